@@ -0,0 +1,180 @@
+// Package batcher provides dataloader-style request batching in front of a cache, collecting individual key misses
+// over a small time window into a single call of a batch load function, bridging per-request code to batched queries.
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// ErrNotFound is returned by Load for keys absent from the batch load function's result.
+var ErrNotFound = errors.New("batcher: key not found")
+
+// Options provides configuration for a Batcher.
+type Options struct {
+	// Wait is how long to collect keys before dispatching a batch, defaulting to 1ms.
+	Wait time.Duration
+
+	// MaxBatch is the maximum number of keys per batch, dispatching immediately when reached. MaxBatch <= 0 is unlimited.
+	MaxBatch int
+}
+
+// Batcher coalesces cache misses into batched calls of a load function, storing the loaded values in the cache.
+type Batcher[Key comparable, Value any] struct {
+	cache cache.Cache[Key, Value]
+	load  func(context.Context, []Key) (map[Key]Value, error)
+	opts  Options
+	cur   *batch[Key, Value]
+	mu    sync.Mutex
+}
+
+// batch is a set of keys collected for dispatch.
+type batch[Key comparable, Value any] struct {
+	keys    []Key
+	results map[Key]*result[Value]
+}
+
+// result is the pending result of a key in a batch.
+type result[Value any] struct {
+	done chan struct{}
+	val  Value
+	err  error
+}
+
+// New returns a new Batcher in front of c, loading missed keys in batches using load. Keys absent from the map
+// returned by load resolve to ErrNotFound, and an error returned by load resolves all keys in the batch to it.
+func New[K comparable, V any](c cache.Cache[K, V], load func(context.Context, []K) (map[K]V, error), opts Options) *Batcher[K, V] {
+	if opts.Wait <= 0 {
+		// Default duration
+		opts.Wait = time.Millisecond
+	}
+	return &Batcher[K, V]{
+		cache: c,
+		load:  load,
+		opts:  opts,
+	}
+}
+
+// Load fetches the value with key from the cache, or on miss queues it in the current batch and waits on the result.
+// Note that ctx only bounds the time spent waiting, the batch itself is loaded independently of any one caller.
+func (b *Batcher[K, V]) Load(ctx context.Context, key K) (V, error) {
+	if value, ok := b.cache.Get(key); ok {
+		return value, nil
+	}
+
+	r := b.enqueue(key)
+
+	select {
+	case <-r.done:
+		return r.val, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany is as Load for multiple keys, returning values for found keys and the first error encountered.
+func (b *Batcher[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	values := make(map[K]V, len(keys))
+	pending := make(map[K]*result[V])
+
+	for _, key := range keys {
+		if value, ok := b.cache.Get(key); ok {
+			values[key] = value
+			continue
+		}
+		pending[key] = b.enqueue(key)
+	}
+
+	var err error
+
+	for key, r := range pending {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return values, ctx.Err()
+		}
+
+		switch {
+		case r.err == nil:
+			values[key] = r.val
+		case err == nil && r.err != ErrNotFound:
+			err = r.err
+		}
+	}
+
+	return values, err
+}
+
+// enqueue adds key to the current batch (starting a new one if needed), returning its pending result.
+func (b *Batcher[K, V]) enqueue(key K) *result[V] {
+	b.mu.Lock()
+
+	bt := b.cur
+	if bt == nil {
+		// Start new batch, dispatched after wait.
+		bt = &batch[K, V]{results: make(map[K]*result[V])}
+		b.cur = bt
+		time.AfterFunc(b.opts.Wait, func() { b.dispatch(bt) })
+	}
+
+	r, ok := bt.results[key]
+	if !ok {
+		// Key not yet in batch.
+		r = &result[V]{done: make(chan struct{})}
+		bt.results[key] = r
+		bt.keys = append(bt.keys, key)
+	}
+
+	full := b.opts.MaxBatch > 0 && len(bt.keys) >= b.opts.MaxBatch
+	if full {
+		// Close batch to further keys,
+		// the timer then finds it gone.
+		b.cur = nil
+	}
+
+	b.mu.Unlock()
+
+	if full {
+		// Dispatch early.
+		go b.resolve(bt)
+	}
+
+	return r
+}
+
+// dispatch loads batch bt, if not already dispatched, resolving all its waiters.
+func (b *Batcher[K, V]) dispatch(bt *batch[K, V]) {
+	b.mu.Lock()
+	if b.cur != bt {
+		// Already dispatched.
+		b.mu.Unlock()
+		return
+	}
+	b.cur = nil
+	b.mu.Unlock()
+
+	b.resolve(bt)
+}
+
+// resolve loads closed batch bt, resolving all its waiters.
+func (b *Batcher[K, V]) resolve(bt *batch[K, V]) {
+	values, err := b.load(context.Background(), bt.keys)
+
+	for key, r := range bt.results {
+		switch value, ok := values[key]; {
+		case err != nil:
+			r.err = err
+		case !ok:
+			r.err = ErrNotFound
+		default:
+			r.val = value
+			b.cache.Set(key, value)
+		}
+		close(r.done)
+	}
+}
@@ -0,0 +1,93 @@
+package batcher_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/batcher"
+	"github.com/mkc188/go-cache/v3/simple"
+)
+
+func TestBatcher(t *testing.T) {
+	var (
+		calls int
+		mu    sync.Mutex
+	)
+
+	c := simple.New[int, int](0, 100)
+	b := batcher.New[int, int](c, func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+
+		values := make(map[int]int, len(keys))
+		for _, key := range keys {
+			if key >= 0 {
+				values[key] = key * 2
+			}
+		}
+		return values, nil
+	}, batcher.Options{Wait: time.Millisecond * 10})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if v, err := b.Load(context.Background(), i); err != nil || v != i*2 {
+				t.Errorf("unexpected result for %d: %d %v", i, v, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected a single batch load, got %d", calls)
+	}
+	if v, ok := c.Get(5); !ok || v != 10 {
+		t.Fatal("loaded value not stored in cache")
+	}
+	if _, err := b.Load(context.Background(), -1); err != batcher.ErrNotFound {
+		t.Fatalf("unexpected error for missing key: %v", err)
+	}
+}
+
+func TestBatcherMaxBatch(t *testing.T) {
+	var (
+		sizes []int
+		mu    sync.Mutex
+	)
+
+	c := simple.New[int, int](0, 100)
+	b := batcher.New[int, int](c, func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		sizes = append(sizes, len(keys))
+		mu.Unlock()
+
+		values := make(map[int]int, len(keys))
+		for _, key := range keys {
+			values[key] = key
+		}
+		return values, nil
+	}, batcher.Options{Wait: time.Millisecond * 10, MaxBatch: 3})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := b.Load(context.Background(), i); err != nil {
+				t.Errorf("unexpected error for %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, n := range sizes {
+		if n > 3 {
+			t.Fatalf("batch exceeded max size: %v", sizes)
+		}
+	}
+}
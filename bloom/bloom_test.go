@@ -0,0 +1,68 @@
+package bloom_test
+
+import (
+	"strconv"
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/bloom"
+)
+
+func TestFilter(t *testing.T) {
+	f := bloom.NewFilter(1000, 0.01)
+
+	for i := 0; i < 1000; i++ {
+		f.Add(uint64(i) * 0x9e3779b97f4a7c15)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !f.Test(uint64(i) * 0x9e3779b97f4a7c15) {
+			t.Fatalf("false negative for %d", i)
+		}
+	}
+
+	var fps int
+	for i := 1000; i < 11000; i++ {
+		if f.Test(uint64(i) * 0x9e3779b97f4a7c15) {
+			fps++
+		}
+	}
+	if fps > 300 {
+		t.Fatalf("false positive rate too high: %d/10000", fps)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	var calls int
+
+	c := &counting{Cache: cache.New[string, int](0, 100), calls: &calls}
+	g := bloom.NewGuard[string, int](c, 100, 0.01)
+
+	g.Set("a", 1)
+	if v, ok := g.Get("a"); !ok || v != 1 {
+		t.Fatal("failed to get present key")
+	}
+
+	for i := 0; i < 100; i++ {
+		g.Get("absent" + strconv.Itoa(i))
+	}
+	if calls > 10 {
+		t.Fatalf("absent lookups reached cache: %d", calls)
+	}
+
+	// Rebuild without "a", which is then rejected.
+	g.Rebuild(func(add func(string)) { add("b") })
+	if g.Has("a") {
+		t.Fatal("expected rebuilt filter to reject key")
+	}
+}
+
+type counting struct {
+	cache.Cache[string, int]
+	calls *int
+}
+
+func (c *counting) Get(key string) (int, bool) {
+	*c.calls++
+	return c.Cache.Get(key)
+}
@@ -0,0 +1,65 @@
+// Package bloom provides a bloom filter, and a Guard fronting a cache with one such that lookups for keys
+// that are definitely absent are rejected without touching the backing store.
+package bloom
+
+import (
+	"math"
+)
+
+// Filter is a bloom filter over 64-bit key hashes. It is not safe for concurrent use.
+type Filter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// NewFilter returns a new Filter sized for n keys at given false positive rate.
+func NewFilter(n int, fp float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+
+	if fp <= 0 || fp >= 1 {
+		// Default rate
+		fp = 0.01
+	}
+
+	// Optimal bit count and hash func count.
+	m := math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(n)*math.Ln2))
+
+	return &Filter{
+		bits: make([]uint64, (uint64(m)+63)/64),
+		m:    uint64(m),
+		k:    uint64(k),
+	}
+}
+
+// Add records given key hash in the filter.
+func (f *Filter) Add(h uint64) {
+	h1, h2 := split(h)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Test returns whether given key hash may have been recorded, false meaning it definitely was not.
+func (f *Filter) Test(h uint64) bool {
+	h1, h2 := split(h)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// split derives the two hashes used for double hashing from a single 64-bit hash.
+func split(h uint64) (uint64, uint64) {
+	h1 := h
+	h2 := (h >> 32) | (h << 32)
+	h2 ^= 0x9e3779b97f4a7c15
+	return h1, h2 | 1
+}
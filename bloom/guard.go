@@ -0,0 +1,173 @@
+package bloom
+
+import (
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Guard wraps a cache.Cache with a bloom filter recording known-present keys, such that Get and Has for keys that are
+// definitely absent return without touching the wrapped cache. As keys cannot be removed from a bloom filter, the filter
+// should be periodically rebuilt from the source of known-present keys, using Rebuild or Start.
+type Guard[Key comparable, Value any] struct {
+	cache.Cache[Key, Value]
+
+	// Hash returns the hash of a key, defaulting to hashing its fmt.Sprint representation.
+	Hash func(Key) uint64
+
+	// filter is the current bloom filter.
+	filter *Filter
+
+	// next is the filter being rebuilt, if any.
+	next *Filter
+
+	// n and fp are the filter sizing.
+	n  int
+	fp float64
+
+	// stop is the rebuild routine stop channel.
+	stop chan struct{}
+
+	// rebuild serializes rebuilds.
+	rebuild sync.Mutex
+
+	// Embedded mutex.
+	sync.RWMutex
+}
+
+// seed is the hash seed of default key hashing.
+var seed = maphash.MakeSeed()
+
+// NewGuard returns a new Guard wrapping c, with filters sized for n keys at given false positive rate.
+func NewGuard[K comparable, V any](c cache.Cache[K, V], n int, fp float64) *Guard[K, V] {
+	return &Guard[K, V]{
+		Cache: c,
+		Hash: func(key K) uint64 {
+			return maphash.String(seed, fmt.Sprint(key))
+		},
+		filter: NewFilter(n, fp),
+		n:      n,
+		fp:     fp,
+	}
+}
+
+// Record records key as present, without touching the wrapped cache.
+func (g *Guard[K, V]) Record(key K) {
+	h := g.Hash(key)
+	g.Lock()
+	g.filter.Add(h)
+	if g.next != nil {
+		g.next.Add(h)
+	}
+	g.Unlock()
+}
+
+// MayContain returns whether key may be present, false meaning it definitely is not.
+func (g *Guard[K, V]) MayContain(key K) bool {
+	h := g.Hash(key)
+	g.RLock()
+	ok := g.filter.Test(h)
+	g.RUnlock()
+	return ok
+}
+
+// Rebuild replaces the filter with one built from the keys passed by source to its add func.
+// Keys recorded while the rebuild is in progress are retained.
+func (g *Guard[K, V]) Rebuild(source func(add func(K))) {
+	g.rebuild.Lock()
+	defer g.rebuild.Unlock()
+
+	next := NewFilter(g.n, g.fp)
+
+	g.Lock()
+	g.next = next
+	g.Unlock()
+
+	source(g.Record)
+
+	g.Lock()
+	g.filter, g.next = next, nil
+	g.Unlock()
+}
+
+// Start starts a routine rebuilding the filter from source at given frequency. If already running or a freq <= 0 provided, this is a no-op.
+func (g *Guard[K, V]) Start(freq time.Duration, source func(add func(K))) (ok bool) {
+	if freq <= 0 {
+		return false
+	}
+
+	g.Lock()
+	if ok = (g.stop == nil); ok {
+		g.stop = make(chan struct{})
+		go g.run(g.stop, freq, source)
+	}
+	g.Unlock()
+
+	return
+}
+
+// Stop stops the rebuild routine. If not running this is a no-op.
+func (g *Guard[K, V]) Stop() (ok bool) {
+	g.Lock()
+	if ok = (g.stop != nil); ok {
+		close(g.stop)
+		g.stop = nil
+	}
+	g.Unlock()
+	return
+}
+
+// run rebuilds the filter from source at freq until stopped.
+func (g *Guard[K, V]) run(stop chan struct{}, freq time.Duration, source func(add func(K))) {
+	ticker := time.NewTicker(freq)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			g.Rebuild(source)
+		}
+	}
+}
+
+// Get: implements cache.Cache's Get(), rejecting definitely absent keys.
+func (g *Guard[K, V]) Get(key K) (V, bool) {
+	if !g.MayContain(key) {
+		var zero V
+		return zero, false
+	}
+	return g.Cache.Get(key)
+}
+
+// Has: implements cache.Cache's Has(), rejecting definitely absent keys.
+func (g *Guard[K, V]) Has(key K) bool {
+	return g.MayContain(key) && g.Cache.Has(key)
+}
+
+// Add: implements cache.Cache's Add(), recording key as present.
+func (g *Guard[K, V]) Add(key K, value V) bool {
+	g.Record(key)
+	return g.Cache.Add(key, value)
+}
+
+// Set: implements cache.Cache's Set(), recording key as present.
+func (g *Guard[K, V]) Set(key K, value V) {
+	g.Record(key)
+	g.Cache.Set(key, value)
+}
+
+// CAS: implements cache.Cache's CAS(), rejecting definitely absent keys.
+func (g *Guard[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	return g.MayContain(key) && g.Cache.CAS(key, old, new, cmp)
+}
+
+// Swap: implements cache.Cache's Swap(), recording key as present.
+func (g *Guard[K, V]) Swap(key K, swp V) V {
+	g.Record(key)
+	return g.Cache.Swap(key, swp)
+}
@@ -3,7 +3,10 @@ package cache
 import (
 	"time"
 
+	"github.com/mkc188/go-cache/v3/errs"
+	"github.com/mkc188/go-cache/v3/logging"
 	"github.com/mkc188/go-cache/v3/simple"
+	"github.com/mkc188/go-cache/v3/stats"
 	"github.com/mkc188/go-cache/v3/ttl"
 	"github.com/mkc188/go-cache/v3/redis"
 )
@@ -19,10 +22,41 @@ type TTLCache[Key comparable, Value any] interface {
 	// SetTTL sets the cache item TTL. Update can be specified to force updates of existing items in the cache, this will simply add the change in TTL to their current expiry time.
 	SetTTL(ttl time.Duration, update bool)
 
+	// Touch resets the TTL of the value with key without calling the invalidate callback, returning whether it exists.
+	Touch(key Key) bool
+
+	// Extend extends the remaining TTL of the value with key by d without calling the invalidate callback, returning whether it exists.
+	Extend(key Key, d time.Duration) bool
+
 	// implements base cache.
 	Cache[Key, Value]
 }
 
+// StatsRecorder receives metrics from the ttl and redis caches, see stats.Recorder.
+type StatsRecorder = stats.Recorder
+
+// Logger receives operational events from cache background routines, see logging.Logger.
+type Logger = logging.Logger
+
+// Errors returned by the error-returning method variants of caches, see errs and ErrorCache.
+var (
+	ErrNotFound = errs.ErrNotFound
+	ErrCapacity = errs.ErrCapacity
+	ErrStopped  = errs.ErrStopped
+)
+
+// ErrorCache represents a cache providing error-returning variants of its methods, so wrappers can surface the cause of failures (e.g. ErrNotFound, ErrCapacity, ErrStopped, or a backend error) through a common interface.
+type ErrorCache[Key comparable, Value any] interface {
+	// GetE fetches the value with key from the cache as Get, returning ErrNotFound if none exists.
+	GetE(key Key) (Value, error)
+
+	// SetE places the value at key in the cache as Set, returning the cause if it could not be stored.
+	SetE(key Key, value Value) error
+
+	// InvalidateE deletes a value from the cache as Invalidate, returning ErrNotFound if none exists.
+	InvalidateE(key Key) error
+}
+
 // Cache represents a cache with customizable callbacks, it exists here to abstract away the "unsafe" methods in the case that you do not want your own implementation atop simple.Cache{}.
 type Cache[Key comparable, Value any] interface {
 	// SetEvictionCallback sets the eviction callback to the provided hook.
@@ -0,0 +1,22 @@
+package cachetest_test
+
+import (
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/cachetest"
+)
+
+func TestMock(t *testing.T) {
+	cachetest.TestCache(t, func(cap int) cache.Cache[string, string] {
+		return cachetest.NewMock[string, string](cap)
+	})
+
+	m := cachetest.NewMock[string, string](10)
+	m.Set("a", "1")
+	m.Get("a")
+	m.Get("b")
+	if m.Count("Get") != 2 || len(m.Calls()) != 3 {
+		t.Fatalf("unexpected recorded calls: %v", m.Calls())
+	}
+}
@@ -0,0 +1,143 @@
+// Package cachetest provides a recording mock implementing cache.Cache, and a conformance test suite for cache.Cache implementations.
+package cachetest
+
+import (
+	"sync"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Call is a recorded method call on a Mock.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Mock is an in-memory cache.Cache, backed by the simple LRU cache, which records all method calls made on it.
+type Mock[Key comparable, Value any] struct {
+	cache cache.Cache[Key, Value]
+	calls []Call
+	mu    sync.Mutex
+}
+
+// NewMock returns a new Mock with given maximum capacity.
+func NewMock[K comparable, V any](cap int) *Mock[K, V] {
+	return &Mock[K, V]{cache: cache.New[K, V](0, cap)}
+}
+
+// Calls returns a copy of all calls recorded so far.
+func (m *Mock[K, V]) Calls() []Call {
+	m.mu.Lock()
+	calls := make([]Call, len(m.calls))
+	copy(calls, m.calls)
+	m.mu.Unlock()
+	return calls
+}
+
+// Count returns the number of recorded calls to method.
+func (m *Mock[K, V]) Count(method string) (n int) {
+	m.mu.Lock()
+	for _, call := range m.calls {
+		if call.Method == method {
+			n++
+		}
+	}
+	m.mu.Unlock()
+	return
+}
+
+// Reset clears all recorded calls.
+func (m *Mock[K, V]) Reset() {
+	m.mu.Lock()
+	m.calls = nil
+	m.mu.Unlock()
+}
+
+// SetEvictionCallback: implements cache.Cache's SetEvictionCallback().
+func (m *Mock[K, V]) SetEvictionCallback(hook func(K, V)) {
+	m.record("SetEvictionCallback")
+	m.cache.SetEvictionCallback(hook)
+}
+
+// SetInvalidateCallback: implements cache.Cache's SetInvalidateCallback().
+func (m *Mock[K, V]) SetInvalidateCallback(hook func(K, V)) {
+	m.record("SetInvalidateCallback")
+	m.cache.SetInvalidateCallback(hook)
+}
+
+// Get: implements cache.Cache's Get().
+func (m *Mock[K, V]) Get(key K) (V, bool) {
+	m.record("Get", key)
+	return m.cache.Get(key)
+}
+
+// Add: implements cache.Cache's Add().
+func (m *Mock[K, V]) Add(key K, value V) bool {
+	m.record("Add", key, value)
+	return m.cache.Add(key, value)
+}
+
+// Set: implements cache.Cache's Set().
+func (m *Mock[K, V]) Set(key K, value V) {
+	m.record("Set", key, value)
+	m.cache.Set(key, value)
+}
+
+// CAS: implements cache.Cache's CAS().
+func (m *Mock[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	m.record("CAS", key, old, new)
+	return m.cache.CAS(key, old, new, cmp)
+}
+
+// Swap: implements cache.Cache's Swap().
+func (m *Mock[K, V]) Swap(key K, swp V) V {
+	m.record("Swap", key, swp)
+	return m.cache.Swap(key, swp)
+}
+
+// Has: implements cache.Cache's Has().
+func (m *Mock[K, V]) Has(key K) bool {
+	m.record("Has", key)
+	return m.cache.Has(key)
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (m *Mock[K, V]) Invalidate(key K) bool {
+	m.record("Invalidate", key)
+	return m.cache.Invalidate(key)
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll().
+func (m *Mock[K, V]) InvalidateAll(keys ...K) bool {
+	args := make([]any, len(keys))
+	for i := range keys {
+		args[i] = keys[i]
+	}
+	m.record("InvalidateAll", args...)
+	return m.cache.InvalidateAll(keys...)
+}
+
+// Clear: implements cache.Cache's Clear().
+func (m *Mock[K, V]) Clear() {
+	m.record("Clear")
+	m.cache.Clear()
+}
+
+// Len: implements cache.Cache's Len().
+func (m *Mock[K, V]) Len() int {
+	m.record("Len")
+	return m.cache.Len()
+}
+
+// Cap: implements cache.Cache's Cap().
+func (m *Mock[K, V]) Cap() int {
+	m.record("Cap")
+	return m.cache.Cap()
+}
+
+// record records a call to method with args.
+func (m *Mock[K, V]) record(method string, args ...any) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	m.mu.Unlock()
+}
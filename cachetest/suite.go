@@ -0,0 +1,160 @@
+package cachetest
+
+import (
+	"sync"
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// TestCache runs the cache.Cache conformance suite against caches returned by factory, which must return
+// a new empty cache with given maximum capacity on each call. Backend implementations run this from
+// their own tests to prove interface compliance.
+func TestCache(t *testing.T, factory func(cap int) cache.Cache[string, string]) {
+	t.Run("GetMissing", func(t *testing.T) {
+		c := factory(10)
+		if _, ok := c.Get("missing"); ok {
+			t.Fatal("got value for missing key")
+		}
+		if c.Has("missing") {
+			t.Fatal("has missing key")
+		}
+	})
+
+	t.Run("AddSetGet", func(t *testing.T) {
+		c := factory(10)
+		if !c.Add("a", "1") {
+			t.Fatal("failed to add new key")
+		}
+		if c.Add("a", "2") {
+			t.Fatal("added existing key")
+		}
+		expectValue(t, c, "a", "1")
+
+		c.Set("a", "3")
+		expectValue(t, c, "a", "3")
+
+		c.Set("b", "4")
+		expectValue(t, c, "b", "4")
+
+		if c.Len() != 2 {
+			t.Fatalf("unexpected len: %d", c.Len())
+		}
+	})
+
+	t.Run("CAS", func(t *testing.T) {
+		c := factory(10)
+		eq := func(a, b string) bool { return a == b }
+		if c.CAS("a", "", "1", eq) {
+			t.Fatal("CAS succeeded on missing key")
+		}
+		c.Set("a", "1")
+		if !c.CAS("a", "1", "2", eq) {
+			t.Fatal("CAS failed on matching value")
+		}
+		expectValue(t, c, "a", "2")
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		c := factory(10)
+		c.Set("a", "1")
+		if old := c.Swap("a", "2"); old != "1" {
+			t.Fatalf("unexpected swapped value: %q", old)
+		}
+		expectValue(t, c, "a", "2")
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		c := factory(10)
+		invalid := recorder(c.SetInvalidateCallback)
+
+		c.Set("a", "1")
+		c.Set("a", "2")
+		if invalid.get("a") != "1" {
+			t.Fatal("invalidate callback not called with overwritten value")
+		}
+
+		if !c.Invalidate("a") {
+			t.Fatal("failed to invalidate existing key")
+		}
+		if c.Invalidate("a") {
+			t.Fatal("invalidated missing key")
+		}
+		if c.Has("a") || invalid.get("a") != "2" {
+			t.Fatal("invalidate callback not called with invalidated value")
+		}
+
+		c.Set("b", "1")
+		c.Set("c", "1")
+		c.InvalidateAll("b", "c")
+		if c.Has("b") || c.Has("c") || c.Len() != 0 {
+			t.Fatal("failed to invalidate all keys")
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		c := factory(10)
+		invalid := recorder(c.SetInvalidateCallback)
+
+		c.Set("a", "1")
+		c.Set("b", "2")
+		c.Clear()
+		if c.Len() != 0 || c.Has("a") || c.Has("b") {
+			t.Fatal("failed to clear cache")
+		}
+		if invalid.get("a") != "1" || invalid.get("b") != "2" {
+			t.Fatal("invalidate callback not called on clear")
+		}
+	})
+
+	t.Run("Evict", func(t *testing.T) {
+		c := factory(2)
+		evict := recorder(c.SetEvictionCallback)
+
+		if c.Cap() != 2 {
+			t.Fatalf("unexpected cap: %d", c.Cap())
+		}
+
+		c.Set("a", "1")
+		c.Set("b", "2")
+		c.Set("c", "3")
+		if c.Len() != 2 || c.Has("a") {
+			t.Fatal("least recently used key not evicted")
+		}
+		if evict.get("a") != "1" {
+			t.Fatal("eviction callback not called")
+		}
+	})
+}
+
+// expectValue fails the test if key does not have value in c.
+func expectValue(t *testing.T, c cache.Cache[string, string], key, value string) {
+	t.Helper()
+	if v, ok := c.Get(key); !ok || v != value {
+		t.Fatalf("expected %q=%q, got %q (ok=%v)", key, value, v, ok)
+	}
+}
+
+// hooked records key-values passed to a cache callback.
+type hooked struct {
+	kvs map[string]string
+	mu  sync.Mutex
+}
+
+// recorder returns a new hooked, registered via given callback setter.
+func recorder(set func(func(string, string))) *hooked {
+	h := &hooked{kvs: make(map[string]string)}
+	set(func(k, v string) {
+		h.mu.Lock()
+		h.kvs[k] = v
+		h.mu.Unlock()
+	})
+	return h
+}
+
+// get returns the last value recorded for key.
+func (h *hooked) get(key string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.kvs[key]
+}
@@ -0,0 +1,55 @@
+package cache
+
+// Key2 is a two-part composite key, usable as the key of any Cache in place of manual string concatenation.
+type Key2[K1, K2 comparable] struct {
+	K1 K1
+	K2 K2
+}
+
+// Key3 is a three-part composite key, usable as the key of any Cache in place of manual string concatenation.
+type Key3[K1, K2, K3 comparable] struct {
+	K1 K1
+	K2 K2
+	K3 K3
+}
+
+// Cache2 wraps a Cache keyed by Key2, providing methods taking both key parts directly.
+type Cache2[K1, K2 comparable, V any] struct {
+	// Cache is the underlying cache, for access to the remaining Cache methods.
+	Cache Cache[Key2[K1, K2], V]
+}
+
+// New2 returns a new initialized Cache2 with given initial length, maximum capacity.
+func New2[K1, K2 comparable, V any](len, cap int) *Cache2[K1, K2, V] {
+	return Wrap2(New[Key2[K1, K2], V](len, cap))
+}
+
+// Wrap2 returns a Cache2 wrapping c.
+func Wrap2[K1, K2 comparable, V any](c Cache[Key2[K1, K2], V]) *Cache2[K1, K2, V] {
+	return &Cache2[K1, K2, V]{Cache: c}
+}
+
+// Get: see Cache's Get().
+func (c *Cache2[K1, K2, V]) Get(k1 K1, k2 K2) (V, bool) {
+	return c.Cache.Get(Key2[K1, K2]{k1, k2})
+}
+
+// Add: see Cache's Add().
+func (c *Cache2[K1, K2, V]) Add(k1 K1, k2 K2, value V) bool {
+	return c.Cache.Add(Key2[K1, K2]{k1, k2}, value)
+}
+
+// Set: see Cache's Set().
+func (c *Cache2[K1, K2, V]) Set(k1 K1, k2 K2, value V) {
+	c.Cache.Set(Key2[K1, K2]{k1, k2}, value)
+}
+
+// Has: see Cache's Has().
+func (c *Cache2[K1, K2, V]) Has(k1 K1, k2 K2) bool {
+	return c.Cache.Has(Key2[K1, K2]{k1, k2})
+}
+
+// Invalidate: see Cache's Invalidate().
+func (c *Cache2[K1, K2, V]) Invalidate(k1 K1, k2 K2) bool {
+	return c.Cache.Invalidate(Key2[K1, K2]{k1, k2})
+}
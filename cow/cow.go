@@ -0,0 +1,225 @@
+// Package cow provides a read-optimized copy-on-write cache, for configuration-style data that changes rarely but is read very often.
+package cow
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Cache is a copy-on-write cache. Reads load an immutable map atomically without any locking, while each mutation
+// copies the map under a writer lock and atomically swaps it in, making writes O(n). It is unbounded, so the
+// eviction callback is never called and Cap returns 0. Batch multiple mutations with Update or Replace.
+type Cache[Key comparable, Value any] struct {
+	// Evict is the hook that is called when an item is evicted from the cache, never for this cache.
+	Evict func(Key, Value)
+
+	// Invalid is the hook that is called when an item's data in the cache is invalidated.
+	Invalid func(Key, Value)
+
+	// m is the current immutable map.
+	m atomic.Pointer[map[Key]Value]
+
+	// Embedded writer mutex.
+	sync.Mutex
+}
+
+// New returns a new initialized Cache.
+func New[K comparable, V any]() *Cache[K, V] {
+	c := new(Cache[K, V])
+	c.m.Store(&map[K]V{})
+	return c
+}
+
+// SetEvictionCallback: implements cache.Cache's SetEvictionCallback().
+func (c *Cache[K, V]) SetEvictionCallback(hook func(K, V)) {
+	c.Lock()
+	c.Evict = hook
+	c.Unlock()
+}
+
+// SetInvalidateCallback: implements cache.Cache's SetInvalidateCallback().
+func (c *Cache[K, V]) SetInvalidateCallback(hook func(K, V)) {
+	c.Lock()
+	c.Invalid = hook
+	c.Unlock()
+}
+
+// Get: implements cache.Cache's Get(), without locking.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	v, ok := c.load()[key]
+	return v, ok
+}
+
+// Has: implements cache.Cache's Has(), without locking.
+func (c *Cache[K, V]) Has(key K) bool {
+	_, ok := c.load()[key]
+	return ok
+}
+
+// Len: implements cache.Cache's Len(), without locking.
+func (c *Cache[K, V]) Len() int {
+	return len(c.load())
+}
+
+// Cap: implements cache.Cache's Cap(), returning 0 as the cache is unbounded.
+func (c *Cache[K, V]) Cap() int {
+	return 0
+}
+
+// Snapshot returns the current immutable map, which must not be modified.
+func (c *Cache[K, V]) Snapshot() map[K]V {
+	return c.load()
+}
+
+// Add: implements cache.Cache's Add().
+func (c *Cache[K, V]) Add(key K, value V) (ok bool) {
+	c.mutate(func(m map[K]V) (k K, v V, invalid bool) {
+		if _, ok = m[key]; !ok {
+			m[key] = value
+		}
+		return
+	})
+	return !ok
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mutate(func(m map[K]V) (K, V, bool) {
+		old, ok := m[key]
+		m[key] = value
+		return key, old, ok
+	})
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) (ok bool) {
+	c.mutate(func(m map[K]V) (K, V, bool) {
+		var cur V
+		if cur, ok = m[key]; ok && cmp(old, cur) {
+			m[key] = new
+		} else {
+			ok = false
+		}
+		return key, cur, ok
+	})
+	return
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) (old V) {
+	c.mutate(func(m map[K]V) (K, V, bool) {
+		var ok bool
+		if old, ok = m[key]; ok {
+			m[key] = swp
+		}
+		return key, old, ok
+	})
+	return
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
+	c.mutate(func(m map[K]V) (K, V, bool) {
+		var old V
+		if old, ok = m[key]; ok {
+			delete(m, key)
+		}
+		return key, old, ok
+	})
+	return
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll().
+func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
+	c.Update(func(m map[K]V) {
+		for _, key := range keys {
+			if _, has := m[key]; has {
+				delete(m, key)
+				ok = true
+			}
+		}
+	})
+	return
+}
+
+// Clear: implements cache.Cache's Clear().
+func (c *Cache[K, V]) Clear() {
+	c.Replace(map[K]V{})
+}
+
+// Update applies fn to a copy of the current map, then swaps it in, calling the invalidate callback for each
+// removed value. This allows batching many mutations into a single copy.
+func (c *Cache[K, V]) Update(fn func(map[K]V)) {
+	var (
+		// old and new maps.
+		old, m map[K]V
+
+		// hook func ptrs.
+		invalid func(K, V)
+	)
+
+	c.Lock()
+	old, m = c.load(), c.copy()
+	fn(m)
+	c.m.Store(&m)
+	invalid = c.Invalid
+	c.Unlock()
+
+	if invalid != nil {
+		for k, v := range old {
+			if _, ok := m[k]; !ok {
+				// Pass to invalidate hook.
+				invalid(k, v)
+			}
+		}
+	}
+}
+
+// Replace swaps in m as the new map, which must not be modified after, calling the invalidate callback for each replaced value.
+func (c *Cache[K, V]) Replace(m map[K]V) {
+	c.Lock()
+	old := c.load()
+	c.m.Store(&m)
+	invalid := c.Invalid
+	c.Unlock()
+
+	if invalid != nil {
+		for k, v := range old {
+			// Pass to invalidate hook.
+			invalid(k, v)
+		}
+	}
+}
+
+// mutate applies fn to a copy of the current map, then swaps it in, passing any value invalidated by fn to the invalidate callback.
+func (c *Cache[K, V]) mutate(fn func(map[K]V) (K, V, bool)) {
+	c.Lock()
+	m := c.copy()
+	k, v, ok := fn(m)
+	c.m.Store(&m)
+	invalid := c.Invalid
+	c.Unlock()
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(k, v)
+	}
+}
+
+// copy returns a copy of the current map (NOTE: requires lock).
+func (c *Cache[K, V]) copy() map[K]V {
+	old := c.load()
+	m := make(map[K]V, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	return m
+}
+
+// load returns the current map.
+func (c *Cache[K, V]) load() map[K]V {
+	if p := c.m.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
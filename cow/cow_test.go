@@ -0,0 +1,41 @@
+package cow_test
+
+import (
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/cow"
+)
+
+func TestCache(t *testing.T) {
+	var _ cache.Cache[string, int] = cow.New[string, int]()
+
+	c := cow.New[string, int]()
+
+	invalidated := map[string]int{}
+	c.SetInvalidateCallback(func(k string, v int) { invalidated[k] = v })
+
+	c.Set("a", 1)
+	snap := c.Snapshot()
+	c.Set("a", 2)
+
+	if snap["a"] != 1 {
+		t.Fatal("snapshot modified by later write")
+	}
+	if v, ok := c.Get("a"); !ok || v != 2 || invalidated["a"] != 1 {
+		t.Fatal("unexpected set result")
+	}
+
+	c.Update(func(m map[string]int) {
+		m["b"] = 3
+		delete(m, "a")
+	})
+	if c.Has("a") || !c.Has("b") || invalidated["a"] != 2 {
+		t.Fatal("unexpected update result")
+	}
+
+	c.Replace(map[string]int{"c": 4})
+	if c.Len() != 1 || invalidated["b"] != 3 {
+		t.Fatal("unexpected replace result")
+	}
+}
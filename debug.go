@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/simple"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+// EntryInfo describes a cache entry for inspection.
+type EntryInfo struct {
+	// Key is the entry key, formatted as string.
+	Key string `json:"key"`
+
+	// Age is the time since the entry was last accessed, or created if never, zero if unknown (nanoseconds in JSON).
+	Age time.Duration `json:"age"`
+
+	// Size is the JSON encoded size of the entry value, -1 if not encodable.
+	Size int `json:"size"`
+
+	// Hits is the number of times the entry has been read, zero if unknown.
+	Hits uint64 `json:"hits,omitempty"`
+}
+
+// Inspector is implemented by caches that can be inspected by DebugHandler.
+type Inspector interface {
+	// Len returns the current length of the cache.
+	Len() int
+
+	// Cap returns the maximum capacity of the cache.
+	Cap() int
+
+	// Inspect returns info on up to limit entries, most recently used first.
+	Inspect(limit int) []EntryInfo
+
+	// InvalidateKey invalidates the entry with key formatted as string, see EntryInfo.Key.
+	InvalidateKey(key string) bool
+}
+
+// DebugHandler returns an http.Handler serving inspection of given named caches, for operations and debugging:
+//
+//	GET    /                  lengths and capacities of all caches
+//	GET    /{name}?limit=N    length, capacity and up to N (default 100) entries of cache
+//	DELETE /{name}?key=K      invalidate the entry with key K in cache
+func DebugHandler(caches map[string]Inspector) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		name := strings.Trim(r.URL.Path, "/")
+
+		if name == "" {
+			if r.Method != http.MethodGet {
+				http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			stats := make(map[string]any, len(caches))
+			for name, c := range caches {
+				stats[name] = map[string]int{"len": c.Len(), "cap": c.Cap()}
+			}
+
+			writeJSON(rw, stats)
+			return
+		}
+
+		c, ok := caches[name]
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			limit := 100
+			if s := r.URL.Query().Get("limit"); s != "" {
+				n, err := strconv.Atoi(s)
+				if err != nil || n < 0 {
+					http.Error(rw, "invalid limit", http.StatusBadRequest)
+					return
+				}
+				limit = n
+			}
+
+			writeJSON(rw, map[string]any{
+				"len":     c.Len(),
+				"cap":     c.Cap(),
+				"entries": c.Inspect(limit),
+			})
+
+		case http.MethodDelete:
+			key := r.URL.Query().Get("key")
+			if key == "" {
+				http.Error(rw, "missing key", http.StatusBadRequest)
+				return
+			}
+
+			writeJSON(rw, map[string]bool{"invalidated": c.InvalidateKey(key)})
+
+		default:
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// InspectSimple returns an Inspector for given simple.Cache{}.
+func InspectSimple[K comparable, V any](c *simple.Cache[K, V]) Inspector {
+	return &simpleInspector[K, V]{c: c}
+}
+
+// InspectTTL returns an Inspector for given ttl.Cache{}.
+func InspectTTL[K comparable, V any](c *ttl.Cache[K, V]) Inspector {
+	return &ttlInspector[K, V]{c: c}
+}
+
+type simpleInspector[K comparable, V any] struct{ c *simple.Cache[K, V] }
+
+func (i *simpleInspector[K, V]) Len() int { return i.c.Len() }
+
+func (i *simpleInspector[K, V]) Cap() int { return i.c.Cap() }
+
+func (i *simpleInspector[K, V]) Inspect(limit int) (infos []EntryInfo) {
+	i.c.Lock()
+	n := i.c.Cache.Len()
+	if limit < n {
+		n = limit
+	}
+	i.c.Cache.Range(0, n, func(_ int, key K, item *simple.Entry) {
+		infos = append(infos, EntryInfo{
+			Key:  fmt.Sprint(key),
+			Size: sizeOf(item.Value),
+		})
+	})
+	i.c.Unlock()
+	return
+}
+
+func (i *simpleInspector[K, V]) InvalidateKey(key string) bool {
+	i.c.Lock()
+	k, ok := findKey(key, func(fn func(int, K)) {
+		i.c.Cache.Range(0, i.c.Cache.Len(), func(x int, k K, _ *simple.Entry) { fn(x, k) })
+	})
+	i.c.Unlock()
+	return ok && i.c.Invalidate(k)
+}
+
+type ttlInspector[K comparable, V any] struct{ c *ttl.Cache[K, V] }
+
+func (i *ttlInspector[K, V]) Len() int { return i.c.Len() }
+
+func (i *ttlInspector[K, V]) Cap() int { return i.c.Cap() }
+
+func (i *ttlInspector[K, V]) Inspect(limit int) (infos []EntryInfo) {
+	i.c.Lock()
+	n := i.c.Cache.Len()
+	if limit < n {
+		n = limit
+	}
+	i.c.Cache.Range(0, n, func(_ int, key K, item *ttl.Entry[K, V]) {
+		infos = append(infos, EntryInfo{
+			Key:  fmt.Sprint(key),
+			Size: sizeOf(item.Value),
+			Hits: item.Hits,
+			Age:  item.Idle(),
+		})
+	})
+	i.c.Unlock()
+	return
+}
+
+func (i *ttlInspector[K, V]) InvalidateKey(key string) bool {
+	i.c.Lock()
+	k, ok := findKey(key, func(fn func(int, K)) {
+		i.c.Cache.Range(0, i.c.Cache.Len(), func(x int, k K, _ *ttl.Entry[K, V]) { fn(x, k) })
+	})
+	i.c.Unlock()
+	return ok && i.c.Invalidate(k)
+}
+
+// findKey returns the first key ranged over whose string format matches given key.
+func findKey[K comparable](key string, rangefn func(func(int, K))) (found K, ok bool) {
+	rangefn(func(_ int, k K) {
+		if !ok && fmt.Sprint(k) == key {
+			found, ok = k, true
+		}
+	})
+	return
+}
+
+// sizeOf returns the JSON encoded size of value, or -1 if not encodable.
+func sizeOf(value any) int {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return -1
+	}
+	return len(b)
+}
+
+// writeJSON writes value as a JSON response.
+func writeJSON(rw http.ResponseWriter, value any) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(b)
+}
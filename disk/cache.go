@@ -0,0 +1,768 @@
+package disk
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/logging"
+	"github.com/mkc188/go-cache/v3/snapshot"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Options provides configuration for a disk-backed Cache.
+type Options struct {
+	// TTL is the cache item TTL, measured from each item's last access. A TTL <= 0 disables expiry.
+	TTL time.Duration
+
+	// Cap is the maximum number of items, beyond which the least recently used are evicted. A Cap <= 0 is unbounded.
+	Cap int
+
+	// CompactInterval is the frequency at which the database file is compacted while the eviction routine is running, reclaiming space freed by removed items. An interval <= 0 disables compaction.
+	CompactInterval time.Duration
+
+	// Timeout is the maximum time to wait on obtaining the database file lock. A Timeout <= 0 waits indefinitely.
+	Timeout time.Duration
+
+	// Logger receives sweep and compaction failures, nil disables logging.
+	Logger logging.Logger
+
+	// Codec encodes entries for Dump() and Restore(), nil uses snapshot.Gob.
+	Codec snapshot.Codec
+}
+
+// Cache is a persistent TTLCache implementation stored in a bbolt database file, for large caches that must survive restarts and exceed available memory. Keys and values are stored JSON encoded, any values failing to encode or decode are treated as cache misses.
+type Cache[Key comparable, Value any] struct {
+	// TTL is the cache item TTL, measured from each item's last access.
+	TTL time.Duration
+
+	// Evict is the hook that is called when an item is evicted from the cache.
+	Evict func(Key, Value)
+
+	// Invalid is the hook that is called when an item's data in the cache is invalidated.
+	Invalid func(Key, Value)
+
+	// db is the underlying database.
+	db *bolt.DB
+
+	// path is the database file path.
+	path string
+
+	// opts are the options the cache was opened with.
+	opts Options
+
+	// stop is the eviction routine cancel func.
+	stop func()
+
+	// Embedded mutex, write locked only for configuration and compaction.
+	sync.RWMutex
+}
+
+// Open opens (creating if necessary) the database file at path, and returns a new Cache stored within it.
+func Open[K comparable, V any](path string, opts *Options) (*Cache[K, V], error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: opts.Timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(initBuckets); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Cache[K, V]{
+		TTL:  opts.TTL,
+		db:   db,
+		path: path,
+		opts: *opts,
+	}, nil
+}
+
+// Close stops the eviction routine and closes the underlying database.
+func (c *Cache[K, V]) Close() (err error) {
+	c.Stop()
+	c.locked(func() { err = c.db.Close() })
+	return
+}
+
+// Start: implements cache.TTLCache's Start(), also scheduling compaction if configured.
+func (c *Cache[K, V]) Start(freq time.Duration) (ok bool) {
+	// Nothing to start
+	if freq <= 0 {
+		return false
+	}
+
+	c.locked(func() {
+		if ok = (c.stop == nil); !ok {
+			return
+		}
+
+		// Not yet running, schedule us
+		stopSweep := schedule(c.Sweep, freq)
+		stopCompact := func() {}
+
+		if c.opts.CompactInterval > 0 {
+			stopCompact = schedule(func(time.Time) {
+				if err := c.Compact(); err != nil && c.opts.Logger != nil {
+					c.opts.Logger.Error("disk: compaction failed", "path", c.path, "err", err)
+				}
+			}, c.opts.CompactInterval)
+		}
+
+		c.stop = func() {
+			stopSweep()
+			stopCompact()
+		}
+	})
+
+	return
+}
+
+// Stop: implements cache.TTLCache's Stop().
+func (c *Cache[K, V]) Stop() (ok bool) {
+	c.locked(func() {
+		if ok = (c.stop != nil); ok {
+			// We're running, cancel evicts
+			c.stop()
+			c.stop = nil
+		}
+	})
+	return
+}
+
+// Sweep attempts to evict expired items (with callback!) from cache.
+func (c *Cache[K, V]) Sweep(_ time.Time) {
+	var (
+		// evicted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		evict func(K, V)
+	)
+
+	err := c.update(func(tx *bolt.Tx) error {
+		if c.TTL <= 0 {
+			// sweep is
+			// disabled
+			return nil
+		}
+
+		// Set hook func ptr.
+		evict = c.Evict
+
+		// Access index is ordered least recently used first, so
+		// we drop items until reaching the first unexpired one.
+		var err error
+		t := now()
+		kvs, err = c.drop(tx, evict != nil, func(_ int, access uint64) bool {
+			return c.expired(access, t)
+		})
+		return err
+	})
+
+	if err != nil {
+		if c.opts.Logger != nil {
+			c.opts.Logger.Error("disk: sweep failed", "path", c.path, "err", err)
+		}
+		return
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+}
+
+// Compact rewrites the database file to reclaim space freed by removed items. All other cache operations block while in progress.
+func (c *Cache[K, V]) Compact() (err error) {
+	c.locked(func() {
+		tmp := c.path + ".compact"
+
+		// Copy live data into a fresh database file.
+		dst, err2 := bolt.Open(tmp, 0o600, nil)
+		if err = err2; err != nil {
+			return
+		}
+
+		if err = bolt.Compact(dst, c.db, 0); err == nil {
+			err = dst.Close()
+		} else {
+			_ = dst.Close()
+		}
+
+		if err != nil {
+			_ = os.Remove(tmp)
+			return
+		}
+
+		// Swap the compacted file into place.
+		if err = c.db.Close(); err != nil {
+			_ = os.Remove(tmp)
+			return
+		}
+
+		if err = os.Rename(tmp, c.path); err != nil {
+			_ = os.Remove(tmp)
+		}
+
+		// Reopen at path, being the compacted file on success or the original otherwise.
+		db, err2 := bolt.Open(c.path, 0o600, &bolt.Options{Timeout: c.opts.Timeout})
+		if err2 != nil {
+			err = err2
+			return
+		}
+		c.db = db
+	})
+	return
+}
+
+// SetEvictionCallback: implements cache.Cache's SetEvictionCallback().
+func (c *Cache[K, V]) SetEvictionCallback(hook func(K, V)) {
+	c.locked(func() { c.Evict = hook })
+}
+
+// SetInvalidateCallback: implements cache.Cache's SetInvalidateCallback().
+func (c *Cache[K, V]) SetInvalidateCallback(hook func(K, V)) {
+	c.locked(func() { c.Invalid = hook })
+}
+
+// SetTTL: implements cache.TTLCache's SetTTL(). As expiry is calculated from each item's last access, existing items always observe the updated TTL regardless of update.
+func (c *Cache[K, V]) SetTTL(ttl time.Duration, update bool) {
+	c.locked(func() { c.TTL = ttl })
+}
+
+// Get: implements cache.Cache's Get().
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// cached value.
+		v V
+	)
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return v, false
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Check for item in cache
+		access, data, found := lookup(tx, k)
+		if !found || c.expired(access, t) {
+			return nil
+		}
+
+		// Set value.
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		ok = true
+
+		// Update fetched's access time
+		return store(tx, k, data, t)
+	})
+
+	return v, ok && err == nil
+}
+
+// Add: implements cache.Cache's Add().
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// evicted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		evict func(K, V)
+	)
+
+	k, data, err := encode(key, value)
+	if err != nil {
+		return false
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Check if in cache.
+		access, _, found := lookup(tx, k)
+		if ok = found && !c.expired(access, t); ok {
+			return nil
+		}
+
+		// Set hook func ptr.
+		evict = c.Evict
+
+		if err := store(tx, k, data, t); err != nil {
+			return err
+		}
+
+		// Evict any items beyond capacity.
+		kvs, err = c.trim(tx, evict != nil)
+		return err
+	})
+
+	if err != nil {
+		return false
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+
+	return !ok
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// old value.
+		oldV V
+
+		// evicted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		evict   func(K, V)
+	)
+
+	k, data, err := encode(key, value)
+	if err != nil {
+		return
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		evict = c.Evict
+
+		// Check for item in cache
+		access, old, found := lookup(tx, k)
+		ok = found && !c.expired(access, t)
+
+		if ok && invalid != nil {
+			// Set old value.
+			ok = (json.Unmarshal(old, &oldV) == nil)
+		}
+
+		if err := store(tx, k, data, t); err != nil {
+			return err
+		}
+
+		// Evict any items beyond capacity.
+		kvs, err = c.trim(tx, evict != nil)
+		return err
+	})
+
+	if err != nil {
+		return
+	}
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	var (
+		// did swap value?
+		ok bool
+
+		// swapped value.
+		oldV V
+
+		// hook func ptrs.
+		invalid func(K, V)
+	)
+
+	k, data, err := encode(key, new)
+	if err != nil {
+		return false
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Check for item in cache
+		access, cur, found := lookup(tx, k)
+		if !found || c.expired(access, t) {
+			return nil
+		}
+
+		// Set old value.
+		if err := json.Unmarshal(cur, &oldV); err != nil {
+			return err
+		}
+
+		// Perform the comparison
+		if !cmp(old, oldV) {
+			return nil
+		}
+		ok = true
+
+		// Set hook func ptr.
+		invalid = c.Invalid
+
+		// Update value + access time.
+		return store(tx, k, data, t)
+	})
+
+	if err != nil {
+		return false
+	}
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	return ok
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) V {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// swapped value.
+		oldV V
+
+		// hook func ptrs.
+		invalid func(K, V)
+	)
+
+	k, data, err := encode(key, swp)
+	if err != nil {
+		return oldV
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Check for item in cache
+		access, cur, found := lookup(tx, k)
+		if !found || c.expired(access, t) {
+			return nil
+		}
+
+		// Set old value.
+		if err := json.Unmarshal(cur, &oldV); err != nil {
+			return err
+		}
+		ok = true
+
+		// Set hook func ptr.
+		invalid = c.Invalid
+
+		// Update value + access time.
+		return store(tx, k, data, t)
+	})
+
+	if err != nil {
+		var zero V
+		return zero
+	}
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	return oldV
+}
+
+// Has: implements cache.Cache's Has().
+func (c *Cache[K, V]) Has(key K) (ok bool) {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return false
+	}
+
+	c.rlocked(func() {
+		_ = c.db.View(func(tx *bolt.Tx) error {
+			access, _, found := lookup(tx, k)
+			ok = found && !c.expired(access, now())
+			return nil
+		})
+	})
+
+	return
+}
+
+// Touch: implements cache.TTLCache's Touch().
+func (c *Cache[K, V]) Touch(key K) bool {
+	return c.access(key, 0)
+}
+
+// Extend: implements cache.TTLCache's Extend(), by moving the item's last access time d into the future.
+func (c *Cache[K, V]) Extend(key K, d time.Duration) bool {
+	return c.access(key, d)
+}
+
+// access updates the last access time of key to now, or to its current access time plus d if d > 0.
+func (c *Cache[K, V]) access(key K, d time.Duration) (ok bool) {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return false
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Check for item in cache
+		access, data, found := lookup(tx, k)
+		if !found || c.expired(access, t) {
+			return nil
+		}
+		ok = true
+
+		if d > 0 {
+			t = access + uint64(d)
+		}
+
+		// Update item's access time
+		return store(tx, k, data, t)
+	})
+
+	return ok && err == nil
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
+	return c.InvalidateAll(key)
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll(), returning whether any items were invalidated.
+func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
+	var (
+		// deleted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+	)
+
+	err := c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		// Set hook func ptr.
+		invalid = c.Invalid
+
+		for x := range keys {
+			k, err := json.Marshal(keys[x])
+			if err != nil {
+				continue
+			}
+
+			// Check for item in cache
+			access, data, found := lookup(tx, k)
+			if !found {
+				continue
+			}
+
+			// Remove from cache (expired items silently).
+			if err := remove(tx, k, access); err != nil {
+				return err
+			}
+
+			if c.expired(access, t) {
+				continue
+			}
+			ok = true
+
+			if invalid != nil {
+				var v V
+				if json.Unmarshal(data, &v) == nil {
+					kvs = append(kvs, kv[K, V]{K: keys[x], V: v})
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return false
+	}
+
+	if invalid != nil {
+		for x := range kvs {
+			// Pass to invalidate hook.
+			invalid(kvs[x].K, kvs[x].V)
+		}
+	}
+
+	return
+}
+
+// Clear: implements cache.Cache's Clear().
+func (c *Cache[K, V]) Clear() {
+	var (
+		// deleted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+	)
+
+	err := c.update(func(tx *bolt.Tx) error {
+		// Set hook func ptr.
+		invalid = c.Invalid
+
+		var err error
+		kvs, err = c.drop(tx, invalid != nil, func(int, uint64) bool {
+			return true
+		})
+		return err
+	})
+
+	if err == nil && invalid != nil {
+		for x := range kvs {
+			// Pass to invalidate hook.
+			invalid(kvs[x].K, kvs[x].V)
+		}
+	}
+}
+
+// Len: implements cache.Cache's Len().
+func (c *Cache[K, V]) Len() (l int) {
+	c.rlocked(func() {
+		_ = c.db.View(func(tx *bolt.Tx) error {
+			l = length(tx)
+			return nil
+		})
+	})
+	return
+}
+
+// Cap: implements cache.Cache's Cap().
+func (c *Cache[K, V]) Cap() int {
+	return c.opts.Cap
+}
+
+// update performs given function within a read-write transaction, under read lock (NOTE: UNLOCK IS NOT DEFERRED).
+func (c *Cache[K, V]) update(fn func(*bolt.Tx) error) (err error) {
+	c.rlocked(func() { err = c.db.Update(fn) })
+	return
+}
+
+// trim evicts the least recently used items beyond capacity, returning them decoded if decode is set.
+func (c *Cache[K, V]) trim(tx *bolt.Tx, decode bool) ([]kv[K, V], error) {
+	if c.opts.Cap <= 0 {
+		return nil, nil
+	}
+	over := length(tx) - c.opts.Cap
+	return c.drop(tx, decode, func(i int, _ uint64) bool {
+		return i < over
+	})
+}
+
+// drop removes items in least recently used order for as long as cond returns true, returning them decoded if decode is set.
+func (c *Cache[K, V]) drop(tx *bolt.Tx, decode bool, cond func(i int, access uint64) bool) ([]kv[K, V], error) {
+	var (
+		// dropped index keys.
+		iks [][]byte
+
+		// dropped key-values.
+		kvs []kv[K, V]
+	)
+
+	cur := tx.Bucket(accessBucket).Cursor()
+	for ik, _ := cur.First(); ik != nil; ik, _ = cur.Next() {
+		if !cond(len(iks), binary.BigEndian.Uint64(ik)) {
+			break
+		}
+		iks = append(iks, append([]byte(nil), ik...))
+	}
+
+	for _, ik := range iks {
+		access, k := binary.BigEndian.Uint64(ik), ik[8:]
+
+		if decode {
+			var item kv[K, V]
+			_, data, _ := lookup(tx, k)
+			if json.Unmarshal(k, &item.K) == nil &&
+				json.Unmarshal(data, &item.V) == nil {
+				kvs = append(kvs, item)
+			}
+		}
+
+		if err := remove(tx, k, access); err != nil {
+			return nil, err
+		}
+	}
+
+	return kvs, nil
+}
+
+// expired returns whether an item last accessed at given time has expired by now.
+func (c *Cache[K, V]) expired(access, now uint64) bool {
+	return c.TTL > 0 && now > access+uint64(c.TTL)
+}
+
+// locked performs given function within mutex lock (NOTE: UNLOCK IS NOT DEFERRED).
+func (c *Cache[K, V]) locked(fn func()) {
+	c.Lock()
+	fn()
+	c.Unlock()
+}
+
+// rlocked performs given function within mutex read lock (NOTE: UNLOCK IS NOT DEFERRED).
+func (c *Cache[K, V]) rlocked(fn func()) {
+	c.RLock()
+	fn()
+	c.RUnlock()
+}
+
+// encode returns the JSON encoded key and value.
+func encode[K comparable, V any](key K, value V) (k, data []byte, err error) {
+	if k, err = json.Marshal(key); err != nil {
+		return nil, nil, err
+	}
+	if data, err = json.Marshal(value); err != nil {
+		return nil, nil, err
+	}
+	return k, data, nil
+}
+
+// now returns the current wall clock time in nanoseconds, as persisted access times must survive restarts.
+func now() uint64 {
+	return uint64(time.Now().UnixNano())
+}
+
+type kv[K comparable, V any] struct {
+	K K
+	V V
+}
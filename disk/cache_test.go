@@ -0,0 +1,123 @@
+package disk_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/cachetest"
+	"github.com/mkc188/go-cache/v3/disk"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+
+	// Prepare cache
+	c, err := disk.Open[string, int](path, &disk.Options{Cap: 2})
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+
+	// Track callbacks set
+	evicted := map[string]int{}
+	c.SetEvictionCallback(func(key string, value int) {
+		evicted[key] = value
+	})
+	invalidated := map[string]int{}
+	c.SetInvalidateCallback(func(key string, value int) {
+		invalidated[key] = value
+	})
+
+	if !c.Add("a", 1) || c.Add("a", 2) {
+		t.Fatal("unexpected add result")
+	}
+	c.Set("b", 2)
+	c.Set("a", 3)
+
+	if invalidated["a"] != 1 {
+		t.Fatalf("expected invalidate of old value, got %v", invalidated)
+	}
+
+	// "b" is now least recently used.
+	c.Set("c", 4)
+	if _, ok := evicted["b"]; !ok || c.Has("b") || c.Len() != 2 {
+		t.Fatalf("expected eviction of least recently used, got %v (len=%d)", evicted, c.Len())
+	}
+
+	if !c.CAS("a", 3, 5, func(a, b int) bool { return a == b }) {
+		t.Fatal("failed to CAS existing value")
+	}
+	if old := c.Swap("a", 6); old != 5 {
+		t.Fatalf("unexpected swapped value: %d", old)
+	}
+
+	if err := c.Compact(); err != nil {
+		t.Fatalf("failed to compact: %v", err)
+	}
+
+	// Ensure values persist across reopen.
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close cache: %v", err)
+	}
+	c, err = disk.Open[string, int](path, &disk.Options{Cap: 2, TTL: time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to reopen cache: %v", err)
+	}
+	defer c.Close()
+
+	c.SetTTL(time.Hour, false)
+	if v, ok := c.Get("a"); !ok || v != 6 {
+		t.Fatalf("unexpected persisted value: %d %v", v, ok)
+	}
+
+	// Ensure expired items are swept.
+	c.SetTTL(time.Millisecond, false)
+	time.Sleep(time.Millisecond * 5)
+	c.Sweep(time.Now())
+	if c.Len() != 0 {
+		t.Fatalf("expected all items swept, got len=%d", c.Len())
+	}
+}
+
+func TestConformance(t *testing.T) {
+	cachetest.TestCache(t, func(cap int) cache.Cache[string, string] {
+		c, err := disk.Open[string, string](filepath.Join(t.TempDir(), "cache.db"), &disk.Options{Cap: cap})
+		if err != nil {
+			t.Fatalf("failed to open cache: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	src := ttl.New[string, int](0, 10, time.Minute)
+	src.Set("a", 1)
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("failed to dump cache: %v", err)
+	}
+
+	// Restore a ttl dump into a disk cache.
+	dst, err := disk.Open[string, int](filepath.Join(t.TempDir(), "cache.db"), &disk.Options{TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	defer dst.Close()
+
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("failed to restore cache: %v", err)
+	}
+	if v, ok := dst.Get("a"); !ok || v != 1 {
+		t.Fatalf("unexpected restored value: %d %v", v, ok)
+	}
+
+	buf.Reset()
+	if err := dst.Dump(&buf); err != nil || buf.Len() == 0 {
+		t.Fatalf("failed to dump cache: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package disk
+
+import (
+	"time"
+
+	"codeberg.org/gruf/go-sched"
+)
+
+// scheduler is the global disk cache runtime
+// scheduler for handling sweeps and compaction.
+var scheduler sched.Scheduler
+
+// schedule will add given routine to the global scheduler, and start global scheduler.
+func schedule(fn func(time.Time), freq time.Duration) func() {
+	if !scheduler.Running() {
+		// ensure sched running
+		_ = scheduler.Start(nil)
+	}
+	return scheduler.Schedule(sched.NewJob(fn).Every(freq))
+}
@@ -0,0 +1,112 @@
+package disk
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/snapshot"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Dump: implements snapshot.Dumper, writing entries least recently used first, with their remaining TTLs.
+func (c *Cache[K, V]) Dump(w io.Writer) error {
+	var entries []snapshot.Entry[K, V]
+
+	var err error
+	c.rlocked(func() {
+		err = c.db.View(func(tx *bolt.Tx) error {
+			t := now()
+
+			cur := tx.Bucket(accessBucket).Cursor()
+			for ik, _ := cur.First(); ik != nil; ik, _ = cur.Next() {
+				access, k := binary.BigEndian.Uint64(ik), ik[8:]
+				if c.expired(access, t) {
+					continue
+				}
+
+				var e snapshot.Entry[K, V]
+				_, data, _ := lookup(tx, k)
+				if json.Unmarshal(k, &e.Key) != nil ||
+					json.Unmarshal(data, &e.Value) != nil {
+					continue
+				}
+
+				if c.TTL > 0 {
+					// Remaining TTL, at least 1ns as zero means no expiry.
+					e.TTL = time.Duration(access+uint64(c.TTL)-t) + 1
+				}
+
+				entries = append(entries, e)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return snapshot.Write(w, c.opts.Codec, entries)
+}
+
+// Restore: implements snapshot.Restorer, placing restored entries with their remaining TTLs, replacing any existing.
+func (c *Cache[K, V]) Restore(r io.Reader) error {
+	var entries []snapshot.Entry[K, V]
+
+	if err := snapshot.Read(r, c.opts.Codec, func(e snapshot.Entry[K, V]) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var (
+		// evicted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		evict func(K, V)
+	)
+
+	err := c.update(func(tx *bolt.Tx) error {
+		t := now()
+
+		for _, e := range entries {
+			k, data, err := encode(e.Key, e.Value)
+			if err != nil {
+				continue
+			}
+
+			// Back-date access time such
+			// that remaining TTL is kept.
+			access := t
+			if e.TTL > 0 && e.TTL < c.TTL {
+				access -= uint64(c.TTL - e.TTL)
+			}
+
+			if err := store(tx, k, data, access); err != nil {
+				return err
+			}
+		}
+
+		// Set hook func ptr.
+		evict = c.Evict
+
+		// Evict any items beyond capacity.
+		var err error
+		kvs, err = c.trim(tx, evict != nil)
+		return err
+	})
+
+	if err == nil && evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,101 @@
+package disk
+
+import (
+	"encoding/binary"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// dataBucket maps encoded keys to their last access time followed by encoded value.
+	dataBucket = []byte("data")
+
+	// accessBucket indexes last access time followed by encoded key, ordering least recently used first.
+	accessBucket = []byte("access")
+
+	// metaBucket stores cache metadata, i.e. the current item count.
+	metaBucket = []byte("meta")
+
+	// lenKey is the metaBucket key of the current item count.
+	lenKey = []byte("len")
+)
+
+// initBuckets ensures all cache buckets exist.
+func initBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{dataBucket, accessBucket, metaBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the last access time and encoded value stored at encoded key. The
+// returned value is copied, such that it remains valid after modifying the transaction.
+func lookup(tx *bolt.Tx, k []byte) (access uint64, data []byte, ok bool) {
+	rec := tx.Bucket(dataBucket).Get(k)
+	if len(rec) < 8 {
+		return 0, nil, false
+	}
+	data = make([]byte, len(rec)-8)
+	copy(data, rec[8:])
+	return binary.BigEndian.Uint64(rec), data, true
+}
+
+// store places encoded value at encoded key with given access time, replacing any existing record.
+func store(tx *bolt.Tx, k, data []byte, access uint64) error {
+	old, _, ok := lookup(tx, k)
+	if ok {
+		// Drop the old access index entry.
+		if err := tx.Bucket(accessBucket).Delete(indexKey(old, k)); err != nil {
+			return err
+		}
+	} else if err := addLen(tx, 1); err != nil {
+		return err
+	}
+
+	rec := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(rec, access)
+	copy(rec[8:], data)
+
+	if err := tx.Bucket(dataBucket).Put(k, rec); err != nil {
+		return err
+	}
+
+	return tx.Bucket(accessBucket).Put(indexKey(access, k), nil)
+}
+
+// remove deletes the record at encoded key with given access time.
+func remove(tx *bolt.Tx, k []byte, access uint64) error {
+	if err := tx.Bucket(dataBucket).Delete(k); err != nil {
+		return err
+	}
+	if err := tx.Bucket(accessBucket).Delete(indexKey(access, k)); err != nil {
+		return err
+	}
+	return addLen(tx, -1)
+}
+
+// length returns the current item count.
+func length(tx *bolt.Tx) int {
+	b := tx.Bucket(metaBucket).Get(lenKey)
+	if len(b) < 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(b))
+}
+
+// addLen adds delta to the current item count.
+func addLen(tx *bolt.Tx, delta int) error {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(length(tx)+delta))
+	return tx.Bucket(metaBucket).Put(lenKey, b)
+}
+
+// indexKey returns the access index key for encoded key with given access time.
+func indexKey(access uint64, k []byte) []byte {
+	b := make([]byte, 8+len(k))
+	binary.BigEndian.PutUint64(b, access)
+	copy(b[8:], k)
+	return b
+}
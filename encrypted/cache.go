@@ -0,0 +1,147 @@
+// Package encrypted provides a cache wrapper transparently AES-GCM encrypting values before delegating to a backing
+// cache (e.g. redis or disk), and decrypting them on read, such that sensitive cached data is not stored in plaintext.
+package encrypted
+
+import (
+	"encoding/json"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Cache wraps a backing cache of sealed values. Values are JSON encoded before sealing, and any values failing
+// to encode, or to decode and open (e.g. sealed with a since removed key), are treated as cache misses.
+type Cache[Key comparable, Value any] struct {
+	cache cache.Cache[Key, []byte]
+	keys  *Keyring
+}
+
+// New returns a new Cache sealing values stored in c with given keys.
+func New[K comparable, V any](c cache.Cache[K, []byte], keys *Keyring) *Cache[K, V] {
+	return &Cache[K, V]{cache: c, keys: keys}
+}
+
+// SetEvictionCallback: implements cache.Cache's SetEvictionCallback().
+func (c *Cache[K, V]) SetEvictionCallback(hook func(K, V)) {
+	c.cache.SetEvictionCallback(c.hook(hook))
+}
+
+// SetInvalidateCallback: implements cache.Cache's SetInvalidateCallback().
+func (c *Cache[K, V]) SetInvalidateCallback(hook func(K, V)) {
+	c.cache.SetInvalidateCallback(c.hook(hook))
+}
+
+// Get: implements cache.Cache's Get().
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var v V
+	sealed, ok := c.cache.Get(key)
+	if !ok {
+		return v, false
+	}
+	v, err := c.open(sealed)
+	return v, err == nil
+}
+
+// Add: implements cache.Cache's Add().
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	sealed, err := c.seal(value)
+	if err != nil {
+		return false
+	}
+	return c.cache.Add(key, sealed)
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	sealed, err := c.seal(value)
+	if err != nil {
+		return
+	}
+	c.cache.Set(key, sealed)
+}
+
+// CAS: implements cache.Cache's CAS(), comparing against the opened current value.
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	sealed, err := c.seal(new)
+	if err != nil {
+		return false
+	}
+	return c.cache.CAS(key, nil, sealed, func(_, cur []byte) bool {
+		v, err := c.open(cur)
+		return err == nil && cmp(old, v)
+	})
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) V {
+	var old V
+	sealed, err := c.seal(swp)
+	if err != nil {
+		return old
+	}
+	if prev := c.cache.Swap(key, sealed); prev != nil {
+		old, _ = c.open(prev)
+	}
+	return old
+}
+
+// Has: implements cache.Cache's Has().
+func (c *Cache[K, V]) Has(key K) bool {
+	return c.cache.Has(key)
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (c *Cache[K, V]) Invalidate(key K) bool {
+	return c.cache.Invalidate(key)
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll().
+func (c *Cache[K, V]) InvalidateAll(keys ...K) bool {
+	return c.cache.InvalidateAll(keys...)
+}
+
+// Clear: implements cache.Cache's Clear().
+func (c *Cache[K, V]) Clear() {
+	c.cache.Clear()
+}
+
+// Len: implements cache.Cache's Len().
+func (c *Cache[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// Cap: implements cache.Cache's Cap().
+func (c *Cache[K, V]) Cap() int {
+	return c.cache.Cap()
+}
+
+// hook wraps given value hook to open sealed values, skipping those failing to open.
+func (c *Cache[K, V]) hook(hook func(K, V)) func(K, []byte) {
+	if hook == nil {
+		return nil
+	}
+	return func(key K, sealed []byte) {
+		if v, err := c.open(sealed); err == nil {
+			hook(key, v)
+		}
+	}
+}
+
+// seal encodes and seals value.
+func (c *Cache[K, V]) seal(value V) ([]byte, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return c.keys.Seal(b)
+}
+
+// open opens and decodes sealed value.
+func (c *Cache[K, V]) open(sealed []byte) (V, error) {
+	var v V
+	b, err := c.keys.Open(sealed)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
@@ -0,0 +1,53 @@
+package encrypted_test
+
+import (
+	"bytes"
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/cachetest"
+	"github.com/mkc188/go-cache/v3/encrypted"
+)
+
+func TestCache(t *testing.T) {
+	keys, err := encrypted.NewKeyring(1, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cachetest.TestCache(t, func(cap int) cache.Cache[string, string] {
+		return encrypted.New[string, string](cache.New[string, []byte](0, cap), keys)
+	})
+
+	backing := cache.New[string, []byte](0, 10)
+	c := encrypted.New[string, string](backing, keys)
+
+	c.Set("a", "secret")
+	if sealed, _ := backing.Get("a"); bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("value stored in plaintext")
+	}
+
+	// Rotate to a new key, old values remain readable.
+	if err := keys.Add(2, bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Rotate(2); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("b", "other")
+
+	if v, ok := c.Get("a"); !ok || v != "secret" {
+		t.Fatal("failed to open value sealed with old key")
+	}
+
+	// Remove the old key, its values can no longer be read.
+	if !keys.Remove(1) {
+		t.Fatal("failed to remove old key")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("opened value sealed with removed key")
+	}
+	if v, ok := c.Get("b"); !ok || v != "other" {
+		t.Fatal("failed to open value sealed with new key")
+	}
+}
@@ -0,0 +1,113 @@
+package encrypted
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrUnknownKey is returned when decrypting a value sealed with a key not in the Keyring.
+	ErrUnknownKey = errors.New("encrypted: unknown key")
+
+	// ErrMalformed is returned when decrypting a value too short to be valid.
+	ErrMalformed = errors.New("encrypted: malformed ciphertext")
+)
+
+// Keyring holds the AES keys, identified by a single byte ID, used to seal and open values. Values are always
+// sealed with the primary key, and opened with whichever key they were sealed with, so keys can be rotated by
+// adding a new key, making it primary, and removing the old key once all values sealed with it have expired.
+type Keyring struct {
+	aeads   map[byte]cipher.AEAD
+	primary byte
+	mu      sync.RWMutex
+}
+
+// NewKeyring returns a new Keyring with given primary key, which must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewKeyring(id byte, key []byte) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[byte]cipher.AEAD)}
+	if err := k.Add(id, key); err != nil {
+		return nil, err
+	}
+	k.primary = id
+	return k, nil
+}
+
+// Add adds (or replaces) the key with ID, without making it primary.
+func (k *Keyring) Add(id byte, key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.aeads[id] = aead
+	k.mu.Unlock()
+	return nil
+}
+
+// Rotate makes the key with ID primary, used to seal all values from now on.
+func (k *Keyring) Rotate(id byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.aeads[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownKey, id)
+	}
+	k.primary = id
+	return nil
+}
+
+// Remove removes the key with ID, values sealed with it can no longer be opened. The primary key cannot be removed.
+func (k *Keyring) Remove(id byte) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.aeads[id]; !ok || id == k.primary {
+		return false
+	}
+	delete(k.aeads, id)
+	return true
+}
+
+// Seal encrypts plaintext with the primary key, returning the key ID, nonce and ciphertext.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	k.mu.RLock()
+	id := k.primary
+	aead := k.aeads[id]
+	k.mu.RUnlock()
+
+	out := make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out[0] = id
+	if _, err := rand.Read(out[1:]); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, out[1:], plaintext, nil), nil
+}
+
+// Open decrypts a value returned by Seal, with the key it was sealed with.
+func (k *Keyring) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < 1 {
+		return nil, ErrMalformed
+	}
+
+	k.mu.RLock()
+	aead, ok := k.aeads[sealed[0]]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKey, sealed[0])
+	}
+
+	n := aead.NonceSize()
+	if len(sealed) < 1+n {
+		return nil, ErrMalformed
+	}
+
+	return aead.Open(nil, sealed[1:1+n], sealed[1+n:], nil)
+}
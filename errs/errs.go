@@ -0,0 +1,17 @@
+// Package errs defines the errors returned by the error-returning (E suffixed) method variants of caches in this
+// module, e.g. GetE(), SetE() and InvalidateE(), so callers can handle failure causes the same across backends.
+package errs
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no value exists for key.
+	ErrNotFound = errors.New("cache: not found")
+
+	// ErrCapacity is returned when a value could not be stored, e.g. rejected by an admission filter, or
+	// heavier than the cache's maximum cost on its own.
+	ErrCapacity = errors.New("cache: value not admitted")
+
+	// ErrStopped is returned when the cache has been closed to further operations, e.g. redis.Cache after Close().
+	ErrStopped = errors.New("cache: stopped")
+)
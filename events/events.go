@@ -0,0 +1,258 @@
+// Package events provides an event bus on which caches publish Add, Update, Invalidate and Evict events to registered
+// subscribers, enabling metrics, replication and audit consumers without stacking wrapper callbacks.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+// Kind is the kind of cache event.
+type Kind int
+
+const (
+	// Add is published when a value is added (or set) in the cache.
+	Add Kind = iota
+
+	// Invalidate is published when a value in the cache is invalidated.
+	Invalidate
+
+	// Evict is published when a value is evicted from the cache.
+	Evict
+
+	// Update is published when an existing value in the cache is replaced, see Bus.Observe().
+	Update
+)
+
+// String returns the name of the event kind.
+func (k Kind) String() string {
+	switch k {
+	case Add:
+		return "add"
+	case Invalidate:
+		return "invalidate"
+	case Evict:
+		return "evict"
+	case Update:
+		return "update"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a cache event.
+type Event[Key comparable, Value any] struct {
+	Kind  Kind
+	Key   Key
+	Value Value
+	Time  time.Time
+}
+
+// DropPolicy determines how events are published to a subscriber with a full buffer.
+type DropPolicy int
+
+const (
+	// DropNewest drops the event being published.
+	DropNewest DropPolicy = iota
+
+	// DropOldest drops the oldest buffered event to make room.
+	DropOldest
+
+	// Block blocks the publisher until there is room.
+	Block
+)
+
+// Bus distributes published events to its subscribers. The zero value is ready to use.
+type Bus[Key comparable, Value any] struct {
+	subs map[*Subscription[Key, Value]]struct{}
+	mu   sync.RWMutex
+}
+
+// Subscription is a registered subscriber of a Bus.
+type Subscription[Key comparable, Value any] struct {
+	// C delivers events to channel subscribers, it is closed on Unsubscribe.
+	C <-chan Event[Key, Value]
+
+	bus     *Bus[Key, Value]
+	ch      chan Event[Key, Value]
+	policy  DropPolicy
+	dropped atomic.Uint64
+	quit    chan struct{}
+	once    sync.Once
+}
+
+// Channel registers a subscriber receiving events on Subscription.C, buffering up to size events per given policy.
+func (b *Bus[K, V]) Channel(size int, policy DropPolicy) *Subscription[K, V] {
+	ch := make(chan Event[K, V], size)
+	s := &Subscription[K, V]{
+		C:      ch,
+		bus:    b,
+		ch:     ch,
+		policy: policy,
+		quit:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[*Subscription[K, V]]struct{})
+	}
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s
+}
+
+// Subscribe registers a subscriber calling fn for each event from a separate goroutine, buffering up to size events per given policy.
+func (b *Bus[K, V]) Subscribe(fn func(Event[K, V]), size int, policy DropPolicy) *Subscription[K, V] {
+	s := b.Channel(size, policy)
+	go func() {
+		for ev := range s.C {
+			fn(ev)
+		}
+	}()
+	return s
+}
+
+// Publish publishes event to all subscribers, setting its time if unset.
+func (b *Bus[K, V]) Publish(ev Event[K, V]) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	for s := range b.subs {
+		s.send(ev)
+	}
+	b.mu.RUnlock()
+}
+
+// Attach sets the eviction and invalidate callbacks of given cache to publish Evict and Invalidate events on this bus.
+func (b *Bus[K, V]) Attach(c cache.Cache[K, V]) {
+	c.SetEvictionCallback(func(key K, value V) {
+		b.Publish(Event[K, V]{Kind: Evict, Key: key, Value: value})
+	})
+	c.SetInvalidateCallback(func(key K, value V) {
+		b.Publish(Event[K, V]{Kind: Invalidate, Key: key, Value: value})
+	})
+}
+
+// Observe sets the observer of given ttl cache to publish Add, Update, Invalidate and Evict events on this bus,
+// leaving its eviction and invalidate callbacks free for other use. The event value is the new value for Add and
+// Update events, and the old value otherwise.
+func (b *Bus[K, V]) Observe(c *ttl.Cache[K, V]) {
+	c.SetObserver(func(op ttl.Op, key K, value V) {
+		var kind Kind
+		switch op {
+		case ttl.OpAdd:
+			kind = Add
+		case ttl.OpUpdate:
+			kind = Update
+		case ttl.OpInvalidate:
+			kind = Invalidate
+		case ttl.OpEvict:
+			kind = Evict
+		}
+		b.Publish(Event[K, V]{Kind: kind, Key: key, Value: value})
+	})
+}
+
+// Dropped returns the number of events dropped for this subscriber.
+func (s *Subscription[K, V]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe removes this subscriber from the bus, closing C.
+func (s *Subscription[K, V]) Unsubscribe() {
+	s.once.Do(func() {
+		// Unblock any blocked publish.
+		close(s.quit)
+
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		close(s.ch)
+		s.bus.mu.Unlock()
+	})
+}
+
+// send delivers event to subscriber according to its drop policy.
+func (s *Subscription[K, V]) send(ev Event[K, V]) {
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- ev:
+		case <-s.quit:
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- ev:
+				return
+			default:
+			}
+
+			// Full, drop oldest.
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case s.ch <- ev:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Cache wraps a cache.Cache, publishing an Add event on each successful Add, Set, CAS and Swap. Attach the wrapped cache to also publish Invalidate and Evict events.
+type Cache[Key comparable, Value any] struct {
+	cache.Cache[Key, Value]
+
+	// Bus is the bus events are published on.
+	Bus *Bus[Key, Value]
+}
+
+// Wrap returns given cache wrapped to publish Add events on bus, attaching bus to its callbacks.
+func Wrap[K comparable, V any](c cache.Cache[K, V], bus *Bus[K, V]) *Cache[K, V] {
+	bus.Attach(c)
+	return &Cache[K, V]{Cache: c, Bus: bus}
+}
+
+// Add: implements cache.Cache's Add().
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	ok := c.Cache.Add(key, value)
+	if ok {
+		c.Bus.Publish(Event[K, V]{Kind: Add, Key: key, Value: value})
+	}
+	return ok
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.Cache.Set(key, value)
+	c.Bus.Publish(Event[K, V]{Kind: Add, Key: key, Value: value})
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	ok := c.Cache.CAS(key, old, new, cmp)
+	if ok {
+		c.Bus.Publish(Event[K, V]{Kind: Add, Key: key, Value: new})
+	}
+	return ok
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) V {
+	old := c.Cache.Swap(key, swp)
+	c.Bus.Publish(Event[K, V]{Kind: Add, Key: key, Value: swp})
+	return old
+}
@@ -0,0 +1,75 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/events"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+func TestBus(t *testing.T) {
+	var bus events.Bus[string, int]
+
+	all := bus.Channel(10, events.Block)
+	defer all.Unsubscribe()
+
+	latest := bus.Channel(1, events.DropOldest)
+	defer latest.Unsubscribe()
+
+	c := events.Wrap(cache.New[string, int](0, 1), &bus)
+	c.Set("a", 1)
+	c.Set("b", 2) // evicts "a"
+	c.Invalidate("b")
+
+	expect := []events.Kind{events.Add, events.Evict, events.Add, events.Invalidate}
+	for _, kind := range expect {
+		if ev := <-all.C; ev.Kind != kind {
+			t.Fatalf("expected %s event, got %s", kind, ev.Kind)
+		}
+	}
+
+	if ev := <-latest.C; ev.Kind != events.Invalidate || ev.Key != "b" {
+		t.Fatalf("unexpected latest event: %+v", ev)
+	}
+	if latest.Dropped() != 3 {
+		t.Fatalf("unexpected dropped count: %d", latest.Dropped())
+	}
+}
+
+func TestObserve(t *testing.T) {
+	var bus events.Bus[string, int]
+
+	all := bus.Channel(10, events.Block)
+	defer all.Unsubscribe()
+
+	c := ttl.New[string, int](0, 1, time.Minute)
+	bus.Observe(c)
+
+	// Callbacks remain free for other use.
+	var evicted int
+	c.SetEvictionCallback(func(string, int) { evicted++ })
+
+	c.Set("a", 1)
+	c.Set("a", 2)
+	c.Set("b", 3) // evicts "a"
+	c.Invalidate("b")
+
+	expect := []events.Event[string, int]{
+		{Kind: events.Add, Key: "a", Value: 1},
+		{Kind: events.Update, Key: "a", Value: 2},
+		{Kind: events.Add, Key: "b", Value: 3},
+		{Kind: events.Evict, Key: "a", Value: 2},
+		{Kind: events.Invalidate, Key: "b", Value: 3},
+	}
+	for _, e := range expect {
+		if ev := <-all.C; ev.Kind != e.Kind || ev.Key != e.Key || ev.Value != e.Value {
+			t.Fatalf("expected %+v event, got %+v", e, ev)
+		}
+	}
+
+	if evicted != 1 {
+		t.Fatalf("unexpected eviction callback count: %d", evicted)
+	}
+}
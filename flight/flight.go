@@ -0,0 +1,192 @@
+// Package flight provides generic keyed call coalescing, such that concurrent callers for the same key share
+// a single call's result, optionally retaining successful results for a TTL. It is the shared implementation of
+// cache stampede suppression, plugged into caches via Load or used directly (e.g. by redis.Cache's GetOrLoad).
+package flight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPanicked is returned to callers waiting on a call whose func panicked, the panic itself being raised
+// in the caller that made the call.
+var ErrPanicked = errors.New("flight: call panicked")
+
+// Group coalesces calls by key. The zero value is ready to use, retaining no results beyond in-flight calls.
+type Group[Key comparable, Value any] struct {
+	// TTL is how long successful results are retained, serving later calls for the key without calling again.
+	TTL time.Duration
+
+	// calls is the map of in-flight (and retained) calls.
+	calls map[Key]*call[Value]
+
+	// Embedded mutex.
+	sync.Mutex
+}
+
+// call is an in-flight or retained call result.
+type call[Value any] struct {
+	done    chan struct{}
+	val     Value
+	err     error
+	expires time.Time
+}
+
+// Do calls fn for key, unless a call for key is already in-flight (or its result retained), in which case it waits
+// on and returns that result. Returned bool indicates whether the result was shared with (or by) another caller.
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (V, error, bool) {
+	return g.DoContext(context.Background(), key, func(context.Context) (V, error) {
+		return fn()
+	})
+}
+
+// DoContext is as Do, with ctx passed to fn and bounding the time spent waiting on another caller's call. Note that
+// fn receives the context of the caller that started it, so its cancellation affects all callers sharing the call.
+func (g *Group[K, V]) DoContext(ctx context.Context, key K, fn func(context.Context) (V, error)) (V, error, bool) {
+	g.Lock()
+
+	if g.calls == nil {
+		// Lazily alloc calls map.
+		g.calls = make(map[K]*call[V])
+	}
+
+	if c, ok := g.calls[key]; ok {
+		select {
+		case <-c.done:
+			if time.Now().Before(c.expires) {
+				// Retained result.
+				g.Unlock()
+				return c.val, c.err, true
+			}
+		default:
+			// In-flight, wait.
+			g.Unlock()
+			select {
+			case <-c.done:
+				return c.val, c.err, true
+			case <-ctx.Done():
+				var zero V
+				return zero, ctx.Err(), true
+			}
+		}
+	}
+
+	c := &call[V]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.Unlock()
+
+	g.run(ctx, key, c, fn)
+	return c.val, c.err, false
+}
+
+// run calls fn for c, then completes c, retaining its result or dropping it from calls. If fn panics, c is
+// still completed with ErrPanicked for any waiters, and the panic continues to the caller.
+func (g *Group[K, V]) run(ctx context.Context, key K, c *call[V], fn func(context.Context) (V, error)) {
+	panicked := true
+
+	defer func() {
+		if panicked {
+			// Fail waiters.
+			c.err = ErrPanicked
+		}
+
+		g.Lock()
+		if c.err == nil && g.TTL > 0 {
+			// Retain result until expiry.
+			c.expires = time.Now().Add(g.TTL)
+			time.AfterFunc(g.TTL, func() { g.forget(key, c) })
+		} else if g.calls[key] == c {
+			// Drop if still current.
+			delete(g.calls, key)
+		}
+		g.Unlock()
+
+		close(c.done)
+	}()
+
+	c.val, c.err = fn(ctx)
+	panicked = false
+}
+
+// Forget drops any retained result (or in-flight call) for key, such that the next call for key calls again.
+func (g *Group[K, V]) Forget(key K) {
+	g.Lock()
+	delete(g.calls, key)
+	g.Unlock()
+}
+
+// forget drops c for key, if still current.
+func (g *Group[K, V]) forget(key K, c *call[V]) {
+	g.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.Unlock()
+}
+
+// Cache is the minimal cache interface required by Load, implemented by all caches in this module.
+type Cache[Key comparable, Value any] interface {
+	Get(key Key) (Value, bool)
+	Set(key Key, value Value)
+}
+
+// Load fetches the value with key from c, or on miss loads it using loader via g and stores it in c,
+// such that concurrent misses for the same key result in only a single call to loader.
+func Load[K comparable, V any](c Cache[K, V], g *Group[K, V], key K, loader func() (V, error)) (V, error) {
+	return load(context.Background(), c, g, key, func(context.Context) (V, error) {
+		return loader()
+	})
+}
+
+// Loader wraps a cache with a load func, such that misses are loaded and stored with concurrent misses for
+// the same key coalesced into a single load, see Load().
+type Loader[Key comparable, Value any] struct {
+	cache Cache[Key, Value]
+	load  func(context.Context, Key) (Value, error)
+	group Group[Key, Value]
+}
+
+// NewLoader returns a new Loader wrapping c, loading values on miss using load.
+func NewLoader[K comparable, V any](c Cache[K, V], load func(context.Context, K) (V, error)) *Loader[K, V] {
+	return &Loader[K, V]{cache: c, load: load}
+}
+
+// Cache returns the underlying cache.
+func (l *Loader[K, V]) Cache() Cache[K, V] {
+	return l.cache
+}
+
+// Get fetches the value with key from the cache, or on miss loads and stores it, waiting on any load already
+// in-flight for key. Note ctx is passed to the load func, and bounds the time spent waiting on another caller's load.
+func (l *Loader[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return load(ctx, l.cache, &l.group, key, func(ctx context.Context) (V, error) {
+		return l.load(ctx, key)
+	})
+}
+
+// load performs Load() with context.
+func load[K comparable, V any](ctx context.Context, c Cache[K, V], g *Group[K, V], key K, loader func(context.Context) (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	value, err, _ := g.DoContext(ctx, key, func(ctx context.Context) (V, error) {
+		// Check again, a previous
+		// call may have just stored.
+		if value, ok := c.Get(key); ok {
+			return value, nil
+		}
+
+		value, err := loader(ctx)
+		if err != nil {
+			return value, err
+		}
+
+		c.Set(key, value)
+		return value, nil
+	})
+
+	return value, err
+}
@@ -0,0 +1,198 @@
+package flight_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/flight"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+func TestGroup(t *testing.T) {
+	var (
+		g     flight.Group[string, int]
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+
+	g.TTL = time.Minute
+	release := make(chan struct{})
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, _ := g.Do("key", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 1, nil
+			})
+			if v != 1 || err != nil {
+				t.Errorf("unexpected result: %d %v", v, err)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+	wg.Wait()
+
+	// Retained result should be shared.
+	if _, _, shared := g.Do("key", func() (int, error) { return 2, nil }); !shared {
+		t.Fatal("expected retained result")
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected single call, got %d", calls.Load())
+	}
+
+	g.Forget("key")
+	if v, _, _ := g.Do("key", func() (int, error) { return 2, nil }); v != 2 {
+		t.Fatalf("expected new call after forget, got %d", v)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var g flight.Group[string, int]
+
+	c := ttl.New[string, int](0, 10, time.Minute)
+
+	v, err := flight.Load[string, int](c, &g, "key", func() (int, error) { return 1, nil })
+	if v != 1 || err != nil {
+		t.Fatalf("unexpected load result: %d %v", v, err)
+	}
+
+	if v, ok := c.Get("key"); !ok || v != 1 {
+		t.Fatal("loaded value not stored")
+	}
+}
+
+func TestLoader(t *testing.T) {
+	var (
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+
+	release := make(chan struct{})
+	l := flight.NewLoader[string, int](ttl.New[string, int](0, 10, time.Minute), func(_ context.Context, key string) (int, error) {
+		calls.Add(1)
+		<-release
+		return len(key), nil
+	})
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := l.Get(context.Background(), "key"); v != 3 || err != nil {
+				t.Errorf("unexpected result: %d %v", v, err)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected single load, got %d", calls.Load())
+	}
+
+	if v, ok := l.Cache().Get("key"); !ok || v != 3 {
+		t.Fatal("loaded value not stored")
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	var g flight.Group[string, int]
+
+	started, release := make(chan struct{}), make(chan struct{})
+	waited := make(chan error, 1)
+
+	go func() {
+		defer func() { _ = recover() }()
+		_, _, _ = g.Do("a", func() (int, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+
+	<-started
+	go func() {
+		_, err, _ := g.Do("a", func() (int, error) { return 0, nil })
+		waited <- err
+	}()
+
+	// Let waiter join the call.
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+
+	select {
+	case err := <-waited:
+		if err != flight.ErrPanicked {
+			t.Fatalf("unexpected waiter error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked after panic")
+	}
+
+	// Panicked call dropped.
+	if v, err, _ := g.Do("a", func() (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Fatalf("unexpected result after panic: %d %v", v, err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic not raised to caller")
+			}
+		}()
+		_, _, _ = g.Do("b", func() (int, error) { panic("boom") })
+	}()
+}
+
+func TestGroupForgetInFlight(t *testing.T) {
+	var g flight.Group[string, int]
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_, _, _ = g.Do("a", func() (int, error) {
+			<-release
+			return 1, nil
+		})
+		close(done)
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	// Replace in-flight call with a newer one.
+	g.Forget("a")
+	newer := make(chan struct{})
+	go func() {
+		_, _, _ = g.Do("a", func() (int, error) {
+			<-newer
+			return 2, nil
+		})
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	close(release)
+	<-done
+
+	// Newer call must still be shared.
+	res := make(chan int, 1)
+	go func() {
+		v, _, _ := g.Do("a", func() (int, error) { return 3, nil })
+		res <- v
+	}()
+	time.Sleep(time.Millisecond * 10)
+	close(newer)
+
+	if v := <-res; v != 2 {
+		t.Fatalf("newer in-flight call dropped: %d", v)
+	}
+}
@@ -0,0 +1,307 @@
+// Package httpcache provides HTTP middleware caching handler responses in a cache.Cache, such as a TTLCache or redis backend.
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+// Response is a cached HTTP response.
+type Response struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	Expires time.Time
+
+	// Vary are the request headers the response varies by, per its Vary header. Middleware caches such responses
+	// by these headers' values, under a Response holding only Vary at the request's Key().
+	Vary []string
+}
+
+// Options provides configuration for the response caching Middleware.
+type Options struct {
+	// Cache is the response cache backend. If nil, a TTLCache of Size capacity is used.
+	Cache cache.Cache[string, *Response]
+
+	// Size is the capacity of the default TTLCache backend.
+	Size int
+
+	// TTL is the default response TTL, for responses without their own Cache-Control s-maxage or max-age.
+	TTL time.Duration
+
+	// Routes maps URL path prefixes to response TTLs, the longest matching prefix taking precedence over TTL. A TTL <= 0 disables caching for the route.
+	Routes map[string]time.Duration
+
+	// Headers are the request headers, in addition to method and URL, that responses are keyed by.
+	Headers []string
+
+	// Methods are the cacheable request methods, defaulting to GET and HEAD.
+	Methods []string
+}
+
+// Handler is the response caching http.Handler returned by Middleware.
+type Handler struct {
+	next    http.Handler
+	cache   cache.Cache[string, *Response]
+	stop    func() bool
+	ttl     time.Duration
+	routes  []route
+	headers []string
+	methods map[string]bool
+}
+
+// route is a URL path prefix with its response TTL.
+type route struct {
+	prefix string
+	ttl    time.Duration
+}
+
+// Middleware returns a Handler serving cached responses of next, keyed by request method, URL and selected headers.
+func Middleware(next http.Handler, opts Options) *Handler {
+	if opts.TTL <= 0 {
+		// Default duration
+		opts.TTL = time.Minute
+	}
+
+	if opts.Size <= 0 {
+		// Default capacity
+		opts.Size = 1000
+	}
+
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+
+	h := &Handler{
+		next:    next,
+		cache:   opts.Cache,
+		ttl:     opts.TTL,
+		headers: make([]string, len(opts.Headers)),
+		methods: make(map[string]bool, len(opts.Methods)),
+	}
+
+	if h.cache == nil {
+		// Use a local TTL cache, with maximum TTL of all routes.
+		max := opts.TTL
+		for _, ttl := range opts.Routes {
+			if ttl > max {
+				max = ttl
+			}
+		}
+		c := ttl.New[string, *Response](0, opts.Size, max)
+		c.Start(max / 2)
+		h.cache, h.stop = c, c.Stop
+	}
+
+	for prefix, ttl := range opts.Routes {
+		h.routes = append(h.routes, route{prefix: prefix, ttl: ttl})
+	}
+
+	// Longest prefixes first.
+	sort.Slice(h.routes, func(i, j int) bool {
+		return len(h.routes[i].prefix) > len(h.routes[j].prefix)
+	})
+
+	for i, name := range opts.Headers {
+		h.headers[i] = http.CanonicalHeaderKey(name)
+	}
+
+	for _, method := range opts.Methods {
+		h.methods[method] = true
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler, serving a cached response if available, else caching that of the wrapped handler.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	ttl := h.routeTTL(r.URL.Path)
+	if ttl <= 0 || !h.methods[r.Method] {
+		h.next.ServeHTTP(rw, r)
+		return
+	}
+
+	key := h.Key(r)
+
+	if rsp, ok := h.lookup(key, r); ok {
+		writeResponse(rw, rsp, "HIT")
+		return
+	}
+
+	// Record the wrapped handler's response.
+	rec := &recorder{header: make(http.Header), status: http.StatusOK}
+	h.next.ServeHTTP(rec, r)
+
+	rsp := &Response{
+		Status: rec.status,
+		Header: rec.header,
+		Body:   rec.body.Bytes(),
+	}
+
+	if ttl, ok := storable(r, rsp, ttl); ok {
+		h.store(key, r, rsp, ttl)
+	}
+
+	writeResponse(rw, rsp, "MISS")
+}
+
+// lookup returns the unexpired cached response with key for given request, following any Vary index.
+func (h *Handler) lookup(key string, r *http.Request) (*Response, bool) {
+	rsp, ok := h.cache.Get(key)
+	if ok && len(rsp.Vary) > 0 {
+		// Index of variants, get variant by request headers.
+		rsp, ok = h.cache.Get(varyKey(key, rsp.Vary, r))
+	}
+	return rsp, ok && time.Now().Before(rsp.Expires)
+}
+
+// store caches a copy of response to given request with key for ttl, without any Set-Cookie headers. Responses
+// varying by request headers are stored by their values, indexed at key.
+func (h *Handler) store(key string, r *http.Request, rsp *Response, ttl time.Duration) {
+	stored := *rsp
+	stored.Header = rsp.Header.Clone()
+	stored.Header.Del("Set-Cookie")
+	stored.Expires = time.Now().Add(ttl)
+
+	for _, v := range rsp.Header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				stored.Vary = append(stored.Vary, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	if len(stored.Vary) == 0 {
+		h.cache.Set(key, &stored)
+		return
+	}
+
+	h.cache.Set(key, &Response{Vary: stored.Vary, Expires: stored.Expires})
+	h.cache.Set(varyKey(key, stored.Vary, r), &stored)
+}
+
+// Key returns the cache key of given request, being its method, URL and selected headers.
+func (h *Handler) Key(r *http.Request) string {
+	var buf strings.Builder
+	buf.WriteString(r.Method)
+	buf.WriteByte(' ')
+	buf.WriteString(r.Host)
+	buf.WriteString(r.URL.RequestURI())
+	for _, name := range h.headers {
+		buf.WriteByte('\n')
+		buf.WriteString(name)
+		buf.WriteString(": ")
+		buf.WriteString(strings.Join(r.Header[name], ","))
+	}
+	return buf.String()
+}
+
+// Purge removes the cached response of given request, returning whether one was cached.
+func (h *Handler) Purge(r *http.Request) bool {
+	return h.cache.Invalidate(h.Key(r))
+}
+
+// PurgeAll removes all cached responses.
+func (h *Handler) PurgeAll() {
+	h.cache.Clear()
+}
+
+// Close stops the eviction routine of the default TTLCache backend, if in use.
+func (h *Handler) Close() {
+	if h.stop != nil {
+		h.stop()
+	}
+}
+
+// routeTTL returns the response TTL for given URL path.
+func (h *Handler) routeTTL(path string) time.Duration {
+	for _, r := range h.routes {
+		if strings.HasPrefix(path, r.prefix) {
+			return r.ttl
+		}
+	}
+	return h.ttl
+}
+
+// storable returns the TTL for which response to given request may be stored, by its s-maxage or max-age if set,
+// else given default ttl, and whether it may be stored at all. Responses setting cookies, or to requests carrying
+// credentials (which Key() does not include), are only stored if explicitly marked public.
+func storable(r *http.Request, rsp *Response, ttl time.Duration) (time.Duration, bool) {
+	if rsp.Status != http.StatusOK {
+		return 0, false
+	}
+
+	cc := cacheControl(rsp.Header)
+	if cc.has("no-store") || cc.has("no-cache") || cc.has("private") || rsp.Header.Get("Vary") == "*" {
+		return 0, false
+	}
+
+	if !cc.has("public") && (rsp.Header.Get("Set-Cookie") != "" ||
+		r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "") {
+		return 0, false
+	}
+
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if v, ok := cc[directive]; ok {
+			if secs, err := strconv.Atoi(v); err == nil {
+				ttl = time.Duration(secs) * time.Second
+				break
+			}
+		}
+	}
+
+	return ttl, ttl > 0
+}
+
+// varyKey returns the cache key of the variant of response with key, varying by given request headers.
+func varyKey(key string, vary []string, r *http.Request) string {
+	var buf strings.Builder
+	buf.WriteString(key)
+	for _, name := range vary {
+		buf.WriteString("\nvary ")
+		buf.WriteString(name)
+		buf.WriteString(": ")
+		buf.WriteString(strings.Join(r.Header[name], ","))
+	}
+	return buf.String()
+}
+
+// writeResponse writes given response, marked with an X-Cache status header.
+func writeResponse(rw http.ResponseWriter, rsp *Response, status string) {
+	hdr := rw.Header()
+	for k, v := range rsp.Header {
+		hdr[k] = v
+	}
+	hdr.Set("X-Cache", status)
+	rw.WriteHeader(rsp.Status)
+	_, _ = rw.Write(rsp.Body)
+}
+
+// recorder is an http.ResponseWriter recording the written response.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	once   sync.Once
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.once.Do(func() { r.status = status })
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.once.Do(func() {})
+	return r.body.Write(b)
+}
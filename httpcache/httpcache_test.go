@@ -0,0 +1,158 @@
+package httpcache_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/httpcache"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+
+	h := httpcache.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = rw.Write([]byte(r.Header.Get("Accept-Language")))
+	}), httpcache.Options{
+		TTL:     time.Minute,
+		Routes:  map[string]time.Duration{"/nocache": 0},
+		Headers: []string{"accept-language"},
+	})
+	defer h.Close()
+
+	do := func(method, path, lang string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Accept-Language", lang)
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, r)
+		return rw
+	}
+
+	if rw := do("GET", "/a", "en"); rw.Header().Get("X-Cache") != "MISS" || rw.Body.String() != "en" {
+		t.Fatalf("unexpected first response: %v %q", rw.Header(), rw.Body.String())
+	}
+	if rw := do("GET", "/a", "en"); rw.Header().Get("X-Cache") != "HIT" || rw.Body.String() != "en" {
+		t.Fatalf("unexpected cached response: %v %q", rw.Header(), rw.Body.String())
+	}
+	if rw := do("GET", "/a", "fr"); rw.Body.String() != "fr" {
+		t.Fatalf("response not keyed by header: %q", rw.Body.String())
+	}
+
+	do("POST", "/a", "en")
+	do("GET", "/nocache", "en")
+	do("GET", "/nocache", "en")
+	if calls != 5 {
+		t.Fatalf("unexpected handler calls: %d", calls)
+	}
+
+	if h.Purge(httptest.NewRequest("GET", "/a", nil)) {
+		t.Fatal("purged response keyed by different header")
+	}
+	r := httptest.NewRequest("GET", "/a", nil)
+	r.Header.Set("Accept-Language", "en")
+	if !h.Purge(r) {
+		t.Fatal("failed to purge cached response")
+	}
+	if rw := do("GET", "/a", "en"); rw.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected miss after purge")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var calls, notModified int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", `"v1"`)
+		rw.Header().Set("Cache-Control", "max-age=0")
+		_, _ = rw.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &httpcache.Transport{
+		Cache: cache.New[string, *httpcache.Response](0, 10),
+	}}
+
+	for i := 0; i < 3; i++ {
+		rsp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(rsp.Body)
+		rsp.Body.Close()
+		if string(body) != "body" {
+			t.Fatalf("unexpected body: %q", body)
+		}
+	}
+
+	if calls != 3 || notModified != 2 {
+		t.Fatalf("expected revalidation of stale responses, got calls=%d notModified=%d", calls, notModified)
+	}
+}
+
+func TestMiddlewarePrivate(t *testing.T) {
+	var calls int
+
+	h := httpcache.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/cookie":
+			rw.Header().Set("Set-Cookie", "session=1")
+		case "/public":
+			rw.Header().Set("Set-Cookie", "session=1")
+			rw.Header().Set("Cache-Control", "public")
+		case "/maxage":
+			rw.Header().Set("Cache-Control", "max-age=0")
+		case "/vary":
+			rw.Header().Set("Vary", "Accept-Encoding")
+			_, _ = rw.Write([]byte(r.Header.Get("Accept-Encoding")))
+		}
+	}), httpcache.Options{TTL: time.Minute})
+	defer h.Close()
+
+	do := func(path string, hdr ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for i := 0; i < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, r)
+		return rw
+	}
+
+	for _, req := range [][]string{
+		{"/cookie"},
+		{"/auth", "Authorization", "Bearer x"},
+		{"/cookies", "Cookie", "session=1"},
+		{"/maxage"},
+	} {
+		do(req[0], req[1:]...)
+		if rw := do(req[0], req[1:]...); rw.Header().Get("X-Cache") != "MISS" {
+			t.Fatalf("private response cached: %v", req)
+		}
+	}
+
+	// Public responses cached, without cookies.
+	do("/public")
+	if rw := do("/public"); rw.Header().Get("X-Cache") != "HIT" || rw.Header().Get("Set-Cookie") != "" {
+		t.Fatalf("unexpected public response: %v", rw.Header())
+	}
+
+	// Variants cached by varied header.
+	do("/vary", "Accept-Encoding", "gzip")
+	if rw := do("/vary", "Accept-Encoding", "br"); rw.Header().Get("X-Cache") != "MISS" || rw.Body.String() != "br" {
+		t.Fatalf("unexpected vary response: %v %q", rw.Header(), rw.Body.String())
+	}
+	if rw := do("/vary", "Accept-Encoding", "gzip"); rw.Header().Get("X-Cache") != "HIT" || rw.Body.String() != "gzip" {
+		t.Fatalf("unexpected vary response: %v %q", rw.Header(), rw.Body.String())
+	}
+}
@@ -0,0 +1,179 @@
+package httpcache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Transport is a client-side caching http.RoundTripper, storing GET and HEAD responses in any cache.Cache
+// implementation. Responses are served from cache while fresh per their Cache-Control max-age or Expires
+// headers, and stale responses with an ETag or Last-Modified validator are revalidated conditionally.
+type Transport struct {
+	// Transport is the underlying RoundTripper, defaulting to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Cache stores responses keyed by method and URL.
+	Cache cache.Cache[string, *Response]
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) ||
+		req.Header.Get("Range") != "" {
+		return next.RoundTrip(req)
+	}
+
+	key := req.Method + " " + req.URL.String()
+	reqCC := cacheControl(req.Header)
+
+	cached, ok := t.Cache.Get(key)
+	if ok && !reqCC.has("no-cache") && time.Now().Before(cached.Expires) {
+		return cached.response(req, "HIT"), nil
+	}
+
+	if ok {
+		// Revalidate the stale response, if possible.
+		etag := cached.Header.Get("ETag")
+		modified := cached.Header.Get("Last-Modified")
+
+		if etag != "" || modified != "" {
+			req = req.Clone(req.Context())
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if modified != "" {
+				req.Header.Set("If-Modified-Since", modified)
+			}
+		}
+	}
+
+	rsp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && rsp.StatusCode == http.StatusNotModified {
+		// Still valid, refresh cached headers and expiry.
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		_ = rsp.Body.Close()
+
+		// Copy as cached responses are shared.
+		updated := *cached
+		updated.Header = cached.Header.Clone()
+		for k, v := range rsp.Header {
+			updated.Header[k] = v
+		}
+		cached = &updated
+
+		if expires, store := freshness(cached.Header); store && !reqCC.has("no-store") {
+			cached.Expires = expires
+			t.Cache.Set(key, cached)
+		}
+
+		return cached.response(req, "REVALIDATED"), nil
+	}
+
+	expires, store := freshness(rsp.Header)
+	if !store || reqCC.has("no-store") || rsp.StatusCode != http.StatusOK {
+		return rsp, nil
+	}
+
+	// Read body for caching.
+	body, err := io.ReadAll(rsp.Body)
+	_ = rsp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	t.Cache.Set(key, &Response{
+		Status:  rsp.StatusCode,
+		Header:  rsp.Header.Clone(),
+		Body:    body,
+		Expires: expires,
+	})
+
+	rsp.Body = io.NopCloser(bytes.NewReader(body))
+	return rsp, nil
+}
+
+// response returns a new http.Response for the cached response to given request, marked with an X-Cache status header.
+func (rsp *Response) response(req *http.Request, status string) *http.Response {
+	hdr := rsp.Header.Clone()
+	hdr.Set("X-Cache", status)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rsp.Status, http.StatusText(rsp.Status)),
+		StatusCode:    rsp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        hdr,
+		Body:          io.NopCloser(bytes.NewReader(rsp.Body)),
+		ContentLength: int64(len(rsp.Body)),
+		Request:       req,
+	}
+}
+
+// freshness returns the expiry time of a response with given headers, and whether it may be stored at all.
+// Responses without explicit freshness are only stored if they carry a validator for later revalidation.
+func freshness(hdr http.Header) (time.Time, bool) {
+	now := time.Now()
+	cc := cacheControl(hdr)
+
+	if cc.has("no-store") || hdr.Get("Vary") == "*" {
+		return time.Time{}, false
+	}
+
+	validator := hdr.Get("ETag") != "" || hdr.Get("Last-Modified") != ""
+
+	if cc.has("no-cache") {
+		// Must always be revalidated.
+		return now, validator
+	}
+
+	if v, ok := cc["max-age"]; ok {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return now.Add(time.Duration(secs) * time.Second), secs > 0 || validator
+		}
+	}
+
+	if v := hdr.Get("Expires"); v != "" {
+		if t, err := http.ParseTime(v); err == nil {
+			return t, t.After(now) || validator
+		}
+	}
+
+	return now, validator
+}
+
+// directives are parsed Cache-Control header directives.
+type directives map[string]string
+
+// has returns whether directive is set.
+func (d directives) has(directive string) bool {
+	_, ok := d[directive]
+	return ok
+}
+
+// cacheControl parses the Cache-Control directives in given headers.
+func cacheControl(hdr http.Header) directives {
+	d := make(directives)
+	for _, part := range strings.Split(hdr.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			d[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return d
+}
@@ -0,0 +1,34 @@
+// Package logging defines the Logger interface through which caches in this module report operational events from
+// their background routines (sweeps, compactions, health checks, reconnects, exhausted retries). Its method set
+// matches that of *slog.Logger, which may be passed directly, or any other structured logger may be adapted.
+package logging
+
+// Logger receives operational log events. Args are alternating key-value pairs, as with *slog.Logger.
+type Logger interface {
+	// Debug logs a routine event.
+	Debug(msg string, args ...any)
+
+	// Info logs a notable event.
+	Info(msg string, args ...any)
+
+	// Warn logs an event that may require attention.
+	Warn(msg string, args ...any)
+
+	// Error logs a failure.
+	Error(msg string, args ...any)
+}
+
+// Nop is a Logger discarding all events.
+type Nop struct{}
+
+// Debug implements Logger.
+func (Nop) Debug(string, ...any) {}
+
+// Info implements Logger.
+func (Nop) Info(string, ...any) {}
+
+// Warn implements Logger.
+func (Nop) Warn(string, ...any) {}
+
+// Error implements Logger.
+func (Nop) Error(string, ...any) {}
@@ -0,0 +1,142 @@
+// Package namespace provides versioned key namespaces atop a cache, where each namespace has a generation counter
+// included in its keys, such that bumping the generation instantly invalidates the whole namespace without
+// enumerating its keys. Entries of old generations are no longer reachable, and are left to be evicted or expire.
+package namespace
+
+import (
+	"sync"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Generations stores namespace generation counters, e.g. LocalGenerations or redis.Generations.
+type Generations interface {
+	// Generation returns the current generation of namespace.
+	Generation(ns string) (uint64, error)
+
+	// Bump increments the generation of namespace, returning the new generation.
+	Bump(ns string) (uint64, error)
+}
+
+// LocalGenerations stores namespace generation counters in-process. The zero value is ready to use.
+type LocalGenerations struct {
+	gens map[string]uint64
+	mu   sync.Mutex
+}
+
+// Generation implements Generations.
+func (g *LocalGenerations) Generation(ns string) (uint64, error) {
+	g.mu.Lock()
+	gen := g.gens[ns]
+	g.mu.Unlock()
+	return gen, nil
+}
+
+// Bump implements Generations.
+func (g *LocalGenerations) Bump(ns string) (uint64, error) {
+	g.mu.Lock()
+	if g.gens == nil {
+		g.gens = make(map[string]uint64)
+	}
+	g.gens[ns]++
+	gen := g.gens[ns]
+	g.mu.Unlock()
+	return gen, nil
+}
+
+// Key is a namespaced cache key.
+type Key[K comparable] struct {
+	NS  string
+	Gen uint64
+	Key K
+}
+
+// Manager manages versioned namespaces within a single cache.
+type Manager[K comparable, Value any] struct {
+	cache cache.Cache[Key[K], Value]
+	gens  Generations
+}
+
+// NewManager returns a new Manager of namespaces within c, with generations stored in gens.
+func NewManager[K comparable, V any](c cache.Cache[Key[K], V], gens Generations) *Manager[K, V] {
+	return &Manager[K, V]{cache: c, gens: gens}
+}
+
+// Namespace returns the namespace with name.
+func (m *Manager[K, V]) Namespace(name string) *Namespace[K, V] {
+	return &Namespace[K, V]{m: m, name: name}
+}
+
+// Bump invalidates the namespace with name, by incrementing its generation.
+func (m *Manager[K, V]) Bump(name string) error {
+	_, err := m.gens.Bump(name)
+	return err
+}
+
+// Namespace is a versioned namespace of keys. Operations fail (as misses) if its generation cannot be fetched.
+type Namespace[K comparable, V any] struct {
+	m    *Manager[K, V]
+	name string
+}
+
+// Name returns the namespace name.
+func (ns *Namespace[K, V]) Name() string {
+	return ns.name
+}
+
+// Bump invalidates all keys in the namespace.
+func (ns *Namespace[K, V]) Bump() error {
+	return ns.m.Bump(ns.name)
+}
+
+// Get fetches the value with key in the namespace.
+func (ns *Namespace[K, V]) Get(key K) (V, bool) {
+	k, err := ns.key(key)
+	if err != nil {
+		var zero V
+		return zero, false
+	}
+	return ns.m.cache.Get(k)
+}
+
+// Add places the value at key in the namespace, doing nothing if a value with this key already exists.
+func (ns *Namespace[K, V]) Add(key K, value V) bool {
+	k, err := ns.key(key)
+	if err != nil {
+		return false
+	}
+	return ns.m.cache.Add(k, value)
+}
+
+// Set places the value at key in the namespace.
+func (ns *Namespace[K, V]) Set(key K, value V) {
+	k, err := ns.key(key)
+	if err != nil {
+		return
+	}
+	ns.m.cache.Set(k, value)
+}
+
+// Has checks the namespace for a value with key.
+func (ns *Namespace[K, V]) Has(key K) bool {
+	k, err := ns.key(key)
+	if err != nil {
+		return false
+	}
+	return ns.m.cache.Has(k)
+}
+
+// Invalidate deletes the value with key from the namespace.
+func (ns *Namespace[K, V]) Invalidate(key K) bool {
+	k, err := ns.key(key)
+	if err != nil {
+		return false
+	}
+	return ns.m.cache.Invalidate(k)
+}
+
+// key returns the cache key for key in the current namespace generation.
+func (ns *Namespace[K, V]) key(key K) (Key[K], error) {
+	gen, err := ns.m.gens.Generation(ns.name)
+	return Key[K]{NS: ns.name, Gen: gen, Key: key}, err
+}
@@ -0,0 +1,36 @@
+package namespace_test
+
+import (
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/namespace"
+)
+
+func TestManager(t *testing.T) {
+	m := namespace.NewManager[string, int](
+		cache.New[namespace.Key[string], int](0, 100),
+		&namespace.LocalGenerations{},
+	)
+
+	users := m.Namespace("users")
+	posts := m.Namespace("posts")
+
+	users.Set("a", 1)
+	posts.Set("a", 2)
+
+	if v, ok := users.Get("a"); !ok || v != 1 {
+		t.Fatal("failed to get namespaced value")
+	}
+
+	if err := users.Bump(); err != nil {
+		t.Fatal(err)
+	}
+
+	if users.Has("a") {
+		t.Fatal("namespace not invalidated by bump")
+	}
+	if v, ok := posts.Get("a"); !ok || v != 2 {
+		t.Fatal("other namespace invalidated by bump")
+	}
+}
@@ -0,0 +1,146 @@
+// Package ratelimit provides per-key sliding window rate limiters built on the cache primitives, backed either
+// by a local TTL cache, or by Redis for limiting distributed across processes.
+//
+// Both limiters approximate a sliding window by weighting the previous fixed window's count by its overlap
+// with the sliding window, which is accurate to within a small error without storing individual events.
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/redis"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+// Limiter limits the rate of events per key.
+type Limiter[Key comparable] interface {
+	// Allow reports whether an event for key may happen now, recording it if so.
+	Allow(key Key) bool
+}
+
+// counter is a sliding window counter.
+type counter struct {
+	idx  int64 // current fixed window index
+	cur  int   // current fixed window count
+	prev int   // previous fixed window count
+}
+
+// Local is a Limiter allowing up to limit events per key within any window duration, tracking keys in a local TTL cache.
+type Local[Key comparable] struct {
+	cache  *ttl.Cache[Key, *counter]
+	limit  int
+	window time.Duration
+	mu     sync.Mutex
+}
+
+// NewLocal returns a new Local limiter, tracking up to cap keys.
+func NewLocal[K comparable](limit int, window time.Duration, cap int) *Local[K] {
+	return &Local[K]{
+		cache:  ttl.New[K, *counter](0, cap, window*2),
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Start will start the background routine dropping keys idle beyond two windows, see ttl.Cache.Start().
+func (l *Local[K]) Start(freq time.Duration) bool {
+	return l.cache.Start(freq)
+}
+
+// Stop will stop the background routine, see ttl.Cache.Stop().
+func (l *Local[K]) Stop() bool {
+	return l.cache.Stop()
+}
+
+// Allow implements Limiter.
+func (l *Local[K]) Allow(key K) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n events for key may happen now, recording them if so.
+func (l *Local[K]) AllowN(key K, n int) bool {
+	now := time.Now().UnixNano()
+	idx, frac := split(now, l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.cache.Get(key)
+	if !ok {
+		w = &counter{idx: idx}
+		l.cache.Set(key, w)
+	}
+
+	switch {
+	case w.idx == idx:
+	case w.idx == idx-1:
+		// Roll into next window.
+		w.idx, w.prev, w.cur = idx, w.cur, 0
+	default:
+		// Idle for over a window.
+		w.idx, w.prev, w.cur = idx, 0, 0
+	}
+
+	if estimate(w.prev, w.cur, frac)+float64(n) > float64(l.limit) {
+		return false
+	}
+
+	w.cur += n
+	return true
+}
+
+// Redis is a Limiter allowing up to limit events per key within any window duration, distributed across all
+// processes sharing the Redis keys. Window boundaries are computed from local clocks, which should be in sync.
+type Redis struct {
+	pool   *redis.Pool
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewRedis returns a new Redis limiter, storing counters at keys with prefix using pool.
+func NewRedis(pool *redis.Pool, prefix string, limit int, window time.Duration) *Redis {
+	return &Redis{
+		pool:   pool,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Allow implements Limiter, failing closed (disallowing) on error.
+func (l *Redis) Allow(key string) bool {
+	ok, err := l.AllowN(context.Background(), key, 1)
+	return ok && err == nil
+}
+
+// AllowN reports whether n events for key may happen now, recording them if so.
+func (l *Redis) AllowN(ctx context.Context, key string, n int) (bool, error) {
+	idx, frac := split(time.Now().UnixNano(), l.window)
+
+	// Hash tag keys to the same cluster slot.
+	base := l.prefix + "{" + key + "}:"
+	keys := []string{
+		base + strconv.FormatInt(idx, 10),
+		base + strconv.FormatInt(idx-1, 10),
+	}
+
+	res, err := slidingWindowScript.Run(ctx, l.pool.Client(), keys,
+		l.limit, n, frac, l.window.Milliseconds()*2,
+	).Int()
+	return res == 1, err
+}
+
+// split returns the fixed window index of time now, and the fraction of that window elapsed.
+func split(now int64, window time.Duration) (int64, float64) {
+	w := int64(window)
+	return now / w, float64(now%w) / float64(w)
+}
+
+// estimate returns the sliding window count estimate, from the previous and current fixed window counts.
+func estimate(prev, cur int, frac float64) float64 {
+	return float64(prev)*(1-frac) + float64(cur)
+}
@@ -0,0 +1,24 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/ratelimit"
+)
+
+func TestLocal(t *testing.T) {
+	var l ratelimit.Limiter[string] = ratelimit.NewLocal[string](3, time.Hour, 10)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow("a") {
+			t.Fatalf("event %d unexpectedly limited", i)
+		}
+	}
+	if l.Allow("a") {
+		t.Fatal("event beyond limit allowed")
+	}
+	if !l.Allow("b") {
+		t.Fatal("limit not tracked per key")
+	}
+}
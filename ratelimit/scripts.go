@@ -0,0 +1,20 @@
+package ratelimit
+
+import (
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// slidingWindowScript records ARGV[2] events in the current fixed window counter at KEYS[1], if the
+// sliding window estimate (weighting the previous window at KEYS[2] by 1 - ARGV[3] elapsed fraction)
+// would remain within limit ARGV[1], returning 1 if so. Counters expire after ARGV[4] milliseconds.
+var slidingWindowScript = goredis.NewScript(`
+local cur = tonumber(redis.call("GET", KEYS[1]) or "0")
+local prev = tonumber(redis.call("GET", KEYS[2]) or "0")
+local n = tonumber(ARGV[2])
+if prev * (1 - tonumber(ARGV[3])) + cur + n > tonumber(ARGV[1]) then
+    return 0
+end
+redis.call("INCRBY", KEYS[1], n)
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return 1
+`)
@@ -0,0 +1,137 @@
+package redis
+
+import (
+    "errors"
+    "sync"
+    "time"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker is open and commands are not being sent to Redis.
+var ErrCircuitOpen = errors.New("redis: circuit breaker is open")
+
+type breakerState int
+
+const (
+    breakerClosed breakerState = iota
+    breakerOpen
+    breakerHalfOpen
+)
+
+// breaker is a circuit breaker that opens after a threshold of consecutive failures, failing
+// fast until the open duration has passed, after which a limited number of probes are allowed
+// through (half-open) and the breaker closes again once they have all succeeded.
+type breaker struct {
+    threshold int
+    openFor   time.Duration
+    probes    int
+
+    state     breakerState
+    failures  int
+    successes int
+    inflight  int
+    openedAt  time.Time
+    mu        sync.Mutex
+}
+
+// newBreaker returns a new breaker configured from options, or nil if disabled.
+func newBreaker(opts *Options) *breaker {
+    if opts.BreakerThreshold <= 0 {
+        return nil
+    }
+
+    b := &breaker{
+        threshold: opts.BreakerThreshold,
+        openFor:   opts.BreakerOpenTimeout,
+        probes:    opts.BreakerProbes,
+    }
+
+    if b.openFor <= 0 {
+        // Default duration
+        b.openFor = time.Second * 5
+    }
+
+    if b.probes <= 0 {
+        // Default probes
+        b.probes = 1
+    }
+
+    return b
+}
+
+// allow returns whether a command may currently be attempted.
+func (b *breaker) allow() bool {
+    if b == nil {
+        return true
+    }
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    switch b.state {
+    case breakerOpen:
+        if time.Since(b.openedAt) < b.openFor {
+            return false
+        }
+
+        // Open period over, start probing.
+        b.state = breakerHalfOpen
+        b.successes = 0
+        b.inflight = 0
+        fallthrough
+
+    case breakerHalfOpen:
+        if b.inflight >= b.probes {
+            return false
+        }
+        b.inflight++
+        return true
+
+    default:
+        return true
+    }
+}
+
+// record records the result of an attempted command.
+func (b *breaker) record(failed bool) {
+    if b == nil {
+        return
+    }
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    switch b.state {
+    case breakerClosed:
+        if !failed {
+            b.failures = 0
+            return
+        }
+
+        b.failures++
+        if b.failures >= b.threshold {
+            b.open()
+        }
+
+    case breakerHalfOpen:
+        if b.inflight > 0 {
+            b.inflight--
+        }
+
+        if failed {
+            b.open()
+            return
+        }
+
+        b.successes++
+        if b.successes >= b.probes {
+            b.state = breakerClosed
+            b.failures = 0
+        }
+    }
+}
+
+// open transitions breaker to the open state, requires lock held.
+func (b *breaker) open() {
+    b.state = breakerOpen
+    b.openedAt = time.Now()
+}
@@ -2,19 +2,34 @@ package redis
 
 import (
     "context"
-    "encoding/json"
-    "fmt"
+    "math/rand"
     "sync"
+    "time"
 
     "github.com/go-redis/redis/v8"
+    "github.com/mkc188/go-cache/v3/errs"
+    "github.com/mkc188/go-cache/v3/flight"
+    "go.opentelemetry.io/otel/trace"
 )
 
 type Cache[Key comparable, Value any] struct {
-    pool    *Pool
-    opts    *Options
-    evict   func(Key, Value)
-    invalid func(Key, Value)
-    sync.RWMutex
+    pool     *Pool
+    opts     *Options
+    evict    func(Key, Value)
+    invalid  func(Key, Value)
+    unlisten func()
+    dels     *localDels
+    inflight inflight
+    wb       *writeBehind[Key]
+    fb       *fallback[Key, Value]
+    loads    flight.Group[string, Value]
+    tracer   trace.Tracer
+    breaker  *breaker
+    keys     KeyEncoder[Key]
+    serial   Serializer
+    prefixes []prefixSerializer
+    once     sync.Once
+    mu       sync.RWMutex
 }
 
 func New[K comparable, V any](opts *Options) *Cache[K, V] {
@@ -24,34 +39,140 @@ func New[K comparable, V any](opts *Options) *Cache[K, V] {
 
     pool := NewPool(opts)
 
-    return &Cache[K, V]{
-        pool: pool,
-        opts: opts,
+    c := &Cache[K, V]{
+        pool:    pool,
+        opts:    opts,
+        breaker: newBreaker(opts),
+        keys:    DefaultKeyEncoder[K](),
+        serial:  JSONSerializer{},
     }
+
+    if opts.TracerProvider != nil {
+        c.tracer = opts.TracerProvider.Tracer(tracerName)
+    }
+
+    if opts.KeyspaceNotifications {
+        c.unlisten = c.listenKeyEvents()
+    }
+
+    if opts.WriteBehindInterval > 0 {
+        c.startWriteBehind()
+    }
+
+    if opts.FallbackSize > 0 {
+        c.startFallback()
+    }
+
+    return c
+}
+
+// Dial is equivalent to New, but eagerly connects to Redis, returning the connection error (if any) instead of
+// discovering it on first use. New in comparison is lazy, with connections only established once needed.
+func Dial[K comparable, V any](ctx context.Context, opts *Options) (*Cache[K, V], error) {
+    c := New[K, V](opts)
+    if err := c.pool.Ping(ctx); err != nil {
+        _ = c.Close()
+        return nil, err
+    }
+    return c, nil
 }
 
 func (c *Cache[K, V]) Close() error {
-    return c.pool.Close()
+    return c.CloseContext(context.Background())
+}
+
+// CloseContext closes the cache, rejecting any new operations and waiting for those in-flight (including their
+// retries) to finish, then flushing any queued writes before closing the pool. If ctx expires first the pool is
+// closed regardless, returning ctx error. Further calls are no-ops.
+func (c *Cache[K, V]) CloseContext(ctx context.Context) error {
+    var err error
+    c.once.Do(func() {
+        err = c.close(ctx)
+    })
+    return err
+}
+
+// close performs CloseContext.
+func (c *Cache[K, V]) close(ctx context.Context) error {
+    // Reject new operations,
+    // and wait on in-flight.
+    waitErr := c.inflight.close(ctx)
+
+    // Flush any queued writes,
+    // then replay any buffered.
+    c.stopWriteBehind()
+    c.stopFallback()
+
+    if c.unlisten != nil {
+        c.unlisten()
+    }
+
+    if err := c.pool.Close(); err != nil {
+        return err
+    }
+
+    return waitErr
+}
+
+// Pool returns the underlying connection pool.
+func (c *Cache[K, V]) Pool() *Pool {
+    return c.pool
 }
 
 func (c *Cache[K, V]) SetEvictionCallback(hook func(K, V)) {
-    c.Lock()
+    c.mu.Lock()
     c.evict = hook
-    c.Unlock()
+    c.mu.Unlock()
 }
 
 func (c *Cache[K, V]) SetInvalidateCallback(hook func(K, V)) {
-    c.Lock()
+    c.mu.Lock()
     c.invalid = hook
-    c.Unlock()
+    c.mu.Unlock()
+}
+
+// SetKeyEncoder sets the encoder used to produce Redis keys, this should be set before first use.
+func (c *Cache[K, V]) SetKeyEncoder(enc KeyEncoder[K]) {
+    if enc == nil {
+        enc = DefaultKeyEncoder[K]()
+    }
+    c.mu.Lock()
+    c.keys = enc
+    c.mu.Unlock()
 }
 
 func (c *Cache[K, V]) Get(key K) (V, bool) {
+    value, err := c.GetE(key)
+    return value, err == nil
+}
+
+// GetE is equivalent to Get, but returns the cause of failure: errs.ErrNotFound if no value exists for key,
+// errs.ErrStopped once closed, or the Redis (or deserialization) error otherwise.
+func (c *Cache[K, V]) GetE(key K) (V, error) {
     var value V
-    ctx := context.Background()
+    var hit bool
+    var size int
 
-    err := c.withRetry(ctx, func(ctx context.Context) error {
-        data, err := c.pool.Client().Get(ctx, c.formatKey(key)).Bytes()
+    ctx, op := c.begin(context.Background(), "Get", 1)
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        op.end(err)
+        return value, err
+    }
+
+    if w, ok := c.pending(rkey); ok {
+        // Serve queued write.
+        var err error
+        if w.data != nil {
+            err = c.unmarshal(rkey, w.data, &value)
+            hit = err == nil
+        }
+        op.end(nil, attrHit.Bool(hit), attrPayloadSize.Int(len(w.data)))
+        return value, result(hit, err)
+    }
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        data, err := c.pool.Client().Get(ctx, rkey).Bytes()
         if err != nil {
             if err == redis.Nil {
                 return nil
@@ -59,26 +180,62 @@ func (c *Cache[K, V]) Get(key K) (V, bool) {
             return err
         }
 
-        return json.Unmarshal(data, &value)
+        size = len(data)
+        if err := c.unmarshal(rkey, data, &value); err != nil {
+            return err
+        }
+        hit = true
+        return nil
     })
 
-    if err != nil {
-        return value, false
+    if c.fb != nil {
+        if err != nil && unavailable(err) {
+            // Serve from local fallback.
+            value, hit = c.fb.local.Get(key)
+            op.end(err, attrHit.Bool(hit))
+            if hit {
+                return value, nil
+            }
+            return value, result(false, err)
+        } else if err == nil && hit {
+            c.fb.local.Set(key, value)
+        }
     }
 
-    return value, true
+    op.end(err, attrHit.Bool(hit), attrPayloadSize.Int(size))
+
+    return value, result(hit, err)
 }
 
 func (c *Cache[K, V]) Add(key K, value V) bool {
-    ctx := context.Background()
-    data, err := json.Marshal(value)
+    return c.AddWithTTL(key, value, c.opts.DefaultTTL)
+}
+
+// AddWithTTL is equivalent to Add, but stores the value with given TTL instead of Options.DefaultTTL.
+func (c *Cache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) bool {
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "Add")
+        op.end(err)
+        return false
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "Add", rkey)
+    data, err := c.marshal(rkey, value)
     if err != nil {
+        op.end(err)
+        return false
+    }
+
+    // Apply queued writes first.
+    if err := c.flushKeys(ctx, rkey); err != nil {
+        op.end(err)
         return false
     }
 
     var success bool
     err = c.withRetry(ctx, func(ctx context.Context) error {
-        result, err := c.pool.Client().SetNX(ctx, c.formatKey(key), data, c.opts.DefaultTTL).Result()
+        result, err := c.pool.Client().SetNX(ctx, rkey, data, c.jitter(ttl)).Result()
         if err != nil {
             return err
         }
@@ -86,83 +243,255 @@ func (c *Cache[K, V]) Add(key K, value V) bool {
         return nil
     })
 
+    op.end(err, attrPayloadSize.Int(len(data)))
+
     return err == nil && success
 }
 
 func (c *Cache[K, V]) Set(key K, value V) {
-    ctx := context.Background()
-    data, err := json.Marshal(value)
+    c.SetWithTTL(key, value, c.opts.DefaultTTL)
+}
+
+// SetE is equivalent to Set, but returns the cause of failure: errs.ErrStopped once closed, or the Redis (or
+// serialization) error otherwise. Writes queued for write-behind, or buffered while Redis is unreachable, succeed.
+func (c *Cache[K, V]) SetE(key K, value V) error {
+    return c.setWithTTL(key, value, c.opts.DefaultTTL)
+}
+
+// SetWithTTL is equivalent to Set, but stores the value with given TTL instead of Options.DefaultTTL.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+    _ = c.setWithTTL(key, value, ttl)
+}
+
+// setWithTTL performs SetWithTTL, returning the cause of any failure as SetE.
+func (c *Cache[K, V]) setWithTTL(key K, value V, ttl time.Duration) error {
+    rkey, err := c.formatKey(key)
     if err != nil {
-        return
+        _, op := c.beginWrite(context.Background(), "Set")
+        op.end(err)
+        return err
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "Set", rkey)
+    data, err := c.marshal(rkey, value)
+    if err != nil {
+        op.end(err)
+        return err
+    }
+
+    if c.wb != nil {
+        if !c.inflight.acquire() {
+            op.end(errs.ErrStopped)
+            return errs.ErrStopped
+        }
+
+        // Queue for write-behind.
+        c.queue(rkey, pendingWrite[K]{
+            key:  key,
+            data: data,
+            ttl:  ttl,
+        })
+        c.inflight.release()
+        op.end(nil, attrPayloadSize.Int(len(data)))
+        return nil
     }
 
     var oldValue V
     var hadOldValue bool
+    invalid := c.invalidHook()
 
     err = c.withRetry(ctx, func(ctx context.Context) error {
-        // Get old value for invalidation callback if needed
-        if c.invalid != nil {
-            oldData, err := c.pool.Client().Get(ctx, c.formatKey(key)).Bytes()
-            if err == nil {
-                if err := json.Unmarshal(oldData, &oldValue); err == nil {
-                    hadOldValue = true
-                }
+        if invalid == nil {
+            return c.pool.Client().Set(ctx, rkey, data, c.jitter(ttl)).Err()
+        }
+
+        // Get old value for invalidation callback in the same command
+        oldData, err := c.pool.Client().SetArgs(ctx, rkey, data, redis.SetArgs{
+            TTL: c.jitter(ttl),
+            Get: true,
+        }).Result()
+        if err != nil {
+            if err == redis.Nil {
+                return nil
             }
+            return err
         }
 
-        return c.pool.Client().Set(ctx, c.formatKey(key), data, c.opts.DefaultTTL).Err()
+        if err := c.unmarshal(rkey, []byte(oldData), &oldValue); err == nil {
+            hadOldValue = true
+        }
+        return nil
     })
 
-    if err == nil && hadOldValue && c.invalid != nil {
-        c.invalid(key, oldValue)
+    op.end(err, attrPayloadSize.Int(len(data)))
+
+    if c.fb != nil {
+        if err != nil && unavailable(err) {
+            // Buffer for when reachable.
+            c.buffer(rkey, value, pendingWrite[K]{
+                key:  key,
+                data: data,
+                ttl:  ttl,
+            })
+            return nil
+        } else if err == nil {
+            c.fb.local.Set(key, value)
+        }
     }
+
+    if err == nil && hadOldValue && invalid != nil {
+        invalid(key, oldValue)
+    }
+
+    return result(true, err)
 }
 
 func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
-    c.Lock()
-    defer c.Unlock()
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "CAS")
+        op.end(err)
+        return false
+    }
 
-    current, exists := c.Get(key)
-    if !exists {
+    ctx, op := c.beginWrite(context.Background(), "CAS", rkey)
+
+    // Apply queued writes first.
+    if err := c.flushKeys(ctx, rkey); err != nil {
+        op.end(err)
+        return false
+    }
+
+    var current V
+    var currentData []byte
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        data, err := c.pool.Client().Get(ctx, rkey).Bytes()
+        if err != nil {
+            return err
+        }
+        currentData = data
+        return c.unmarshal(rkey, data, &current)
+    })
+    if err != nil {
+        if err == redis.Nil {
+            err = nil
+        }
+        op.end(err, attrHit.Bool(false))
         return false
     }
 
     if !cmp(old, current) {
+        op.end(nil, attrHit.Bool(true))
         return false
     }
 
-    ctx := context.Background()
-    data, err := json.Marshal(new)
+    data, err := c.marshal(rkey, new)
     if err != nil {
+        op.end(err, attrHit.Bool(true))
         return false
     }
 
+    // Only swap in the new value if the stored value is still
+    // the one we compared against, this is checked server-side.
+    var swapped bool
     err = c.withRetry(ctx, func(ctx context.Context) error {
-        return c.pool.Client().Set(ctx, c.formatKey(key), data, c.opts.DefaultTTL).Err()
+        result, err := casScript.Run(ctx, c.pool.Client(), []string{rkey},
+            currentData, data, c.jitter(c.opts.DefaultTTL).Milliseconds()).Int()
+        if err != nil {
+            return err
+        }
+        swapped = result == 1
+        return nil
     })
 
-    if err == nil && c.invalid != nil {
-        c.invalid(key, old)
+    op.end(err, attrHit.Bool(true), attrPayloadSize.Int(len(data)))
+
+    if invalid := c.invalidHook(); err == nil && swapped && invalid != nil {
+        invalid(key, current)
     }
 
-    return err == nil
+    return err == nil && swapped
 }
 
 func (c *Cache[K, V]) Swap(key K, swp V) V {
-    c.Lock()
-    defer c.Unlock()
-
-    old, _ := c.Get(key)
-    c.Set(key, swp)
+    old, _ := c.GetSet(key, swp)
     return old
 }
 
+// GetSet places the value at key in the cache, returning the previous value and whether one existed, in a single command.
+func (c *Cache[K, V]) GetSet(key K, value V) (V, bool) {
+    var oldValue V
+    var hadOldValue bool
+
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "GetSet")
+        op.end(err)
+        return oldValue, false
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "GetSet", rkey)
+    data, err := c.marshal(rkey, value)
+    if err != nil {
+        op.end(err)
+        return oldValue, false
+    }
+
+    // Apply queued writes first.
+    if err := c.flushKeys(ctx, rkey); err != nil {
+        op.end(err)
+        return oldValue, false
+    }
+
+    // SET ... GET both stores the new value and returns
+    // the previous one in a single atomic command.
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        oldData, err := c.pool.Client().SetArgs(ctx, rkey, data, redis.SetArgs{
+            TTL: c.jitter(c.opts.DefaultTTL),
+            Get: true,
+        }).Result()
+        if err != nil {
+            if err == redis.Nil {
+                return nil
+            }
+            return err
+        }
+
+        if err := c.unmarshal(rkey, []byte(oldData), &oldValue); err == nil {
+            hadOldValue = true
+        }
+        return nil
+    })
+
+    op.end(err, attrHit.Bool(hadOldValue), attrPayloadSize.Int(len(data)))
+
+    if invalid := c.invalidHook(); err == nil && hadOldValue && invalid != nil {
+        invalid(key, oldValue)
+    }
+
+    return oldValue, hadOldValue
+}
+
 func (c *Cache[K, V]) Has(key K) bool {
-    ctx := context.Background()
+    ctx, op := c.begin(context.Background(), "Has", 1)
     var exists bool
 
-    err := c.withRetry(ctx, func(ctx context.Context) error {
-        result, err := c.pool.Client().Exists(ctx, c.formatKey(key)).Result()
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        op.end(err)
+        return false
+    }
+
+    if w, ok := c.pending(rkey); ok {
+        // Check queued write.
+        exists = w.data != nil
+        op.end(nil, attrHit.Bool(exists))
+        return exists
+    }
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        result, err := c.pool.Client().Exists(ctx, rkey).Result()
         if err != nil {
             return err
         }
@@ -170,16 +499,136 @@ func (c *Cache[K, V]) Has(key K) bool {
         return nil
     })
 
+    op.end(err, attrHit.Bool(exists))
+
     return err == nil && exists
 }
 
+// GetTTL returns the remaining TTL of the value with key, and whether it exists. A value stored without expiry returns a negative TTL.
+func (c *Cache[K, V]) GetTTL(key K) (time.Duration, bool) {
+    ctx, op := c.begin(context.Background(), "GetTTL", 1)
+    var ttl time.Duration
+
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        op.end(err)
+        return 0, false
+    }
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        result, err := c.pool.Client().PTTL(ctx, rkey).Result()
+        if err != nil {
+            return err
+        }
+        ttl = result
+        return nil
+    })
+
+    // PTTL returns -2 for a missing key, -1 for no expiry.
+    exists := err == nil && ttl != -2
+
+    op.end(err, attrHit.Bool(exists))
+
+    if !exists {
+        return 0, false
+    }
+    return ttl, true
+}
+
+// Touch resets the TTL of the value with key to Options.DefaultTTL, returning whether it exists.
+func (c *Cache[K, V]) Touch(key K) bool {
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "Touch")
+        op.end(err)
+        return false
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "Touch", rkey)
+    var ok bool
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        var err error
+        if c.opts.DefaultTTL > 0 {
+            ok, err = c.pool.Client().PExpire(ctx, rkey, c.jitter(c.opts.DefaultTTL)).Result()
+        } else {
+            // No default TTL, ensure no expiry.
+            _, err = c.pool.Client().Persist(ctx, rkey).Result()
+            if err == nil {
+                var n int64
+                n, err = c.pool.Client().Exists(ctx, rkey).Result()
+                ok = n > 0
+            }
+        }
+        return err
+    })
+
+    op.end(err, attrHit.Bool(ok))
+
+    return err == nil && ok
+}
+
+// Extend extends the remaining TTL of the value with key by d, returning whether it exists. Values without expiry are unchanged.
+func (c *Cache[K, V]) Extend(key K, d time.Duration) bool {
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "Extend")
+        op.end(err)
+        return false
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "Extend", rkey)
+    var ok bool
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        result, err := extendScript.Run(ctx, c.pool.Client(), []string{rkey}, d.Milliseconds()).Int()
+        if err != nil {
+            return err
+        }
+        ok = result == 1
+        return nil
+    })
+
+    op.end(err, attrHit.Bool(ok))
+
+    return err == nil && ok
+}
+
 func (c *Cache[K, V]) Invalidate(key K) bool {
-    ctx := context.Background()
+    return c.InvalidateE(key) == nil
+}
+
+// InvalidateE is equivalent to Invalidate, but returns the cause of failure: errs.ErrNotFound if no value exists
+// for key, errs.ErrStopped once closed, or the Redis error otherwise.
+func (c *Cache[K, V]) InvalidateE(key K) error {
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "Invalidate")
+        op.end(err)
+        return err
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "Invalidate", rkey)
     var success bool
 
-    if oldVal, exists := c.Get(key); exists {
-        err := c.withRetry(ctx, func(ctx context.Context) error {
-            result, err := c.pool.Client().Del(ctx, c.formatKey(key)).Result()
+    if c.wb != nil {
+        if !c.inflight.acquire() {
+            op.end(errs.ErrStopped)
+            return errs.ErrStopped
+        }
+
+        // Queue for write-behind.
+        c.queue(rkey, pendingWrite[K]{key: key})
+        c.inflight.release()
+        op.end(nil)
+        return nil
+    }
+
+    oldVal, getErr := c.GetE(key)
+    if getErr == nil {
+        err = c.withRetry(ctx, func(ctx context.Context) error {
+            c.dels.mark(rkey)
+            result, err := c.pool.Client().Del(ctx, rkey).Result()
             if err != nil {
                 return err
             }
@@ -187,47 +636,131 @@ func (c *Cache[K, V]) Invalidate(key K) bool {
             return nil
         })
 
-        if err == nil && success && c.invalid != nil {
-            c.invalid(key, oldVal)
+        if invalid := c.invalidHook(); err == nil && success && invalid != nil {
+            invalid(key, oldVal)
+        }
+
+        if c.fb != nil && err != nil && unavailable(err) {
+            // Buffer for when reachable.
+            c.buffer(rkey, oldVal, pendingWrite[K]{key: key})
+            success = true
         }
     }
 
-    return success
+    if c.fb != nil {
+        // Drop any local copy.
+        c.fb.local.Invalidate(key)
+    }
+
+    op.end(err, attrHit.Bool(success))
+
+    if success {
+        return nil
+    }
+    if err == nil {
+        err = getErr
+    }
+    return result(false, err)
 }
 
-func (c *Cache[K, V]) InvalidateAll(keys ...K) bool {
-    ctx := context.Background()
-    redisKeys := make([]string, len(keys))
-    oldValues := make(map[K]V)
+// result returns the error for an operation with given outcome: err mapped to its cache error (e.g. errs.ErrStopped
+// once closed) if set, otherwise errs.ErrNotFound if not ok.
+func result(ok bool, err error) error {
+    switch {
+    case err == redis.ErrClosed:
+        return errs.ErrStopped
+    case err != nil:
+        return err
+    case !ok:
+        return errs.ErrNotFound
+    }
+    return nil
+}
 
-    // Collect old values for invalidation callbacks
-    if c.invalid != nil {
-        for _, key := range keys {
-            if oldVal, exists := c.Get(key); exists {
-                oldValues[key] = oldVal
-            }
-        }
+func (c *Cache[K, V]) InvalidateAll(keys ...K) bool {
+    if len(keys) == 0 {
+        return false
     }
 
     // Format keys for Redis
-    for i, key := range keys {
-        redisKeys[i] = c.formatKey(key)
+    redisKeys, err := c.formatKeys(keys)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "InvalidateAll")
+        op.end(err)
+        return false
+    }
+
+    oldValues := make(map[K]V)
+    invalid := c.invalidHook()
+
+    ctx, op := c.beginWrite(context.Background(), "InvalidateAll", redisKeys...)
+
+    // Flush queued writes, so they
+    // are not applied after delete.
+    if err := c.flushKeys(ctx, redisKeys...); err != nil {
+        op.end(err)
+        return false
     }
 
     var deleted int64
-    err := c.withRetry(ctx, func(ctx context.Context) error {
-        result, err := c.pool.Client().Del(ctx, redisKeys...).Result()
+    c.dels.mark(redisKeys...)
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        var mget *redis.SliceCmd
+        var del *redis.IntCmd
+
+        // Fetch old values (only if needed for invalidation
+        // callbacks) and delete, atomically in one round trip.
+        _, err := c.pool.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            if invalid != nil {
+                mget = pipe.MGet(ctx, redisKeys...)
+            }
+            del = pipe.Del(ctx, redisKeys...)
+            return nil
+        })
         if err != nil {
             return err
         }
-        deleted = result
+
+        if mget != nil {
+            for i, val := range mget.Val() {
+                data, ok := val.(string)
+                if !ok {
+                    continue
+                }
+
+                var oldVal V
+                if err := c.unmarshal(redisKeys[i], []byte(data), &oldVal); err == nil {
+                    oldValues[keys[i]] = oldVal
+                }
+            }
+        }
+
+        deleted = del.Val()
         return nil
     })
 
+    op.end(err, attrHit.Bool(deleted > 0))
+
     // Call invalidation callbacks
-    if err == nil && deleted > 0 && c.invalid != nil {
+    if err == nil && deleted > 0 && invalid != nil {
         for key, oldVal := range oldValues {
-            c.invalid(key, oldVal)
+            invalid(key, oldVal)
+        }
+    }
+
+    if c.fb != nil {
+        if err != nil && unavailable(err) {
+            // Buffer for when reachable.
+            var zero V
+            for i, rkey := range redisKeys {
+                c.buffer(rkey, zero, pendingWrite[K]{key: keys[i]})
+            }
+            return true
+        }
+
+        // Drop any local copies.
+        for _, key := range keys {
+            c.fb.local.Invalidate(key)
         }
     }
 
@@ -235,10 +768,17 @@ func (c *Cache[K, V]) InvalidateAll(keys ...K) bool {
 }
 
 func (c *Cache[K, V]) Clear() {
-    ctx := context.Background()
+    ctx, op := c.beginWrite(context.Background(), "Clear")
+
+    // Flush queued writes, so they
+    // are not applied after clear.
+    if err := c.Flush(ctx); err != nil {
+        op.end(err)
+        return
+    }
 
     // If invalidation callback is set, we need to get all keys first
-    if c.invalid != nil {
+    if invalid := c.invalidHook(); invalid != nil {
         var cursor uint64
         for {
             var keys []string
@@ -251,9 +791,13 @@ func (c *Cache[K, V]) Clear() {
                 break
             }
 
-            for _, key := range keys {
-                if val, exists := c.Get(any(key).(K)); exists {
-                    c.invalid(any(key).(K), val)
+            for _, rkey := range c.dropLockKeys(keys) {
+                key, ok := c.parseKey(rkey)
+                if !ok {
+                    continue
+                }
+                if val, exists := c.Get(key); exists {
+                    invalid(key, val)
                 }
             }
 
@@ -263,13 +807,26 @@ func (c *Cache[K, V]) Clear() {
         }
     }
 
-    _ = c.withRetry(ctx, func(ctx context.Context) error {
+    err := c.withRetry(ctx, func(ctx context.Context) error {
         return c.pool.Client().FlushDB(ctx).Err()
     })
+
+    if c.fb != nil {
+        // Drop local copies,
+        // and buffered writes.
+        c.fb.local.Clear()
+        c.fb.mu.Lock()
+        c.fb.buffered = make(map[string]pendingWrite[K])
+        c.fb.mu.Unlock()
+    }
+
+    op.end(err)
 }
 
+// Len returns the number of keys in the database. Note this includes the lock keys of any GetOrLoad
+// calls loading at the time, as these are only excluded from scans.
 func (c *Cache[K, V]) Len() int {
-    ctx := context.Background()
+    ctx, op := c.begin(context.Background(), "Len", 0)
     var size int64
 
     err := c.withRetry(ctx, func(ctx context.Context) error {
@@ -281,6 +838,8 @@ func (c *Cache[K, V]) Len() int {
         return nil
     })
 
+    op.end(err)
+
     if err != nil {
         return 0
     }
@@ -293,16 +852,111 @@ func (c *Cache[K, V]) Cap() int {
 
 // Helper methods
 
-func (c *Cache[K, V]) formatKey(key K) string {
-    return fmt.Sprintf("%v", key)
+func (c *Cache[K, V]) jitter(ttl time.Duration) time.Duration {
+    if ttl <= 0 || c.opts.TTLJitter <= 0 {
+        return ttl
+    }
+
+    // Randomize within +/- jitter fraction of TTL.
+    delta := c.opts.TTLJitter * (2*rand.Float64() - 1)
+    if ttl += time.Duration(delta * float64(ttl)); ttl < time.Millisecond {
+        // Avoid rounding down to no expiry.
+        ttl = time.Millisecond
+    }
+    return ttl
+}
+
+func (c *Cache[K, V]) invalidHook() func(K, V) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.invalid
+}
+
+func (c *Cache[K, V]) keyEncoder() KeyEncoder[K] {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.keys
+}
+
+func (c *Cache[K, V]) evictHook() func(K, V) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.evict
+}
+
+func (c *Cache[K, V]) formatKey(key K) (string, error) {
+    return c.keyEncoder().EncodeKey(key)
+}
+
+// formatKeys formats each of keys for Redis, as formatKey.
+func (c *Cache[K, V]) formatKeys(keys []K) ([]string, error) {
+    rkeys := make([]string, len(keys))
+    for i, key := range keys {
+        rkey, err := c.formatKey(key)
+        if err != nil {
+            return nil, err
+        }
+        rkeys[i] = rkey
+    }
+    return rkeys, nil
+}
+
+func (c *Cache[K, V]) parseKey(s string) (K, bool) {
+    return c.keyEncoder().DecodeKey(s)
+}
+
+// execErr returns the error of a pipeline executed as (cmds, err), ignoring redis.Nil
+// results (e.g. missing keys) but no other failed command, as err is only the first.
+func execErr(cmds []redis.Cmder, err error) error {
+    if err != redis.Nil {
+        return err
+    }
+    for _, cmd := range cmds {
+        if err := cmd.Err(); err != nil && err != redis.Nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// mget fetches values for all keys using the fewest possible MGET commands in a single
+// pipeline, returning values in order of keys (nil for missing) as with a single MGET.
+func (c *Cache[K, V]) mget(ctx context.Context, client redis.Cmdable, keys []string) ([]interface{}, error) {
+    batches := c.batchKeys(keys)
+
+    pipe := client.Pipeline()
+    cmds := make([]*redis.SliceCmd, len(batches))
+    for b, batch := range batches {
+        args := make([]string, len(batch))
+        for i, idx := range batch {
+            args[i] = keys[idx]
+        }
+        cmds[b] = pipe.MGet(ctx, args...)
+    }
+
+    if _, err := pipe.Exec(ctx); err != nil {
+        return nil, err
+    }
+
+    vals := make([]interface{}, len(keys))
+    for b, cmd := range cmds {
+        for i, val := range cmd.Val() {
+            vals[batches[b][i]] = val
+        }
+    }
+
+    return vals, nil
 }
 
 // Transaction support
 
 func (c *Cache[K, V]) WithTx(ctx context.Context, fn func(*redis.Tx) error) error {
-    return c.withRetry(ctx, func(ctx context.Context) error {
+    ctx, op := c.begin(ctx, "WithTx", 0)
+    err := c.withRetry(ctx, func(ctx context.Context) error {
         return c.pool.Client().Watch(ctx, fn)
     })
+    op.end(err)
+    return err
 }
 
 // Batch operations
@@ -312,36 +966,46 @@ func (c *Cache[K, V]) MGet(keys ...K) map[K]V {
         return make(map[K]V)
     }
 
-    ctx := context.Background()
-    redisKeys := make([]string, len(keys))
-    for i, key := range keys {
-        redisKeys[i] = c.formatKey(key)
+    ctx, op := c.begin(context.Background(), "MGet", len(keys))
+    redisKeys, err := c.formatKeys(keys)
+    if err != nil {
+        op.end(err)
+        return make(map[K]V)
     }
 
+    // Apply queued writes first.
+    if err := c.flushKeys(ctx, redisKeys...); err != nil {
+        op.end(err)
+        return make(map[K]V)
+    }
+
+    var size int
     result := make(map[K]V)
-    err := c.withRetry(ctx, func(ctx context.Context) error {
-        pipe := c.pool.Client().Pipeline()
-        for _, key := range redisKeys {
-            pipe.Get(ctx, key)
-        }
 
-        cmds, err := pipe.Exec(ctx)
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        vals, err := c.mget(ctx, c.pool.Client(), redisKeys)
         if err != nil {
             return err
         }
 
-        for i, cmd := range cmds {
+        size = 0
+        for i, val := range vals {
+            data, ok := val.(string)
+            if !ok {
+                continue
+            }
+
             var value V
-            data, err := cmd.(*redis.StringCmd).Bytes()
-            if err == nil {
-                if err := json.Unmarshal(data, &value); err == nil {
-                    result[keys[i]] = value
-                }
+            size += len(data)
+            if err := c.unmarshal(redisKeys[i], []byte(data), &value); err == nil {
+                result[keys[i]] = value
             }
         }
         return nil
     })
 
+    op.end(err, attrHit.Bool(len(result) > 0), attrPayloadSize.Int(size))
+
     if err != nil {
         return make(map[K]V)
     }
@@ -349,24 +1013,163 @@ func (c *Cache[K, V]) MGet(keys ...K) map[K]V {
     return result
 }
 
+// MHas checks the cache for values with each of keys, in a single pipeline of EXISTS commands.
+func (c *Cache[K, V]) MHas(keys ...K) map[K]bool {
+    result := make(map[K]bool, len(keys))
+    if len(keys) == 0 {
+        return result
+    }
+
+    ctx, op := c.begin(context.Background(), "MHas", len(keys))
+    redisKeys, err := c.formatKeys(keys)
+    if err != nil {
+        op.end(err)
+        for _, key := range keys {
+            result[key] = false
+        }
+        return result
+    }
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        pipe := c.pool.Client().Pipeline()
+        cmds := make([]*redis.IntCmd, len(keys))
+        for i, rkey := range redisKeys {
+            cmds[i] = pipe.Exists(ctx, rkey)
+        }
+
+        if _, err := pipe.Exec(ctx); err != nil {
+            return err
+        }
+
+        for i, cmd := range cmds {
+            result[keys[i]] = cmd.Val() > 0
+        }
+        return nil
+    })
+
+    op.end(err)
+
+    if err != nil {
+        // Unknown, report all absent.
+        for _, key := range keys {
+            result[key] = false
+        }
+    }
+
+    return result
+}
+
 func (c *Cache[K, V]) MSet(items map[K]V) error {
     if len(items) == 0 {
         return nil
     }
 
-    ctx := context.Background()
-    return c.withRetry(ctx, func(ctx context.Context) error {
-        pipe := c.pool.Client().Pipeline()
+    var size int
+    keys := make([]K, 0, len(items))
+    redisKeys := make([]string, 0, len(items))
+    values := make([][]byte, 0, len(items))
 
-        for key, value := range items {
-            data, err := json.Marshal(value)
-            if err != nil {
-                return err
-            }
-            pipe.Set(ctx, c.formatKey(key), data, c.opts.DefaultTTL)
+    // Encode all keys and values upfront
+    for key, value := range items {
+        rkey, err := c.formatKey(key)
+        if err != nil {
+            return err
+        }
+        data, err := c.marshal(rkey, value)
+        if err != nil {
+            return err
+        }
+        size += len(data)
+        keys = append(keys, key)
+        redisKeys = append(redisKeys, rkey)
+        values = append(values, data)
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "MSet", redisKeys...)
+
+    // Apply queued writes first.
+    if err := c.flushKeys(ctx, redisKeys...); err != nil {
+        op.end(err)
+        return err
+    }
+
+    err := c.withRetry(ctx, func(ctx context.Context) error {
+        pipe := c.pool.Client().Pipeline()
+        for i, rkey := range redisKeys {
+            pipe.Set(ctx, rkey, values[i], c.jitter(c.opts.DefaultTTL))
         }
 
         _, err := pipe.Exec(ctx)
         return err
     })
+
+    op.end(err, attrPayloadSize.Int(size))
+
+    if c.fb != nil {
+        if err != nil && unavailable(err) {
+            // Buffer for when reachable.
+            for i, rkey := range redisKeys {
+                c.buffer(rkey, items[keys[i]], pendingWrite[K]{
+                    key:  keys[i],
+                    data: values[i],
+                    ttl:  c.opts.DefaultTTL,
+                })
+            }
+            return nil
+        } else if err == nil {
+            for _, key := range keys {
+                c.fb.local.Set(key, items[key])
+            }
+        }
+    }
+
+    return err
+}
+
+// Iteration
+
+// Range iterates over all keys matching pattern (in SCAN syntax, e.g. "*" for all keys), passing
+// each key and value to fn until it returns false. Keys modified during iteration may be skipped
+// or seen more than once, and keys that cannot be parsed or decoded are skipped.
+func (c *Cache[K, V]) Range(ctx context.Context, pattern string, fn func(K, V) bool) error {
+    ctx, op := c.begin(ctx, "Range", 0)
+
+    err := c.scanKeys(ctx, pattern, func(ctx context.Context, client redis.Cmdable, keys []string) (bool, error) {
+        var vals []interface{}
+
+        err := c.withRetry(ctx, func(ctx context.Context) error {
+            var err error
+            vals, err = c.mget(ctx, client, keys)
+            return err
+        })
+        if err != nil {
+            return false, err
+        }
+
+        for i := range keys {
+            data, ok := vals[i].(string)
+            if !ok {
+                continue
+            }
+
+            key, ok := c.parseKey(keys[i])
+            if !ok {
+                continue
+            }
+
+            var value V
+            if err := c.unmarshal(keys[i], []byte(data), &value); err != nil {
+                continue
+            }
+
+            if !fn(key, value) {
+                return false, nil
+            }
+        }
+
+        return true, nil
+    })
+
+    op.end(err)
+    return err
 }
@@ -0,0 +1,205 @@
+package redis_test
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    goredis "github.com/go-redis/redis/v8"
+    "github.com/mkc188/go-cache/v3/errs"
+    "github.com/mkc188/go-cache/v3/redis"
+)
+
+func TestGetMiss(t *testing.T) {
+    c := redis.New[string, int](testOptions(t))
+    defer c.Close()
+    c.Clear()
+
+    if _, ok := c.Get("missing"); ok {
+        t.Fatal("Get reported hit for missing key")
+    }
+    if _, err := c.GetE("missing"); !errors.Is(err, errs.ErrNotFound) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    c.Set("present", 1)
+    if v, ok := c.Get("present"); !ok || v != 1 {
+        t.Fatalf("unexpected result: %v %v", v, ok)
+    }
+}
+
+func TestCloseTwice(t *testing.T) {
+    opts := redis.DefaultOptions()
+    opts.WriteBehindInterval = time.Hour
+    opts.FallbackSize = 16
+
+    c := redis.New[string, int](opts)
+    if err := c.Close(); err != nil {
+        t.Fatal(err)
+    }
+
+    // Further closes are no-ops.
+    if err := c.CloseContext(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if err := c.Pool().Close(); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestCAS(t *testing.T) {
+    mr, opts := miniOptions(t)
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    var invalidated []int
+    c.SetInvalidateCallback(func(_ string, v int) {
+        invalidated = append(invalidated, v)
+    })
+
+    eq := func(a, b int) bool { return a == b }
+
+    if c.CAS("a", 0, 1, eq) {
+        t.Fatal("CAS succeeded on missing key")
+    }
+
+    c.Set("a", 1)
+    invalidated = nil
+    if !c.CAS("a", 1, 2, eq) {
+        t.Fatal("CAS failed")
+    }
+    if c.CAS("a", 1, 3, eq) {
+        t.Fatal("CAS succeeded with stale old value")
+    }
+    if v, ok := c.Get("a"); !ok || v != 2 {
+        t.Fatalf("unexpected value: %d %v", v, ok)
+    }
+    if len(invalidated) != 1 || invalidated[0] != 1 {
+        t.Fatalf("unexpected invalidations: %v", invalidated)
+    }
+
+    // The swap keeps the default TTL.
+    if ttl := mr.TTL("a"); ttl <= 0 || ttl > opts.DefaultTTL {
+        t.Fatalf("unexpected ttl: %v", ttl)
+    }
+}
+
+func TestGetSet(t *testing.T) {
+    _, opts := miniOptions(t)
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    if old, ok := c.GetSet("a", 1); ok {
+        t.Fatalf("GetSet returned old value of missing key: %d", old)
+    }
+    if old, ok := c.GetSet("a", 2); !ok || old != 1 {
+        t.Fatalf("unexpected old value: %d %v", old, ok)
+    }
+    if old := c.Swap("a", 3); old != 2 {
+        t.Fatalf("unexpected swapped value: %d", old)
+    }
+    if v, ok := c.Get("a"); !ok || v != 3 {
+        t.Fatalf("unexpected value: %d %v", v, ok)
+    }
+}
+
+func TestTouchExtend(t *testing.T) {
+    mr, opts := miniOptions(t)
+    opts.DefaultTTL = time.Minute
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    if c.Touch("a") || c.Extend("a", time.Minute) {
+        t.Fatal("touched missing key")
+    }
+
+    c.Set("a", 1)
+    mr.FastForward(time.Second * 30)
+
+    // Touch resets to the default TTL.
+    if !c.Touch("a") {
+        t.Fatal("Touch failed")
+    }
+    if ttl, ok := c.GetTTL("a"); !ok || ttl != time.Minute {
+        t.Fatalf("unexpected ttl after touch: %v %v", ttl, ok)
+    }
+
+    // Extend adds to the remaining TTL.
+    if !c.Extend("a", time.Minute) {
+        t.Fatal("Extend failed")
+    }
+    if ttl, ok := c.GetTTL("a"); !ok || ttl != time.Minute*2 {
+        t.Fatalf("unexpected ttl after extend: %v %v", ttl, ok)
+    }
+
+    // Keys without expiry are left so.
+    mr.Set("b", "2")
+    if !c.Extend("b", time.Minute) {
+        t.Fatal("Extend failed")
+    }
+    if ttl := mr.TTL("b"); ttl != 0 {
+        t.Fatalf("unexpected ttl of persistent key: %v", ttl)
+    }
+}
+
+func TestCloseContext(t *testing.T) {
+    _, opts := miniOptions(t)
+
+    c := redis.New[string, int](opts)
+
+    // Hold an operation in-flight.
+    started := make(chan struct{})
+    release := make(chan struct{})
+    go func() {
+        _ = c.WithTx(context.Background(), func(*goredis.Tx) error {
+            close(started)
+            <-release
+            return nil
+        })
+    }()
+    <-started
+
+    // Close waits on it, or ctx.
+    ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+    defer cancel()
+    if err := c.CloseContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    close(release)
+
+    // New operations are rejected.
+    if err := c.SetE("a", 1); !errors.Is(err, errs.ErrStopped) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+}
+
+func TestCloseDrains(t *testing.T) {
+    _, opts := miniOptions(t)
+
+    c := redis.New[string, int](opts)
+
+    started := make(chan struct{})
+    done := make(chan struct{})
+    go func() {
+        _ = c.WithTx(context.Background(), func(*goredis.Tx) error {
+            close(started)
+            time.Sleep(time.Millisecond * 50)
+            close(done)
+            return nil
+        })
+    }()
+    <-started
+
+    if err := c.Close(); err != nil {
+        t.Fatal(err)
+    }
+    select {
+    case <-done:
+    default:
+        t.Fatal("closed before in-flight operation finished")
+    }
+}
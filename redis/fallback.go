@@ -0,0 +1,124 @@
+package redis
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/mkc188/go-cache/v3/ttl"
+)
+
+// fallback is a bounded local cache used while Redis is unreachable, serving (possibly stale)
+// values and buffering writes, which are replayed to Redis once it is reachable again.
+type fallback[K comparable, V any] struct {
+    local    *ttl.Cache[K, V]
+    buffered map[string]pendingWrite[K]
+    stopCh   chan struct{}
+    doneCh   chan struct{}
+    mu       sync.Mutex
+}
+
+// startFallback initializes the local fallback cache, and starts the routine replaying buffered writes.
+func (c *Cache[K, V]) startFallback() {
+    local := ttl.New[K, V](0, c.opts.FallbackSize, c.opts.FallbackTTL)
+    local.Start(local.TTL / 2)
+
+    c.fb = &fallback[K, V]{
+        local:    local,
+        buffered: make(map[string]pendingWrite[K]),
+        stopCh:   make(chan struct{}),
+        doneCh:   make(chan struct{}),
+    }
+
+    go func() {
+        ticker := time.NewTicker(c.pool.health.interval)
+        defer ticker.Stop()
+        defer close(c.fb.doneCh)
+
+        for {
+            select {
+            case <-c.fb.stopCh:
+                return
+            case <-ticker.C:
+                if err := c.replay(context.Background()); err != nil {
+                    c.opts.logger().Warn("redis: fallback replay failed", "err", err)
+                }
+            }
+        }
+    }()
+}
+
+// stopFallback stops the fallback replay routine and local cache, making a final replay attempt.
+func (c *Cache[K, V]) stopFallback() {
+    if c.fb == nil {
+        return
+    }
+    close(c.fb.stopCh)
+    <-c.fb.doneCh
+    if err := c.replay(context.Background()); err != nil {
+        c.opts.logger().Error("redis: final fallback replay failed", "err", err)
+    }
+    c.fb.local.Stop()
+}
+
+// unavailable returns whether err indicates Redis is unreachable.
+func unavailable(err error) bool {
+    return err == ErrCircuitOpen || isRetryableError(err)
+}
+
+// buffer stores a write locally and buffers it for replay to Redis.
+func (c *Cache[K, V]) buffer(rkey string, value V, w pendingWrite[K]) {
+    if w.data == nil {
+        c.fb.local.Invalidate(w.key)
+    } else {
+        c.fb.local.Set(w.key, value)
+    }
+
+    c.fb.mu.Lock()
+    c.fb.buffered[rkey] = w
+    c.fb.mu.Unlock()
+}
+
+// replay writes all buffered writes to Redis in a single pipeline, requeueing them on failure.
+// (NOTE: not registered in-flight, only called by the replay routine and when stopped)
+func (c *Cache[K, V]) replay(ctx context.Context) error {
+    c.fb.mu.Lock()
+    buffered := c.fb.buffered
+    c.fb.buffered = make(map[string]pendingWrite[K], len(buffered))
+    c.fb.mu.Unlock()
+
+    if len(buffered) == 0 {
+        return nil
+    }
+
+    err := c.retry(ctx, func(ctx context.Context) error {
+        pipe := c.pool.Client().Pipeline()
+        for rkey, w := range buffered {
+            if w.data == nil {
+                pipe.Del(ctx, rkey)
+            } else {
+                pipe.Set(ctx, rkey, w.data, c.jitter(w.ttl))
+            }
+        }
+        _, err := pipe.Exec(ctx)
+        if err == redis.Nil {
+            err = nil
+        }
+        return err
+    })
+
+    if err != nil {
+        // Requeue failed writes,
+        // unless since replaced.
+        c.fb.mu.Lock()
+        for rkey, w := range buffered {
+            if _, ok := c.fb.buffered[rkey]; !ok {
+                c.fb.buffered[rkey] = w
+            }
+        }
+        c.fb.mu.Unlock()
+    }
+
+    return err
+}
@@ -0,0 +1,60 @@
+package redis_test
+
+import (
+    "net"
+    "testing"
+    "time"
+
+    "github.com/mkc188/go-cache/v3/redis"
+)
+
+// unreachableOptions returns options for an address refusing connections, without retries.
+func unreachableOptions(t *testing.T) *redis.Options {
+    l, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    addr := l.Addr().String()
+    l.Close()
+
+    opts := redis.DefaultOptions()
+    opts.Addresses = []string{addr}
+    opts.MinIdleConns = 0
+    opts.MaxRetries = 0
+    return opts
+}
+
+func TestFallbackUnreachable(t *testing.T) {
+    opts := unreachableOptions(t)
+    opts.FallbackSize = 16
+    opts.FallbackTTL = time.Minute
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    // Writes are applied locally.
+    if err := c.SetE("a", 1); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if err := c.MSet(map[string]int{"b": 2, "c": 3}); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    for key, want := range map[string]int{"a": 1, "b": 2, "c": 3} {
+        if v, ok := c.Get(key); !ok || v != want {
+            t.Fatalf("unexpected fallback value of %s: %d %v", key, v, ok)
+        }
+    }
+
+    // As are invalidations.
+    if !c.InvalidateAll("a", "b") {
+        t.Fatal("InvalidateAll failed")
+    }
+    if _, ok := c.Get("a"); ok {
+        t.Fatal("invalidated key served from fallback")
+    }
+
+    c.Clear()
+    if _, ok := c.Get("c"); ok {
+        t.Fatal("cleared key served from fallback")
+    }
+}
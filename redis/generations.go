@@ -0,0 +1,52 @@
+package redis
+
+import (
+    "context"
+    "strconv"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// Generations stores namespace generation counters in Redis, shared by all processes using the same keys,
+// implementing namespace.Generations. Each namespace's counter is stored at its prefixed name.
+type Generations struct {
+    pool   *Pool
+    prefix string
+}
+
+// NewGenerations returns a new Generations storing counters at keys with prefix, using pool.
+func NewGenerations(pool *Pool, prefix string) *Generations {
+    return &Generations{pool: pool, prefix: prefix}
+}
+
+// Generation returns the current generation of namespace.
+func (g *Generations) Generation(ns string) (uint64, error) {
+    ctx, cancel := g.context()
+    defer cancel()
+
+    s, err := g.pool.Client().Get(ctx, g.prefix+ns).Result()
+    if err == redis.Nil {
+        return 0, nil
+    } else if err != nil {
+        return 0, err
+    }
+
+    return strconv.ParseUint(s, 10, 64)
+}
+
+// Bump increments the generation of namespace, returning the new generation.
+func (g *Generations) Bump(ns string) (uint64, error) {
+    ctx, cancel := g.context()
+    defer cancel()
+
+    gen, err := g.pool.Client().Incr(ctx, g.prefix+ns).Result()
+    return uint64(gen), err
+}
+
+// context returns a context bounded by the pool's OpTimeout, if any.
+func (g *Generations) context() (context.Context, context.CancelFunc) {
+    if g.pool.opts.OpTimeout > 0 {
+        return context.WithTimeout(context.Background(), g.pool.opts.OpTimeout)
+    }
+    return context.WithCancel(context.Background())
+}
@@ -0,0 +1,241 @@
+package redis
+
+import (
+    "context"
+    "reflect"
+    "strings"
+    "sync"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// hashField describes a struct field stored as a Redis hash field.
+type hashField struct {
+    name  string
+    index []int
+}
+
+// hashFields caches struct types to their hash fields.
+var hashFields sync.Map // map[reflect.Type][]hashField
+
+// fieldsOf returns the hash fields of struct type t, i.e. its exported (and promoted) fields,
+// named by their `redis:"name"` tag if set, and skipping fields tagged `redis:"-"`.
+func fieldsOf(t reflect.Type) []hashField {
+    if v, ok := hashFields.Load(t); ok {
+        return v.([]hashField)
+    }
+
+    var fields []hashField
+    for _, f := range reflect.VisibleFields(t) {
+        if !f.IsExported() || f.Anonymous {
+            continue
+        }
+
+        if viaPointer(t, f.Index) {
+            // Skip fields promoted through
+            // embedded pointers, may be nil.
+            continue
+        }
+
+        name := f.Name
+        if tag, ok := f.Tag.Lookup("redis"); ok {
+            tag, _, _ = strings.Cut(tag, ",")
+            if tag == "-" {
+                continue
+            } else if tag != "" {
+                name = tag
+            }
+        }
+
+        fields = append(fields, hashField{name: name, index: f.Index})
+    }
+
+    hashFields.Store(t, fields)
+    return fields
+}
+
+// viaPointer returns whether field at index in struct type t is promoted through an embedded pointer.
+func viaPointer(t reflect.Type, index []int) bool {
+    for i := 1; i < len(index); i++ {
+        if t.FieldByIndex(index[:i]).Type.Kind() == reflect.Pointer {
+            return true
+        }
+    }
+    return false
+}
+
+// structOf returns the addressable struct value pointed to (perhaps indirectly) by
+// ptr, allocating any nil pointers on the way, or false if not a struct.
+func structOf(ptr any) (reflect.Value, bool) {
+    v := reflect.ValueOf(ptr).Elem()
+    for v.Kind() == reflect.Pointer {
+        if v.IsNil() {
+            v.Set(reflect.New(v.Type().Elem()))
+        }
+        v = v.Elem()
+    }
+    return v, v.Kind() == reflect.Struct
+}
+
+// SetHash places the struct value at key in the cache stored as a Redis hash, with each exported
+// field (named by its `redis:"name"` tag, if any) stored as a separately serialized hash field.
+// Values stored this way should be fetched with GetHash or GetFields, and not Get.
+func (c *Cache[K, V]) SetHash(key K, value V) error {
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "SetHash")
+        op.end(err)
+        return err
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "SetHash", rkey)
+
+    v, ok := structOf(&value)
+    if !ok {
+        op.end(ErrUnsupportedValue)
+        return ErrUnsupportedValue
+    }
+
+    var size int
+    fields := fieldsOf(v.Type())
+    values := make([]interface{}, 0, 2*len(fields))
+    for _, f := range fields {
+        data, err := c.serializer(rkey).Marshal(v.FieldByIndex(f.index).Interface())
+        if err != nil {
+            op.end(err)
+            return err
+        }
+        size += len(data)
+        values = append(values, f.name, data)
+    }
+
+    c.dels.mark(rkey)
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        // Replace the entire hash with its TTL, atomically.
+        _, err := c.pool.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            pipe.Del(ctx, rkey)
+            if len(values) > 0 {
+                pipe.HSet(ctx, rkey, values...)
+            }
+            if c.opts.DefaultTTL > 0 {
+                pipe.PExpire(ctx, rkey, c.jitter(c.opts.DefaultTTL))
+            }
+            return nil
+        })
+        return err
+    })
+
+    op.end(err, attrPayloadSize.Int(size))
+    return err
+}
+
+// SetFields updates only the given fields (by hash field name) of the struct value stored at key with SetHash.
+func (c *Cache[K, V]) SetFields(key K, fields map[string]any) error {
+    if len(fields) == 0 {
+        return nil
+    }
+
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        _, op := c.beginWrite(context.Background(), "SetFields")
+        op.end(err)
+        return err
+    }
+
+    ctx, op := c.beginWrite(context.Background(), "SetFields", rkey)
+
+    var size int
+    values := make([]interface{}, 0, 2*len(fields))
+    for name, field := range fields {
+        data, err := c.serializer(rkey).Marshal(field)
+        if err != nil {
+            op.end(err)
+            return err
+        }
+        size += len(data)
+        values = append(values, name, data)
+    }
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        return c.pool.Client().HSet(ctx, rkey, values...).Err()
+    })
+
+    op.end(err, attrPayloadSize.Int(size))
+    return err
+}
+
+// GetHash fetches the struct value stored at key with SetHash.
+func (c *Cache[K, V]) GetHash(key K) (V, bool) {
+    return c.getFields("GetHash", key, nil)
+}
+
+// GetFields fetches only the given fields (by hash field name) of the struct value stored at key with SetHash,
+// leaving all other fields of the returned value zeroed. No fields given is equivalent to GetHash.
+func (c *Cache[K, V]) GetFields(key K, fields ...string) (V, bool) {
+    return c.getFields("GetFields", key, fields)
+}
+
+// getFields fetches given fields (or all if none) of struct value stored as a hash at key.
+func (c *Cache[K, V]) getFields(name string, key K, fields []string) (V, bool) {
+    var value V
+
+    ctx, op := c.begin(context.Background(), name, 1)
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        op.end(err)
+        return value, false
+    }
+
+    v, ok := structOf(&value)
+    if !ok {
+        op.end(ErrUnsupportedValue)
+        return value, false
+    }
+
+    var hit bool
+    var size int
+
+    err = c.withRetry(ctx, func(ctx context.Context) error {
+        stored := make(map[string]string, len(fields))
+
+        if len(fields) == 0 {
+            result, err := c.pool.Client().HGetAll(ctx, rkey).Result()
+            if err != nil {
+                return err
+            }
+            stored = result
+        } else {
+            result, err := c.pool.Client().HMGet(ctx, rkey, fields...).Result()
+            if err != nil {
+                return err
+            }
+            for i, val := range result {
+                if data, ok := val.(string); ok {
+                    stored[fields[i]] = data
+                }
+            }
+        }
+
+        hit = len(stored) > 0
+        size = 0
+
+        for _, f := range fieldsOf(v.Type()) {
+            data, ok := stored[f.name]
+            if !ok {
+                continue
+            }
+
+            size += len(data)
+            ptr := v.FieldByIndex(f.index).Addr().Interface()
+            if err := c.serializer(rkey).Unmarshal([]byte(data), ptr); err != nil {
+                return err
+            }
+        }
+
+        return nil
+    })
+
+    op.end(err, attrHit.Bool(hit), attrPayloadSize.Int(size))
+
+    return value, err == nil && hit
+}
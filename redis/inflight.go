@@ -0,0 +1,58 @@
+package redis
+
+import (
+    "context"
+    "sync"
+)
+
+// inflight tracks in-flight operations, allowing a close to wait on them.
+type inflight struct {
+    count   int
+    closing bool
+    idle    chan struct{}
+    mu      sync.Mutex
+}
+
+// acquire registers a new in-flight operation, returning false if closing.
+func (i *inflight) acquire() bool {
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    if i.closing {
+        return false
+    }
+    i.count++
+    return true
+}
+
+// release unregisters an in-flight operation.
+func (i *inflight) release() {
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    i.count--
+    if i.count == 0 && i.idle != nil {
+        close(i.idle)
+        i.idle = nil
+    }
+}
+
+// close rejects further operations, and waits until there are none in-flight or ctx expires.
+func (i *inflight) close(ctx context.Context) error {
+    i.mu.Lock()
+    i.closing = true
+    if i.count == 0 {
+        i.mu.Unlock()
+        return nil
+    }
+    if i.idle == nil {
+        i.idle = make(chan struct{})
+    }
+    idle := i.idle
+    i.mu.Unlock()
+
+    select {
+    case <-idle:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
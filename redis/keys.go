@@ -0,0 +1,180 @@
+package redis
+
+import (
+    "encoding"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strings"
+)
+
+// ErrUnsupportedKey is returned by a KeyEncoder given a key it cannot unambiguously encode.
+var ErrUnsupportedKey = errors.New("redis: unsupported key type for key encoder")
+
+// KeyEncoder encodes cache keys to Redis keys, and decodes them back. Encoding must be
+// unambiguous, i.e. no two distinct keys may encode to the same Redis key.
+type KeyEncoder[K comparable] interface {
+    // EncodeKey encodes key as a Redis key, returning an error (failing the
+    // cache operation) if key cannot be encoded.
+    EncodeKey(key K) (string, error)
+
+    // DecodeKey decodes a Redis key back to key, returning false if not possible.
+    DecodeKey(s string) (K, bool)
+}
+
+// DefaultKeyEncoder returns the default KeyEncoder, which uses string keys as-is and encodes
+// all other key types as JSON, so keys of different types or structure cannot collide. Key
+// types that JSON cannot encode unambiguously (pointers, interfaces, channels, funcs, complex
+// numbers, and structs with unexported or omitted fields, unless implementing json.Marshaler
+// or encoding.TextMarshaler) are rejected, failing every operation with ErrUnsupportedKey,
+// such keys should use a custom KeyEncoder.
+//
+// Note that before KeyEncoder, keys were formatted with fmt.Sprint(key). The Redis keys of
+// string keys are unchanged, those of any other key type differ, so existing entries are not
+// found. To keep them, set a KeyEncoder formatting keys as before.
+func DefaultKeyEncoder[K comparable]() KeyEncoder[K] {
+    t := reflect.TypeOf((*K)(nil)).Elem()
+    if t.Kind() == reflect.String {
+        return stringKeyEncoder[K]{}
+    }
+    if err := checkKeyType(t); err != nil {
+        return invalidKeyEncoder[K]{err: err}
+    }
+    return jsonKeyEncoder[K]{}
+}
+
+var (
+    jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+    textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// checkKeyType returns ErrUnsupportedKey (wrapped) if keys of type t cannot be unambiguously encoded as JSON.
+func checkKeyType(t reflect.Type) error {
+    if t.Implements(jsonMarshaler) || t.Implements(textMarshaler) {
+        // Encodes itself.
+        return nil
+    }
+
+    switch t.Kind() {
+    case reflect.Bool, reflect.String,
+        reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+        reflect.Float32, reflect.Float64:
+        return nil
+
+    case reflect.Array:
+        return checkKeyType(t.Elem())
+
+    case reflect.Struct:
+        for i := 0; i < t.NumField(); i++ {
+            f := t.Field(i)
+            if !f.IsExported() && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+                return fmt.Errorf("%w: %s has unexported field %s", ErrUnsupportedKey, t, f.Name)
+            }
+            if f.Tag.Get("json") == "-" {
+                return fmt.Errorf("%w: %s has omitted field %s", ErrUnsupportedKey, t, f.Name)
+            }
+            if err := checkKeyType(f.Type); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    // i.e. pointers (encoded by pointee, so
+    // distinct keys collide), interfaces, chans,
+    // funcs and complex numbers.
+    return fmt.Errorf("%w: %s", ErrUnsupportedKey, t)
+}
+
+// stringKeyEncoder is a KeyEncoder for string kinded keys.
+type stringKeyEncoder[K comparable] struct{}
+
+func (stringKeyEncoder[K]) EncodeKey(key K) (string, error) {
+    if s, ok := any(key).(string); ok {
+        return s, nil
+    }
+    return reflect.ValueOf(key).String(), nil
+}
+
+func (stringKeyEncoder[K]) DecodeKey(s string) (K, bool) {
+    var key K
+    if k, ok := any(&key).(*string); ok {
+        *k = s
+        return key, true
+    }
+    reflect.ValueOf(&key).Elem().SetString(s)
+    return key, true
+}
+
+// jsonKeyEncoder is a KeyEncoder using JSON encoding.
+type jsonKeyEncoder[K comparable] struct{}
+
+func (jsonKeyEncoder[K]) EncodeKey(key K) (string, error) {
+    b, err := json.Marshal(key)
+    if err != nil {
+        // e.g. NaN floats.
+        return "", fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+    }
+    return string(b), nil
+}
+
+func (jsonKeyEncoder[K]) DecodeKey(s string) (K, bool) {
+    var key K
+    err := json.Unmarshal([]byte(s), &key)
+    return key, err == nil
+}
+
+// invalidKeyEncoder is a KeyEncoder for unsupported key types, failing with err.
+type invalidKeyEncoder[K comparable] struct{ err error }
+
+func (e invalidKeyEncoder[K]) EncodeKey(K) (string, error) {
+    return "", e.err
+}
+
+func (invalidKeyEncoder[K]) DecodeKey(string) (K, bool) {
+    var zero K
+    return zero, false
+}
+
+// HashTagKeyEncoder wraps a KeyEncoder, prefixing each encoded key with the portion returned by tag wrapped
+// in a {hash tag}. Redis cluster only hashes the hash tag to determine a key's slot, so keys sharing a tag
+// (e.g. an entity and its secondary-index keys) land in the same slot, allowing multi-key operations on them.
+// The tag must not contain '}'.
+func HashTagKeyEncoder[K comparable](enc KeyEncoder[K], tag func(K) string) KeyEncoder[K] {
+    if enc == nil {
+        enc = DefaultKeyEncoder[K]()
+    }
+    return hashTagKeyEncoder[K]{enc: enc, tag: tag}
+}
+
+// hashTagKeyEncoder is a KeyEncoder prefixing keys with a {hash tag}.
+type hashTagKeyEncoder[K comparable] struct {
+    enc KeyEncoder[K]
+    tag func(K) string
+}
+
+func (e hashTagKeyEncoder[K]) EncodeKey(key K) (string, error) {
+    s, err := e.enc.EncodeKey(key)
+    if err != nil {
+        return "", err
+    }
+    return "{" + e.tag(key) + "}" + s, nil
+}
+
+func (e hashTagKeyEncoder[K]) DecodeKey(s string) (K, bool) {
+    if !strings.HasPrefix(s, "{") {
+        var zero K
+        return zero, false
+    }
+
+    // Strip the hash tag prefix.
+    end := strings.IndexByte(s, '}')
+    if end < 0 {
+        var zero K
+        return zero, false
+    }
+
+    return e.enc.DecodeKey(s[end+1:])
+}
@@ -0,0 +1,65 @@
+package redis_test
+
+import (
+    "errors"
+    "math"
+    "testing"
+    "time"
+
+    "github.com/mkc188/go-cache/v3/redis"
+)
+
+func TestDefaultKeyEncoder(t *testing.T) {
+    type point struct{ X, Y int }
+
+    // Supported keys round trip.
+    enc := redis.DefaultKeyEncoder[point]()
+    s, err := enc.EncodeKey(point{1, 2})
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if key, ok := enc.DecodeKey(s); !ok || key != (point{1, 2}) {
+        t.Fatalf("unexpected decoded key: %v", key)
+    }
+
+    // Self-encoding keys are supported.
+    if _, err := redis.DefaultKeyEncoder[time.Time]().EncodeKey(time.Now()); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    // Unencodable keys fail, not panic.
+    if _, err := redis.DefaultKeyEncoder[float64]().EncodeKey(math.NaN()); !errors.Is(err, redis.ErrUnsupportedKey) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    // Ambiguous key types are rejected.
+    type hidden struct{ x int }
+    check := func(name string, err error) {
+        if !errors.Is(err, redis.ErrUnsupportedKey) {
+            t.Errorf("%s: unexpected error: %v", name, err)
+        }
+    }
+    _, err = redis.DefaultKeyEncoder[*int]().EncodeKey(new(int))
+    check("pointer", err)
+    _, err = redis.DefaultKeyEncoder[complex128]().EncodeKey(1i)
+    check("complex", err)
+    _, err = redis.DefaultKeyEncoder[chan int]().EncodeKey(nil)
+    check("chan", err)
+    _, err = redis.DefaultKeyEncoder[any]().EncodeKey(1)
+    check("interface", err)
+    _, err = redis.DefaultKeyEncoder[hidden]().EncodeKey(hidden{1})
+    check("unexported field", err)
+}
+
+func TestUnsupportedKey(t *testing.T) {
+    c := redis.New[*int, int](redis.DefaultOptions())
+    defer c.Close()
+
+    // Fails the operation, without contacting Redis.
+    if err := c.SetE(new(int), 1); !errors.Is(err, redis.ErrUnsupportedKey) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if _, err := c.GetE(new(int)); !errors.Is(err, redis.ErrUnsupportedKey) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+}
@@ -0,0 +1,132 @@
+package redis
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "strings"
+    "time"
+)
+
+// defaultLoadLockPrefix is the default prefix of GetOrLoad lock keys.
+const defaultLoadLockPrefix = "go-cache:lock:"
+
+// GetOrLoad fetches the value with key from the cache, or on miss loads it using loader and stores it. Only one
+// caller cluster-wide runs the loader for a key at any one time, by holding a short-lived lock key in Redis, while
+// other callers poll for the stored value (or for the lock to be released, to try loading themselves). Concurrent
+// callers within this process are first coalesced, such that only one of them contends for the lock.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(context.Context) (V, error)) (V, error) {
+    if value, ok := c.Get(key); ok {
+        return value, nil
+    }
+
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        var zero V
+        return zero, err
+    }
+
+    value, err, _ := c.loads.DoContext(ctx, rkey, func(ctx context.Context) (V, error) {
+        return c.getOrLoad(ctx, key, loader)
+    })
+    return value, err
+}
+
+// getOrLoad performs GetOrLoad, contending for the cluster-wide lock on key.
+func (c *Cache[K, V]) getOrLoad(ctx context.Context, key K, loader func(context.Context) (V, error)) (V, error) {
+    lockTTL := c.opts.LoadLockTTL
+    if lockTTL <= 0 {
+        // Default duration
+        lockTTL = time.Second * 10
+    }
+
+    poll := c.opts.LoadPollInterval
+    if poll <= 0 {
+        // Default duration
+        poll = time.Millisecond * 50
+    }
+
+    rkey, err := c.formatKey(key)
+    if err != nil {
+        var zero V
+        return zero, err
+    }
+
+    lockKey := c.lockPrefix() + rkey
+    token := newLockToken()
+
+    for {
+        var locked bool
+
+        err := c.withRetry(ctx, func(ctx context.Context) error {
+            var err error
+            locked, err = c.pool.Client().SetNX(ctx, lockKey, token, lockTTL).Result()
+            return err
+        })
+        if err != nil {
+            var zero V
+            return zero, err
+        }
+
+        if locked {
+            return c.loadLocked(ctx, key, lockKey, token, loader)
+        }
+
+        // Wait for loading caller.
+        select {
+        case <-ctx.Done():
+            var zero V
+            return zero, ctx.Err()
+        case <-time.After(poll):
+        }
+
+        if value, ok := c.Get(key); ok {
+            return value, nil
+        }
+    }
+}
+
+// loadLocked loads and stores the value for key while holding the lock at lockKey with token, releasing it after.
+func (c *Cache[K, V]) loadLocked(ctx context.Context, key K, lockKey, token string, loader func(context.Context) (V, error)) (V, error) {
+    defer func() {
+        // Release lock, regardless of context state.
+        ctx := context.Background()
+        _ = c.withRetry(ctx, func(ctx context.Context) error {
+            return unlockScript.Run(ctx, c.pool.Client(), []string{lockKey}, token).Err()
+        })
+    }()
+
+    // Check again, another caller may have
+    // stored it before we acquired the lock.
+    if value, ok := c.Get(key); ok {
+        return value, nil
+    }
+
+    value, err := loader(ctx)
+    if err != nil {
+        return value, err
+    }
+
+    c.Set(key, value)
+    return value, nil
+}
+
+// lockPrefix returns the configured prefix of GetOrLoad lock keys, or the default if unset.
+func (c *Cache[K, V]) lockPrefix() string {
+    if c.opts.LoadLockPrefix == "" {
+        return defaultLoadLockPrefix
+    }
+    return c.opts.LoadLockPrefix
+}
+
+// isLockKey returns whether Redis key rkey is a GetOrLoad lock key, rather than a cache entry.
+func (c *Cache[K, V]) isLockKey(rkey string) bool {
+    return strings.HasPrefix(rkey, c.lockPrefix())
+}
+
+// newLockToken returns a new random token identifying a lock holder.
+func newLockToken() string {
+    var b [16]byte
+    _, _ = rand.Read(b[:])
+    return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,105 @@
+package redis_test
+
+import (
+    "context"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/mkc188/go-cache/v3/redis"
+)
+
+func TestGetOrLoad(t *testing.T) {
+    mr, opts := miniOptions(t)
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    // Concurrent callers load once.
+    var loads int32
+    var wg sync.WaitGroup
+    for i := 0; i < 10; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            v, err := c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) {
+                atomic.AddInt32(&loads, 1)
+                time.Sleep(time.Millisecond * 20)
+                return 1, nil
+            })
+            if err != nil || v != 1 {
+                t.Errorf("unexpected result: %d %v", v, err)
+            }
+        }()
+    }
+    wg.Wait()
+
+    if n := atomic.LoadInt32(&loads); n != 1 {
+        t.Fatalf("loaded %d times", n)
+    }
+
+    // Lock is released.
+    if keys := mr.Keys(); len(keys) != 1 || keys[0] != "a" {
+        t.Fatalf("unexpected keys: %v", keys)
+    }
+}
+
+func TestGetOrLoadLocked(t *testing.T) {
+    mr, opts := miniOptions(t)
+    opts.LoadLockPrefix = "lock:"
+    opts.LoadPollInterval = time.Millisecond * 10
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    // Held by another client,
+    // which stores the value.
+    mr.Set("lock:a", "other")
+    go func() {
+        time.Sleep(time.Millisecond * 50)
+        mr.Set("a", "2")
+        mr.Del("lock:a")
+    }()
+
+    v, err := c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) {
+        t.Error("loaded while locked")
+        return 1, nil
+    })
+    if err != nil || v != 2 {
+        t.Fatalf("unexpected result: %d %v", v, err)
+    }
+
+    // Lock keys are not entries.
+    mr.Set("lock:b", "other")
+    var keys []string
+    if err := c.Range(context.Background(), "*", func(key string, _ int) bool {
+        keys = append(keys, key)
+        return true
+    }); err != nil {
+        t.Fatal(err)
+    }
+    if len(keys) != 1 || keys[0] != "a" {
+        t.Fatalf("unexpected range keys: %v", keys)
+    }
+}
+
+func TestGetOrLoadCanceled(t *testing.T) {
+    mr, opts := miniOptions(t)
+    opts.LoadPollInterval = time.Millisecond * 10
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    // Held by another client, which never stores.
+    mr.Set("go-cache:lock:a", "other")
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+    defer cancel()
+
+    if _, err := c.GetOrLoad(ctx, "a", func(context.Context) (int, error) {
+        return 1, nil
+    }); err != context.DeadlineExceeded {
+        t.Fatalf("unexpected error: %v", err)
+    }
+}
@@ -0,0 +1,162 @@
+package redis
+
+import (
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a Prometheus collector for redis cache operation latencies, errors and
+// retries, and statistics of every Pool created with it set in Options.Metrics.
+type Metrics struct {
+    latency *prometheus.HistogramVec
+    errors  *prometheus.CounterVec
+    retries prometheus.Counter
+
+    hits       *prometheus.Desc
+    misses     *prometheus.Desc
+    timeouts   *prometheus.Desc
+    totalConns *prometheus.Desc
+    idleConns  *prometheus.Desc
+    staleConns *prometheus.Desc
+
+    // pool counter totals, accumulated from
+    // deltas so they never go backwards.
+    counts poolCounts
+    pools  map[*Pool]*poolCounts
+    mu     sync.Mutex
+}
+
+// poolCounts are the monotonic counter statistics of a pool.
+type poolCounts struct {
+    hits     uint64
+    misses   uint64
+    timeouts uint64
+    stale    uint64
+}
+
+// add adds the change in pool stats since last observed to counts, updating last. Counters found lower
+// than last (i.e. reset by a reconnect replacing the client) are counted from zero.
+func (counts *poolCounts) add(last *poolCounts, stats *redis.PoolStats) {
+    delta := func(total, last *uint64, v uint32) {
+        if uint64(v) >= *last {
+            *total += uint64(v) - *last
+        } else {
+            *total += uint64(v)
+        }
+        *last = uint64(v)
+    }
+    delta(&counts.hits, &last.hits, stats.Hits)
+    delta(&counts.misses, &last.misses, stats.Misses)
+    delta(&counts.timeouts, &last.timeouts, stats.Timeouts)
+    delta(&counts.stale, &last.stale, stats.StaleConns)
+}
+
+// NewMetrics returns a new Metrics collector with metric names under given namespace.
+func NewMetrics(namespace string) *Metrics {
+    return &Metrics{
+        latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: namespace,
+            Subsystem: "redis_cache",
+            Name:      "operation_duration_seconds",
+            Help:      "Latency of redis cache operations, including retries.",
+            Buckets:   prometheus.DefBuckets,
+        }, []string{"operation"}),
+        errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "redis_cache",
+            Name:      "operation_errors_total",
+            Help:      "Number of redis cache operations that failed.",
+        }, []string{"operation"}),
+        retries: prometheus.NewCounter(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "redis_cache",
+            Name:      "retries_total",
+            Help:      "Number of retried redis commands.",
+        }),
+        hits:       poolDesc(namespace, "hits_total", "Number of times a free connection was found in the pool."),
+        misses:     poolDesc(namespace, "misses_total", "Number of times a free connection was not found in the pool."),
+        timeouts:   poolDesc(namespace, "timeouts_total", "Number of times a wait timeout occurred."),
+        totalConns: poolDesc(namespace, "connections", "Number of total connections in the pool."),
+        idleConns:  poolDesc(namespace, "idle_connections", "Number of idle connections in the pool."),
+        staleConns: poolDesc(namespace, "stale_connections_total", "Number of stale connections removed from the pool."),
+        pools:      make(map[*Pool]*poolCounts),
+    }
+}
+
+// poolDesc returns a new description for a pool statistic metric.
+func poolDesc(namespace, name, help string) *prometheus.Desc {
+    return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+    m.latency.Describe(ch)
+    m.errors.Describe(ch)
+    m.retries.Describe(ch)
+    ch <- m.hits
+    ch <- m.misses
+    ch <- m.timeouts
+    ch <- m.totalConns
+    ch <- m.idleConns
+    ch <- m.staleConns
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+    m.latency.Collect(ch)
+    m.errors.Collect(ch)
+    m.retries.Collect(ch)
+
+    var total, idle uint64
+
+    // Accumulate statistics of all attached pools.
+    m.mu.Lock()
+    for pool, last := range m.pools {
+        stats := pool.Stats()
+        m.counts.add(last, stats)
+        total += uint64(stats.TotalConns)
+        idle += uint64(stats.IdleConns)
+    }
+    counts := m.counts
+    m.mu.Unlock()
+
+    ch <- prometheus.MustNewConstMetric(m.hits, prometheus.CounterValue, float64(counts.hits))
+    ch <- prometheus.MustNewConstMetric(m.misses, prometheus.CounterValue, float64(counts.misses))
+    ch <- prometheus.MustNewConstMetric(m.timeouts, prometheus.CounterValue, float64(counts.timeouts))
+    ch <- prometheus.MustNewConstMetric(m.totalConns, prometheus.GaugeValue, float64(total))
+    ch <- prometheus.MustNewConstMetric(m.idleConns, prometheus.GaugeValue, float64(idle))
+    ch <- prometheus.MustNewConstMetric(m.staleConns, prometheus.CounterValue, float64(counts.stale))
+}
+
+// observe records a completed operation with its latency and resulting error.
+func (m *Metrics) observe(op string, took time.Duration, err error) {
+    m.latency.WithLabelValues(op).Observe(took.Seconds())
+    if err != nil {
+        m.errors.WithLabelValues(op).Inc()
+    }
+}
+
+// retry records a retried command.
+func (m *Metrics) retry() {
+    m.retries.Inc()
+}
+
+// attach adds pool to those whose statistics are collected.
+func (m *Metrics) attach(pool *Pool) {
+    m.mu.Lock()
+    m.pools[pool] = &poolCounts{}
+    m.mu.Unlock()
+}
+
+// detach removes pool from those whose statistics are collected, keeping its counts in the totals.
+func (m *Metrics) detach(pool *Pool) {
+    m.mu.Lock()
+    if last, ok := m.pools[pool]; ok {
+        m.counts.add(last, pool.Stats())
+        delete(m.pools, pool)
+    }
+    m.mu.Unlock()
+}
@@ -0,0 +1,144 @@
+package redis
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// listen subscribes to given channels, passing each received message to handle. The
+// subscription is re-established on failure (e.g. after a pool reconnect) until the
+// returned stop function is called, which blocks until the listener has exited.
+func (c *Cache[K, V]) listen(handle func(*redis.Message), channels ...string) (stop func()) {
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+
+    go func() {
+        defer close(done)
+
+        for ctx.Err() == nil {
+            pubsub := c.pool.Client().Subscribe(ctx, channels...)
+
+            // Ensure the subscription is closed on
+            // stop, which will close its channel.
+            exited := make(chan struct{})
+            go func() {
+                select {
+                case <-ctx.Done():
+                    _ = pubsub.Close()
+                case <-exited:
+                }
+            }()
+
+            for msg := range pubsub.Channel() {
+                handle(msg)
+            }
+
+            close(exited)
+            _ = pubsub.Close()
+
+            if ctx.Err() == nil {
+                c.opts.logger().Warn("redis: subscription lost, resubscribing", "channels", channels)
+            }
+
+            select {
+            case <-ctx.Done():
+            case <-time.After(c.opts.RetryBackoff):
+            }
+        }
+    }()
+
+    return func() {
+        cancel()
+        <-done
+    }
+}
+
+// localDelWindow is how long a key deleted by this cache is
+// expected to receive its own deletion notification within.
+const localDelWindow = time.Second * 10
+
+// localDels tracks keys recently deleted by this cache, so that the deletion
+// notifications for them do not trigger the invalidate callback a second time.
+type localDels struct {
+    keys map[string]time.Time
+    mu   sync.Mutex
+}
+
+// mark records given Redis keys as about to be deleted locally.
+func (d *localDels) mark(keys ...string) {
+    if d == nil {
+        return
+    }
+
+    now := time.Now()
+
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    if len(d.keys) > 1024 {
+        // Drop keys whose notifications never arrived,
+        // e.g. deletes of keys that did not exist.
+        for key, at := range d.keys {
+            if now.Sub(at) > localDelWindow {
+                delete(d.keys, key)
+            }
+        }
+    }
+
+    for _, key := range keys {
+        d.keys[key] = now
+    }
+}
+
+// consume returns whether Redis key was recently deleted locally, unmarking it.
+func (d *localDels) consume(key string) bool {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    at, ok := d.keys[key]
+    if ok {
+        delete(d.keys, key)
+    }
+
+    return ok && time.Since(at) <= localDelWindow
+}
+
+// listenKeyEvents subscribes to keyspace expiry and deletion events, passing expired keys to the eviction callback,
+// and keys deleted by other clients to the invalidate callback (e.g. to purge a local cache layered on top). Note
+// that values have already been removed server-side, so the hooks receive the zero value.
+func (c *Cache[K, V]) listenKeyEvents() (stop func()) {
+    expired := fmt.Sprintf("__keyevent@%d__:expired", c.opts.DB)
+    deleted := fmt.Sprintf("__keyevent@%d__:del", c.opts.DB)
+    c.dels = &localDels{keys: make(map[string]time.Time)}
+
+    return c.listen(func(msg *redis.Message) {
+        var hook func(K, V)
+
+        switch msg.Channel {
+        case expired:
+            hook = c.evictHook()
+        case deleted:
+            if c.dels.consume(msg.Payload) {
+                // Already handled.
+                return
+            }
+            hook = c.invalidHook()
+        }
+
+        if hook == nil {
+            return
+        }
+
+        key, ok := c.parseKey(msg.Payload)
+        if !ok {
+            return
+        }
+
+        var zero V
+        hook(key, zero)
+    }, expired, deleted)
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "github.com/mkc188/go-cache/v3/stats"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// AuditHook is called after every mutating cache operation, with the operation name, the affected Redis keys (none
+// for Clear), the total size of values written, the resulting error (if any) and how long the operation took.
+type AuditHook func(op string, keys []string, size int, err error, took time.Duration)
+
+// operation tracks a single cache operation from begin to end, for tracing, metrics and auditing.
+type operation struct {
+    name    string
+    start   time.Time
+    span    trace.Span
+    metrics *Metrics
+    stats   stats.Recorder
+    audit   AuditHook
+    keys    []string
+}
+
+// begin starts tracking cache operation with name on given number of keys.
+func (c *Cache[K, V]) begin(ctx context.Context, name string, keys int) (context.Context, *operation) {
+    ctx, span := c.startSpan(ctx, name, keys)
+    return ctx, &operation{
+        name:    name,
+        start:   time.Now(),
+        span:    span,
+        metrics: c.opts.Metrics,
+        stats:   c.opts.Stats,
+    }
+}
+
+// beginWrite is equivalent to begin, for a mutating cache operation on given Redis keys.
+func (c *Cache[K, V]) beginWrite(ctx context.Context, name string, keys ...string) (context.Context, *operation) {
+    ctx, op := c.begin(ctx, name, len(keys))
+    op.audit = c.opts.AuditHook
+    op.keys = keys
+    return ctx, op
+}
+
+// end finishes tracking the operation with resulting error (if any) and any further span attributes.
+func (op *operation) end(err error, attrs ...attribute.KeyValue) {
+    took := time.Since(op.start)
+
+    if op.metrics != nil {
+        op.metrics.observe(op.name, took, err)
+    }
+
+    if op.stats != nil {
+        op.record(took, err, attrs)
+    }
+
+    if op.audit != nil {
+        var size int
+        for _, attr := range attrs {
+            if attr.Key == attrPayloadSize {
+                size = int(attr.Value.AsInt64())
+            }
+        }
+        op.audit(op.name, op.keys, size, err, took)
+    }
+
+    endSpan(op.span, err, attrs...)
+}
+
+// record reports the finished operation to its stats recorder, as "redis.<op>" timings, "redis.<op>.errors"
+// counts and, for Get, "redis.hits" and "redis.misses" counts.
+func (op *operation) record(took time.Duration, err error, attrs []attribute.KeyValue) {
+    name := "redis." + strings.ToLower(op.name)
+    op.stats.Timing(name, took)
+
+    if err != nil {
+        op.stats.Count(name+".errors", 1)
+    }
+
+    if op.name == "Get" {
+        for _, attr := range attrs {
+            if attr.Key != attrHit {
+                continue
+            }
+            if attr.Value.AsBool() {
+                op.stats.Count("redis.hits", 1)
+            } else {
+                op.stats.Count("redis.misses", 1)
+            }
+        }
+    }
+}
@@ -1,7 +1,14 @@
 package redis
 
 import (
+    "crypto/tls"
     "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/mkc188/go-cache/v3/logging"
+    "github.com/mkc188/go-cache/v3/stats"
+
+    "go.opentelemetry.io/otel/trace"
 )
 
 type Options struct {
@@ -9,6 +16,12 @@ type Options struct {
     Addresses []string // Redis addresses (for cluster support)
     Password  string
     DB        int
+    TLSConfig *tls.Config // enables TLS (and mTLS, if client certificates are set) when non-nil
+
+    // Replica routing options, these only apply to cluster deployments
+    ReadOnly       bool // route read-only commands (Get, Has, MGet) to replicas
+    RouteByLatency bool // route read-only commands to the lowest latency node, implies ReadOnly
+    RouteRandomly  bool // route read-only commands to a random node, implies ReadOnly
 
     // Connection pool options
     PoolSize     int
@@ -16,8 +29,75 @@ type Options struct {
     MaxRetries   int
     RetryBackoff time.Duration
 
+    // RetryPolicy overrides the default exponential backoff derived from MaxRetries and RetryBackoff
+    RetryPolicy RetryPolicy
+
+    // OpTimeout bounds each attempt at a Redis command, <= 0 is no timeout
+    OpTimeout time.Duration
+
+    // Circuit breaker options, a BreakerThreshold <= 0 disables the breaker
+    BreakerThreshold   int           // consecutive failures before opening
+    BreakerOpenTimeout time.Duration // time spent open before probing again
+    BreakerProbes      int           // successful probes required to close
+
     // Cache options
     DefaultTTL time.Duration
+    TTLJitter  float64 // fraction [0, 1) by which each written TTL is randomized, e.g. 0.1 for +/- 10%
+
+    // Write-behind options, a WriteBehindInterval <= 0 disables write-behind. When enabled, Set and
+    // Invalidate are queued and flushed in a single pipeline every interval (or once the queue reaches
+    // WriteBehindSize), with queued writes visible to Get and Has from this cache only until flushed.
+    WriteBehindInterval time.Duration
+    WriteBehindSize     int
+
+    // Degraded-mode options, a FallbackSize <= 0 disables the fallback. When enabled, values read and written are
+    // also kept in a local TTL cache of this capacity, which serves Get while Redis is unreachable, and Set and
+    // Invalidate are applied locally and buffered for replay to Redis once it is reachable again.
+    FallbackSize int
+    FallbackTTL  time.Duration
+
+    // GetOrLoad options
+    LoadLockTTL      time.Duration // maximum time a loader may hold the key lock
+    LoadPollInterval time.Duration // interval at which waiting callers check for the loaded value
+    LoadLockPrefix   string        // prefix of lock keys, which are excluded from Range, Clear and MemoryUsage scans
+
+    // KeyspaceNotifications enables subscribing to server-side expiry and
+    // deletion events, to call the eviction callback on expiry and invalidate
+    // callback on deletion by other clients. This requires the server to be
+    // configured with notify-keyspace-events including "Egx".
+    KeyspaceNotifications bool
+
+    // TracerProvider enables OpenTelemetry spans for cache operations, nil disables tracing.
+    TracerProvider trace.TracerProvider
+
+    // Metrics enables recording Prometheus metrics for cache operations and pools, nil disables metrics.
+    Metrics *Metrics
+
+    // Stats receives operation timings, error counts, hits, misses and retries, nil disables recording.
+    Stats stats.Recorder
+
+    // Logger receives health check, reconnect, retry exhaustion and background flush events, nil disables logging.
+    Logger logging.Logger
+
+    // AuditHook is called after every mutating operation, nil disables auditing.
+    AuditHook AuditHook
+}
+
+// universal returns the go-redis client options equivalent to these options.
+func (o *Options) universal() *redis.UniversalOptions {
+    return &redis.UniversalOptions{
+        Addrs:           o.Addresses,
+        Password:        o.Password,
+        DB:              o.DB,
+        TLSConfig:       o.TLSConfig,
+        PoolSize:        o.PoolSize,
+        MinIdleConns:    o.MinIdleConns,
+        MaxRetries:      o.MaxRetries,
+        MaxRetryBackoff: o.RetryBackoff,
+        ReadOnly:        o.ReadOnly,
+        RouteByLatency:  o.RouteByLatency,
+        RouteRandomly:   o.RouteRandomly,
+    }
 }
 
 func DefaultOptions() *Options {
@@ -30,3 +110,11 @@ func DefaultOptions() *Options {
         DefaultTTL:   time.Hour,
     }
 }
+
+// logger returns the configured Logger, or a no-op Logger if unset.
+func (o *Options) logger() logging.Logger {
+    if o.Logger == nil {
+        return logging.Nop{}
+    }
+    return o.Logger
+}
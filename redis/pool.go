@@ -12,15 +12,20 @@ type Pool struct {
     client  redis.UniversalClient
     opts    *Options
     health  *HealthChecker
+    once    sync.Once
     mu      sync.RWMutex
 }
 
 type HealthChecker struct {
-    stopCh    chan struct{}
-    interval  time.Duration
-    threshold int
-    failures  int
-    mu        sync.RWMutex
+    stopCh      chan struct{}
+    doneCh      chan struct{}
+    interval    time.Duration
+    threshold   int
+    failures    int
+    unhealthy   bool
+    onHealth    func(healthy bool, err error)
+    onReconnect func()
+    mu          sync.RWMutex
 }
 
 func NewPool(opts *Options) *Pool {
@@ -28,26 +33,23 @@ func NewPool(opts *Options) *Pool {
         opts = DefaultOptions()
     }
 
-    client := redis.NewUniversalClient(&redis.UniversalOptions{
-        Addrs:           opts.Addresses,
-        Password:        opts.Password,
-        DB:             opts.DB,
-        PoolSize:        opts.PoolSize,
-        MinIdleConns:    opts.MinIdleConns,
-        MaxRetries:      opts.MaxRetries,
-        MaxRetryBackoff: opts.RetryBackoff,
-    })
+    client := redis.NewUniversalClient(opts.universal())
 
     pool := &Pool{
         client: client,
         opts:   opts,
         health: &HealthChecker{
             stopCh:    make(chan struct{}),
+            doneCh:    make(chan struct{}),
             interval:  time.Second * 5,
             threshold: 3,
         },
     }
 
+    if opts.Metrics != nil {
+        opts.Metrics.attach(pool)
+    }
+
     pool.startHealthCheck()
     return pool
 }
@@ -56,6 +58,7 @@ func (p *Pool) startHealthCheck() {
     go func() {
         ticker := time.NewTicker(p.health.interval)
         defer ticker.Stop()
+        defer close(p.health.doneCh)
 
         for {
             select {
@@ -72,19 +75,84 @@ func (p *Pool) checkHealth() {
     ctx, cancel := context.WithTimeout(context.Background(), time.Second)
     defer cancel()
 
-    err := p.client.Ping(ctx).Err()
+    err := p.Client().Ping(ctx).Err()
+
+    var (
+        // health state changed?
+        changed bool
+
+        // did reconnect?
+        reconnected bool
+
+        // consecutive failures.
+        failures int
+
+        // hook func ptrs.
+        onHealth    func(bool, error)
+        onReconnect func()
+    )
 
     p.health.mu.Lock()
-    defer p.health.mu.Unlock()
 
     if err != nil {
         p.health.failures++
         if p.health.failures >= p.health.threshold {
+            changed = !p.health.unhealthy
+            p.health.unhealthy = true
             p.reconnect()
+            reconnected = true
         }
     } else {
+        changed = p.health.unhealthy
+        p.health.unhealthy = false
         p.health.failures = 0
     }
+
+    failures = p.health.failures
+    onHealth = p.health.onHealth
+    onReconnect = p.health.onReconnect
+
+    p.health.mu.Unlock()
+
+    log := p.opts.logger()
+
+    switch {
+    case reconnected:
+        log.Warn("redis: reconnected after failed health checks", "failures", failures, "err", err)
+    case err != nil:
+        log.Warn("redis: health check failed", "failures", failures, "err", err)
+    case changed:
+        log.Info("redis: health check recovered")
+    }
+
+    if changed && onHealth != nil {
+        onHealth(err == nil, err)
+    }
+
+    if reconnected && onReconnect != nil {
+        onReconnect()
+    }
+}
+
+// Healthy returns whether the pool is currently considered healthy, i.e. health checks are passing.
+func (p *Pool) Healthy() bool {
+    p.health.mu.RLock()
+    defer p.health.mu.RUnlock()
+    return !p.health.unhealthy
+}
+
+// SetHealthCallback sets the hook called on health state transitions, with the health check error when becoming unhealthy.
+func (p *Pool) SetHealthCallback(hook func(healthy bool, err error)) {
+    p.health.mu.Lock()
+    p.health.onHealth = hook
+    p.health.mu.Unlock()
+}
+
+// SetReconnectCallback sets the hook called after the client has been reconnected due to failing health checks.
+func (p *Pool) SetReconnectCallback(hook func()) {
+    p.health.mu.Lock()
+    p.health.onReconnect = hook
+    p.health.mu.Unlock()
 }
 
 func (p *Pool) reconnect() {
@@ -92,24 +160,39 @@ func (p *Pool) reconnect() {
     defer p.mu.Unlock()
 
     oldClient := p.client
-    p.client = redis.NewUniversalClient(&redis.UniversalOptions{
-        Addrs:           p.opts.Addresses,
-        Password:        p.opts.Password,
-        DB:             p.opts.DB,
-        PoolSize:        p.opts.PoolSize,
-        MinIdleConns:    p.opts.MinIdleConns,
-        MaxRetries:      p.opts.MaxRetries,
-        MaxRetryBackoff: p.opts.RetryBackoff,
-    })
+    p.client = redis.NewUniversalClient(p.opts.universal())
 
     if oldClient != nil {
         oldClient.Close()
     }
 }
 
+// Ping checks the connection to Redis, establishing one if not already.
+func (p *Pool) Ping(ctx context.Context) error {
+    return p.Client().Ping(ctx).Err()
+}
+
+// Close stops the health checker and closes the client, further calls are no-ops.
 func (p *Pool) Close() error {
-    close(p.health.stopCh)
-    return p.client.Close()
+    var err error
+    p.once.Do(func() {
+        if p.opts.Metrics != nil {
+            p.opts.Metrics.detach(p)
+        }
+
+        // Stop health checker, waiting on any
+        // in-progress check (and reconnect).
+        close(p.health.stopCh)
+        <-p.health.doneCh
+
+        err = p.Client().Close()
+    })
+    return err
+}
+
+// Stats returns connection pool statistics (hits, misses, timeouts, total/idle/stale connections) of the current client.
+func (p *Pool) Stats() *redis.PoolStats {
+    return p.Client().PoolStats()
 }
 
 func (p *Pool) Client() redis.UniversalClient {
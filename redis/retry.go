@@ -2,6 +2,10 @@ package redis
 
 import (
     "context"
+    "errors"
+    "math/rand"
+    "net"
+    "syscall"
     "time"
 
     "github.com/go-redis/redis/v8"
@@ -9,18 +13,97 @@ import (
 
 type RetryableFunc func(context.Context) error
 
+// RetryPolicy determines whether, and after what delay, a failed command is retried.
+type RetryPolicy interface {
+    // Backoff returns the delay before given retry attempt (starting at 1), with
+    // elapsed time since the first attempt, or false if no more retries are allowed.
+    Backoff(attempt int, elapsed time.Duration) (time.Duration, bool)
+}
+
+// ExponentialBackoff is a RetryPolicy doubling the delay between each attempt, with optional caps and jitter.
+type ExponentialBackoff struct {
+    Base       time.Duration // delay before first retry
+    MaxDelay   time.Duration // cap on any single delay, <= 0 is no cap
+    MaxRetries int           // maximum number of retries
+    MaxElapsed time.Duration // total time budget for all attempts, <= 0 is no budget
+    Jitter     float64       // fraction [0, 1] by which each delay is randomly reduced
+}
+
+// Backoff implements RetryPolicy.
+func (b ExponentialBackoff) Backoff(attempt int, elapsed time.Duration) (time.Duration, bool) {
+    if attempt > b.MaxRetries {
+        return 0, false
+    }
+
+    backoff := b.Base
+    for i := 1; i < attempt; i++ {
+        backoff *= 2
+        if b.MaxDelay > 0 && backoff >= b.MaxDelay {
+            break
+        }
+    }
+
+    if b.MaxDelay > 0 && backoff > b.MaxDelay {
+        backoff = b.MaxDelay
+    }
+
+    if b.Jitter > 0 {
+        // Randomly reduce to avoid synchronized retries.
+        backoff -= time.Duration(b.Jitter * rand.Float64() * float64(backoff))
+    }
+
+    if b.MaxElapsed > 0 && elapsed+backoff > b.MaxElapsed {
+        return 0, false
+    }
+
+    return backoff, true
+}
+
 func (c *Cache[K, V]) withRetry(ctx context.Context, fn RetryableFunc) error {
+    if !c.inflight.acquire() {
+        return redis.ErrClosed
+    }
+    defer c.inflight.release()
+    return c.retry(ctx, fn)
+}
+
+// retry calls fn, retrying on retryable errors as per the retry policy. Unlike withRetry this is not
+// registered in-flight, and so is only for use by routines stopped by CloseContext before closing the pool.
+func (c *Cache[K, V]) retry(ctx context.Context, fn RetryableFunc) error {
     var lastErr error
-    for attempt := 0; attempt <= c.opts.MaxRetries; attempt++ {
+    start := time.Now()
+    for attempt := 0; ; attempt++ {
         if attempt > 0 {
+            backoff, ok := c.retryPolicy().Backoff(attempt, time.Since(start))
+            if !ok {
+                c.opts.logger().Error("redis: retries exhausted", "attempts", attempt, "err", lastErr)
+                return lastErr
+            }
+
+            if c.opts.Metrics != nil {
+                c.opts.Metrics.retry()
+            }
+
+            if c.opts.Stats != nil {
+                c.opts.Stats.Count("redis.retries", 1)
+            }
+
             select {
             case <-ctx.Done():
                 return ctx.Err()
-            case <-time.After(c.getBackoff(attempt)):
+            case <-time.After(backoff):
             }
         }
 
-        err := fn(ctx)
+        if !c.breaker.allow() {
+            if lastErr != nil {
+                return lastErr
+            }
+            return ErrCircuitOpen
+        }
+
+        err := c.attempt(ctx, fn)
+        c.breaker.record(isRetryableError(err))
         if err == nil {
             return nil
         }
@@ -30,15 +113,27 @@ func (c *Cache[K, V]) withRetry(ctx context.Context, fn RetryableFunc) error {
             return err
         }
     }
-    return lastErr
 }
 
-func (c *Cache[K, V]) getBackoff(attempt int) time.Duration {
-    backoff := c.opts.RetryBackoff
-    for i := 1; i < attempt; i++ {
-        backoff *= 2
+// attempt makes a single attempt at fn, bounded by the configured operation timeout.
+func (c *Cache[K, V]) attempt(ctx context.Context, fn RetryableFunc) error {
+    if c.opts.OpTimeout <= 0 {
+        return fn(ctx)
+    }
+    ctx, cancel := context.WithTimeout(ctx, c.opts.OpTimeout)
+    defer cancel()
+    return fn(ctx)
+}
+
+// retryPolicy returns the configured RetryPolicy, or the default policy derived from MaxRetries and RetryBackoff.
+func (c *Cache[K, V]) retryPolicy() RetryPolicy {
+    if c.opts.RetryPolicy != nil {
+        return c.opts.RetryPolicy
+    }
+    return ExponentialBackoff{
+        Base:       c.opts.RetryBackoff,
+        MaxRetries: c.opts.MaxRetries,
     }
-    return backoff
 }
 
 func isRetryableError(err error) bool {
@@ -67,9 +162,12 @@ func isNetworkError(err error) bool {
         return false
     }
 
-    // Check for network-related errors
-    // This is a simplified check - in production you'd want more comprehensive error type checking
-    return err.Error() == "connection refused" ||
-        err.Error() == "connection reset by peer" ||
-        err.Error() == "i/o timeout"
+    // Check for network-related errors, e.g. dial
+    // failures and timeouts (both net.Error), and
+    // connections dropped mid-command.
+    var netErr net.Error
+    return errors.As(err, &netErr) ||
+        errors.Is(err, syscall.ECONNREFUSED) ||
+        errors.Is(err, syscall.ECONNRESET) ||
+        errors.Is(err, syscall.EPIPE)
 }
@@ -0,0 +1,123 @@
+package redis_test
+
+import (
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/mkc188/go-cache/v3/redis"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+    b := redis.ExponentialBackoff{
+        Base:       time.Millisecond * 10,
+        MaxDelay:   time.Millisecond * 30,
+        MaxRetries: 3,
+    }
+
+    for i, want := range []time.Duration{
+        time.Millisecond * 10,
+        time.Millisecond * 20,
+        time.Millisecond * 30, // capped
+    } {
+        attempt := i + 1
+        if d, ok := b.Backoff(attempt, 0); !ok || d != want {
+            t.Errorf("attempt %d: unexpected backoff: %v %v", attempt, d, ok)
+        }
+    }
+
+    if _, ok := b.Backoff(4, 0); ok {
+        t.Error("retry allowed beyond MaxRetries")
+    }
+
+    b.MaxElapsed = time.Millisecond * 25
+    if _, ok := b.Backoff(2, time.Millisecond*10); ok {
+        t.Error("retry allowed beyond MaxElapsed")
+    }
+}
+
+// countPolicy is a RetryPolicy allowing max retries without delay, recording each attempt.
+type countPolicy struct {
+    max      int
+    attempts []int
+    mu       sync.Mutex
+}
+
+func (p *countPolicy) Backoff(attempt int, _ time.Duration) (time.Duration, bool) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.attempts = append(p.attempts, attempt)
+    return 0, attempt <= p.max
+}
+
+func TestRetryPolicy(t *testing.T) {
+    policy := &countPolicy{max: 2}
+    opts := unreachableOptions(t)
+    opts.RetryPolicy = policy
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    // Network errors are retried.
+    if _, err := c.GetE("a"); err == nil {
+        t.Fatal("expected error")
+    }
+    if len(policy.attempts) != 3 || policy.attempts[2] != 3 {
+        t.Fatalf("unexpected attempts: %v", policy.attempts)
+    }
+
+    // Others are not.
+    mr, opts := miniOptions(t)
+    policy = &countPolicy{max: 2}
+    opts.RetryPolicy = policy
+    mr.SetError("ERR failed")
+
+    c = redis.New[string, int](opts)
+    defer c.Close()
+
+    if _, err := c.GetE("a"); err == nil || errors.Is(err, redis.ErrCircuitOpen) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if len(policy.attempts) != 0 {
+        t.Fatalf("unexpected attempts: %v", policy.attempts)
+    }
+}
+
+func TestBreaker(t *testing.T) {
+    mr, opts := miniOptions(t)
+    opts.MaxRetries = 0
+    opts.BreakerThreshold = 2
+    opts.BreakerOpenTimeout = time.Millisecond * 100
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    c.Set("a", 1)
+    mr.Close()
+
+    // Opens after consecutive failures...
+    for i := 0; i < 2; i++ {
+        if _, err := c.GetE("a"); err == nil || errors.Is(err, redis.ErrCircuitOpen) {
+            t.Fatalf("unexpected error: %v", err)
+        }
+    }
+
+    // ...failing fast while open.
+    if _, err := c.GetE("a"); !errors.Is(err, redis.ErrCircuitOpen) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    if err := mr.Restart(); err != nil {
+        t.Fatal(err)
+    }
+    time.Sleep(opts.BreakerOpenTimeout)
+
+    // Closes again after a successful probe.
+    if err := c.SetE("a", 2); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if v, err := c.GetE("a"); err != nil || v != 2 {
+        t.Fatalf("unexpected result: %d %v", v, err)
+    }
+}
@@ -0,0 +1,121 @@
+package redis
+
+import (
+    "context"
+    "sync"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// scanKeys iterates over all keys matching pattern using SCAN (on every master, for cluster clients), passing each
+// batch of keys with the client they were scanned from to fn, until it returns false or an error. Calls to fn are
+// serialized, and any keys modified during iteration may be skipped or seen more than once.
+func (c *Cache[K, V]) scanKeys(ctx context.Context, pattern string, fn func(context.Context, redis.Cmdable, []string) (bool, error)) error {
+    var (
+        stopped bool
+        mu      sync.Mutex
+    )
+
+    scan := func(ctx context.Context, client redis.Cmdable) error {
+        var cursor uint64
+        for {
+            var keys []string
+            var next uint64
+
+            err := c.withRetry(ctx, func(ctx context.Context) error {
+                var err error
+                keys, next, err = client.Scan(ctx, cursor, pattern, rangeScanCount).Result()
+                return err
+            })
+            if err != nil {
+                return err
+            }
+
+            // Skip GetOrLoad locks.
+            keys = c.dropLockKeys(keys)
+
+            mu.Lock()
+            if !stopped && len(keys) > 0 {
+                var cont bool
+                cont, err = fn(ctx, client, keys)
+                stopped = !cont
+            }
+            done := stopped
+            mu.Unlock()
+
+            if err != nil {
+                return err
+            }
+
+            if cursor = next; done || cursor == 0 {
+                return nil
+            }
+        }
+    }
+
+    if cluster, ok := c.pool.Client().(*redis.ClusterClient); ok {
+        // Each master holds its own keyspace to scan.
+        return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+            return scan(ctx, client)
+        })
+    }
+
+    return scan(ctx, c.pool.Client())
+}
+
+// dropLockKeys returns keys without any GetOrLoad lock keys, filtered in place.
+func (c *Cache[K, V]) dropLockKeys(keys []string) []string {
+    n := 0
+    for _, key := range keys {
+        if !c.isLockKey(key) {
+            keys[n] = key
+            n++
+        }
+    }
+    return keys[:n]
+}
+
+// MemoryStats summarizes the Redis memory usage of a set of keys.
+type MemoryStats struct {
+    Keys  int   // number of keys
+    Bytes int64 // total bytes used by keys and values
+}
+
+// MemoryUsage returns the memory usage of all keys matching pattern (in SCAN syntax, e.g. "user:*"), as
+// reported by MEMORY USAGE, where nested values are estimated by sampling. Note this visits every
+// matching key, so may be expensive for large keyspaces.
+func (c *Cache[K, V]) MemoryUsage(ctx context.Context, pattern string) (MemoryStats, error) {
+    var stats MemoryStats
+
+    ctx, op := c.begin(ctx, "MemoryUsage", 0)
+
+    err := c.scanKeys(ctx, pattern, func(ctx context.Context, client redis.Cmdable, keys []string) (bool, error) {
+        var cmds []*redis.IntCmd
+
+        err := c.withRetry(ctx, func(ctx context.Context) error {
+            pipe := client.Pipeline()
+            cmds = make([]*redis.IntCmd, len(keys))
+            for i, key := range keys {
+                cmds[i] = pipe.MemoryUsage(ctx, key)
+            }
+
+            // Keys may expire since scanned, ignore.
+            return execErr(pipe.Exec(ctx))
+        })
+        if err != nil {
+            return false, err
+        }
+
+        for _, cmd := range cmds {
+            if n, err := cmd.Result(); err == nil {
+                stats.Keys++
+                stats.Bytes += n
+            }
+        }
+
+        return true, nil
+    })
+
+    op.end(err)
+    return stats, err
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+    "github.com/go-redis/redis/v8"
+)
+
+// casScript atomically replaces the value at KEYS[1] with ARGV[2], only if the
+// currently stored (serialized) value is equal to ARGV[1]. ARGV[3] is the TTL
+// in milliseconds, where a value <= 0 stores the key without expiry.
+var casScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+if tonumber(ARGV[3]) > 0 then
+    redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+    redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// extendScript atomically extends the remaining TTL of KEYS[1] by ARGV[1] milliseconds,
+// returning 0 if the key does not exist. Keys without expiry are left untouched.
+var extendScript = redis.NewScript(`
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl == -2 then
+    return 0
+end
+if ttl >= 0 then
+    redis.call("PEXPIRE", KEYS[1], ttl + tonumber(ARGV[1]))
+end
+return 1
+`)
+
+// unlockScript deletes the lock at KEYS[1] only if still held with token ARGV[1].
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
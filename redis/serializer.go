@@ -0,0 +1,132 @@
+package redis
+
+import (
+    "encoding/json"
+    "errors"
+    "sort"
+    "strings"
+)
+
+// ErrUnsupportedValue is returned by a Serializer given a value type it cannot handle.
+var ErrUnsupportedValue = errors.New("redis: unsupported value type for serializer")
+
+// Serializer encodes values for storage in Redis, and decodes them back.
+type Serializer interface {
+    // Marshal encodes value v.
+    Marshal(v any) ([]byte, error)
+
+    // Unmarshal decodes data into value pointed to by v.
+    Unmarshal(data []byte, v any) error
+}
+
+// JSONSerializer is a Serializer using JSON encoding, this is the default.
+type JSONSerializer struct{}
+
+// Marshal implements Serializer.
+func (JSONSerializer) Marshal(v any) ([]byte, error) {
+    return json.Marshal(v)
+}
+
+// Unmarshal implements Serializer.
+func (JSONSerializer) Unmarshal(data []byte, v any) error {
+    return json.Unmarshal(data, v)
+}
+
+// RawSerializer is a Serializer passing []byte and string values through as-is, for already encoded data.
+type RawSerializer struct{}
+
+// Marshal implements Serializer.
+func (RawSerializer) Marshal(v any) ([]byte, error) {
+    switch v := v.(type) {
+    case []byte:
+        return v, nil
+    case string:
+        return []byte(v), nil
+    default:
+        return nil, ErrUnsupportedValue
+    }
+}
+
+// Unmarshal implements Serializer.
+func (RawSerializer) Unmarshal(data []byte, v any) error {
+    switch v := v.(type) {
+    case *[]byte:
+        *v = append((*v)[:0], data...)
+        return nil
+    case *string:
+        *v = string(data)
+        return nil
+    default:
+        return ErrUnsupportedValue
+    }
+}
+
+// prefixSerializer is a Serializer override for Redis keys with prefix.
+type prefixSerializer struct {
+    prefix string
+    serial Serializer
+}
+
+// SetSerializer sets the Serializer used for values, this should be set before first use. As each cache holds
+// a single value type, this acts as the per-type override (e.g. RawSerializer for a cache of []byte values).
+func (c *Cache[K, V]) SetSerializer(s Serializer) {
+    if s == nil {
+        s = JSONSerializer{}
+    }
+    c.mu.Lock()
+    c.serial = s
+    c.mu.Unlock()
+}
+
+// SetPrefixSerializer sets a Serializer override for values at Redis keys beginning with prefix, with the longest
+// matching prefix taking precedence. A nil Serializer removes the override. This should be set before first use.
+func (c *Cache[K, V]) SetPrefixSerializer(prefix string, s Serializer) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    // Copy on write, as readers
+    // range over it unlocked.
+    prefixes := make([]prefixSerializer, 0, len(c.prefixes)+1)
+
+    // Drop any existing override.
+    for _, p := range c.prefixes {
+        if p.prefix != prefix {
+            prefixes = append(prefixes, p)
+        }
+    }
+
+    if s != nil {
+        prefixes = append(prefixes, prefixSerializer{prefix, s})
+    }
+
+    // Keep longest prefixes first for matching.
+    sort.SliceStable(prefixes, func(i, j int) bool {
+        return len(prefixes[i].prefix) > len(prefixes[j].prefix)
+    })
+
+    c.prefixes = prefixes
+}
+
+// serializer returns the Serializer to use for value at Redis key.
+func (c *Cache[K, V]) serializer(rkey string) Serializer {
+    c.mu.RLock()
+    prefixes, serial := c.prefixes, c.serial
+    c.mu.RUnlock()
+
+    for i := range prefixes {
+        if strings.HasPrefix(rkey, prefixes[i].prefix) {
+            return prefixes[i].serial
+        }
+    }
+    return serial
+}
+
+// marshal encodes value for storage at Redis key.
+func (c *Cache[K, V]) marshal(rkey string, value V) ([]byte, error) {
+    return c.serializer(rkey).Marshal(value)
+}
+
+// unmarshal decodes data stored at Redis key into value.
+func (c *Cache[K, V]) unmarshal(rkey string, data []byte, value *V) error {
+    return c.serializer(rkey).Unmarshal(data, value)
+}
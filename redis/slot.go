@@ -0,0 +1,69 @@
+package redis
+
+import (
+    "strings"
+
+    "github.com/go-redis/redis/v8"
+)
+
+const (
+    // maxBatchKeys is the maximum number of keys sent in a single multi-key command.
+    maxBatchKeys = 500
+
+    // rangeScanCount is the SCAN count hint used when iterating keys.
+    rangeScanCount = 100
+)
+
+// batchKeys splits the indices of given keys into batches that can each be sent as a single
+// multi-key command, i.e. of limited size and (for cluster clients) all within the same slot.
+func (c *Cache[K, V]) batchKeys(keys []string) [][]int {
+    _, cluster := c.pool.Client().(*redis.ClusterClient)
+
+    var batches [][]int
+    bySlot := make(map[int]int)
+
+    for i, key := range keys {
+        slot := 0
+        if cluster {
+            slot = keySlot(key)
+        }
+
+        // Look for existing batch with space.
+        b, ok := bySlot[slot]
+        if !ok || len(batches[b]) >= maxBatchKeys {
+            b = len(batches)
+            batches = append(batches, make([]int, 0, 1))
+            bySlot[slot] = b
+        }
+
+        batches[b] = append(batches[b], i)
+    }
+
+    return batches
+}
+
+// keySlot returns the Redis cluster hash slot for key, honouring {hash tags}.
+func keySlot(key string) int {
+    if s := strings.IndexByte(key, '{'); s > -1 {
+        if e := strings.IndexByte(key[s+1:], '}'); e > 0 {
+            key = key[s+1 : s+1+e]
+        }
+    }
+    return int(crc16(key) % 16384)
+}
+
+// crc16 implements the CRC16-CCITT (XMODEM) checksum used for cluster key slots.
+func crc16(s string) uint16 {
+    var crc uint16
+    for i := 0; i < len(s); i++ {
+        crc ^= uint16(s[i]) << 8
+        for j := 0; j < 8; j++ {
+            if crc&0x8000 != 0 {
+                crc = crc<<1 ^ 0x1021
+            } else {
+                crc <<= 1
+            }
+        }
+    }
+    return crc
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+    "go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation name used when acquiring a tracer.
+const tracerName = "github.com/mkc188/go-cache/v3/redis"
+
+// Span attribute keys set on cache operation spans.
+const (
+    attrOperation   = attribute.Key("cache.operation")
+    attrKeyCount    = attribute.Key("cache.key_count")
+    attrHit         = attribute.Key("cache.hit")
+    attrPayloadSize = attribute.Key("cache.payload_size")
+)
+
+// startSpan starts a span for cache operation 'op' on given number of keys, returning a no-op span if tracing is disabled.
+func (c *Cache[K, V]) startSpan(ctx context.Context, op string, keys int) (context.Context, trace.Span) {
+    if c.tracer == nil {
+        return ctx, noop.Span{}
+    }
+    return c.tracer.Start(ctx, "redis.Cache."+op,
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(
+            attrOperation.String(op),
+            attrKeyCount.Int(keys),
+        ),
+    )
+}
+
+// endSpan ends given span, recording the operation error (if any) and any further attributes.
+func endSpan(span trace.Span, err error, attrs ...attribute.KeyValue) {
+    if span.IsRecording() {
+        span.SetAttributes(attrs...)
+        if err != nil {
+            span.RecordError(err)
+            span.SetStatus(codes.Error, err.Error())
+        }
+    }
+    span.End()
+}
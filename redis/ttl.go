@@ -18,6 +18,15 @@ func NewTTL[K comparable, V any](opts *Options) *TTLCache[K, V] {
     }
 }
 
+// DialTTL is equivalent to NewTTL, but eagerly connects to Redis, see Dial.
+func DialTTL[K comparable, V any](ctx context.Context, opts *Options) (*TTLCache[K, V], error) {
+    c, err := Dial[K, V](ctx, opts)
+    if err != nil {
+        return nil, err
+    }
+    return &TTLCache[K, V]{Cache: c}, nil
+}
+
 func (c *TTLCache[K, V]) Start(_ time.Duration) bool {
     // Redis handles TTL expiration automatically
     return true
@@ -28,8 +37,8 @@ func (c *TTLCache[K, V]) Stop() bool {
 }
 
 func (c *TTLCache[K, V]) SetTTL(ttl time.Duration, update bool) {
-    c.Lock()
-    defer c.Unlock()
+    c.mu.Lock()
+    defer c.mu.Unlock()
 
     c.opts.DefaultTTL = ttl
 
@@ -38,6 +47,10 @@ func (c *TTLCache[K, V]) SetTTL(ttl time.Duration, update bool) {
         iter := c.pool.Client().Scan(ctx, 0, "*", 0).Iterator()
         for iter.Next(ctx) {
             key := iter.Val()
+            if c.isLockKey(key) {
+                // Keep lock expiry.
+                continue
+            }
             c.pool.Client().Expire(ctx, key, ttl)
         }
     }
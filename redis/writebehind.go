@@ -0,0 +1,212 @@
+package redis
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+)
+
+// pendingWrite is a queued write-behind mutation.
+type pendingWrite[K comparable] struct {
+    key  K
+    data []byte // nil for deletion
+    ttl  time.Duration
+}
+
+// writeBehind queues Set and Invalidate mutations to be flushed to Redis in batches.
+type writeBehind[K comparable] struct {
+    pending map[string]pendingWrite[K]
+    flushCh chan struct{}
+    stopCh  chan struct{}
+    doneCh  chan struct{}
+    mu      sync.Mutex
+}
+
+// startWriteBehind starts the write-behind flush routine, flushing queued mutations
+// every interval or once the queue reaches the configured size, whichever first.
+func (c *Cache[K, V]) startWriteBehind() {
+    c.wb = &writeBehind[K]{
+        pending: make(map[string]pendingWrite[K]),
+        flushCh: make(chan struct{}, 1),
+        stopCh:  make(chan struct{}),
+        doneCh:  make(chan struct{}),
+    }
+
+    go func() {
+        ticker := time.NewTicker(c.opts.WriteBehindInterval)
+        defer ticker.Stop()
+        defer close(c.wb.doneCh)
+
+        for {
+            select {
+            case <-c.wb.stopCh:
+                if err := c.flushAll(context.Background()); err != nil {
+                    c.opts.logger().Error("redis: final write-behind flush failed", "err", err)
+                }
+                return
+            case <-ticker.C:
+            case <-c.wb.flushCh:
+            }
+            if err := c.flushAll(context.Background()); err != nil {
+                c.opts.logger().Warn("redis: write-behind flush failed", "err", err)
+            }
+        }
+    }()
+}
+
+// stopWriteBehind stops the write-behind flush routine, after a final flush.
+func (c *Cache[K, V]) stopWriteBehind() {
+    if c.wb == nil {
+        return
+    }
+    close(c.wb.stopCh)
+    <-c.wb.doneCh
+}
+
+// queue queues a write-behind mutation of Redis key, replacing any pending for it.
+func (c *Cache[K, V]) queue(rkey string, w pendingWrite[K]) {
+    c.wb.mu.Lock()
+    c.wb.pending[rkey] = w
+    full := c.opts.WriteBehindSize > 0 && len(c.wb.pending) >= c.opts.WriteBehindSize
+    c.wb.mu.Unlock()
+
+    if full {
+        select {
+        case c.wb.flushCh <- struct{}{}:
+        default:
+        }
+    }
+}
+
+// pending returns any queued write-behind mutation of Redis key.
+func (c *Cache[K, V]) pending(rkey string) (pendingWrite[K], bool) {
+    if c.wb == nil {
+        return pendingWrite[K]{}, false
+    }
+    c.wb.mu.Lock()
+    w, ok := c.wb.pending[rkey]
+    c.wb.mu.Unlock()
+    return w, ok
+}
+
+// Flush writes all queued write-behind mutations to Redis in a single pipeline, calling the
+// invalidate callback for any replaced or deleted values. This is a no-op if write-behind is disabled.
+func (c *Cache[K, V]) Flush(ctx context.Context) error {
+    if c.wb == nil {
+        return nil
+    }
+    if !c.inflight.acquire() {
+        return redis.ErrClosed
+    }
+    defer c.inflight.release()
+    return c.flushAll(ctx)
+}
+
+// flushAll performs Flush, without registering in-flight (i.e. for the flush routine).
+func (c *Cache[K, V]) flushAll(ctx context.Context) error {
+    // Take all queued mutations.
+    c.wb.mu.Lock()
+    pending := c.wb.pending
+    c.wb.pending = make(map[string]pendingWrite[K], len(pending))
+    c.wb.mu.Unlock()
+
+    return c.flush(ctx, pending)
+}
+
+// flushKeys writes any queued write-behind mutations of given Redis keys as Flush, such that server-side
+// operations on them (e.g. CAS) act on, and are not later overwritten by, earlier queued writes.
+func (c *Cache[K, V]) flushKeys(ctx context.Context, rkeys ...string) error {
+    if c.wb == nil {
+        return nil
+    }
+    if !c.inflight.acquire() {
+        return redis.ErrClosed
+    }
+    defer c.inflight.release()
+
+    // Take queued mutations of keys.
+    var pending map[string]pendingWrite[K]
+    c.wb.mu.Lock()
+    for _, rkey := range rkeys {
+        if w, ok := c.wb.pending[rkey]; ok {
+            if pending == nil {
+                pending = make(map[string]pendingWrite[K])
+            }
+            pending[rkey] = w
+            delete(c.wb.pending, rkey)
+        }
+    }
+    c.wb.mu.Unlock()
+
+    return c.flush(ctx, pending)
+}
+
+// flush writes given taken write-behind mutations to Redis in a single pipeline, requeueing them on failure.
+// (NOTE: requires in-flight registration, unless called by the flush routine)
+func (c *Cache[K, V]) flush(ctx context.Context, pending map[string]pendingWrite[K]) error {
+    if len(pending) == 0 {
+        return nil
+    }
+
+    var size int
+    redisKeys := make([]string, 0, len(pending))
+    for rkey, w := range pending {
+        size += len(w.data)
+        redisKeys = append(redisKeys, rkey)
+    }
+
+    ctx, op := c.beginWrite(ctx, "Flush", redisKeys...)
+    invalid := c.invalidHook()
+    cmds := make(map[string]interface{ Result() (string, error) }, len(pending))
+
+    c.dels.mark(redisKeys...)
+    err := c.retry(ctx, func(ctx context.Context) error {
+        pipe := c.pool.Client().Pipeline()
+        for rkey, w := range pending {
+            switch {
+            case w.data == nil && invalid != nil:
+                cmds[rkey] = pipe.GetDel(ctx, rkey)
+            case w.data == nil:
+                pipe.Del(ctx, rkey)
+            case invalid != nil:
+                cmds[rkey] = pipe.SetArgs(ctx, rkey, w.data, redis.SetArgs{TTL: c.jitter(w.ttl), Get: true})
+            default:
+                pipe.Set(ctx, rkey, w.data, c.jitter(w.ttl))
+            }
+        }
+
+        // Missing old values are not errors.
+        return execErr(pipe.Exec(ctx))
+    })
+
+    op.end(err, attrPayloadSize.Int(size))
+
+    if err != nil {
+        // Requeue failed writes,
+        // unless since replaced.
+        c.wb.mu.Lock()
+        for rkey, w := range pending {
+            if _, ok := c.wb.pending[rkey]; !ok {
+                c.wb.pending[rkey] = w
+            }
+        }
+        c.wb.mu.Unlock()
+        return err
+    }
+
+    for rkey, cmd := range cmds {
+        data, err := cmd.Result()
+        if err != nil {
+            continue
+        }
+
+        var oldValue V
+        if err := c.unmarshal(rkey, []byte(data), &oldValue); err == nil {
+            invalid(pending[rkey].key, oldValue)
+        }
+    }
+
+    return nil
+}
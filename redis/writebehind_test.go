@@ -0,0 +1,146 @@
+package redis_test
+
+import (
+    "context"
+    "errors"
+    "os"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/mkc188/go-cache/v3/errs"
+    "github.com/mkc188/go-cache/v3/redis"
+)
+
+// testOptions returns options for the Redis server at $REDIS_ADDR, or if unset an in-process
+// miniredis server stopped on test cleanup. Tests use database 15, which they clear.
+func testOptions(t *testing.T) *redis.Options {
+    addr := os.Getenv("REDIS_ADDR")
+    if addr == "" {
+        addr = miniredis.RunT(t).Addr()
+    }
+    opts := redis.DefaultOptions()
+    opts.Addresses = []string{addr}
+    opts.DB = 15
+    return opts
+}
+
+// miniOptions returns options for a new in-process miniredis server, stopped on test
+// cleanup, for tests needing control of the server (e.g. stopping it, or its clock).
+func miniOptions(t *testing.T) (*miniredis.Miniredis, *redis.Options) {
+    mr := miniredis.RunT(t)
+    opts := redis.DefaultOptions()
+    opts.Addresses = []string{mr.Addr()}
+    opts.MinIdleConns = 0
+    return mr, opts
+}
+
+func TestWriteBehindCAS(t *testing.T) {
+    opts := testOptions(t)
+    opts.WriteBehindInterval = time.Hour
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+    c.Clear()
+
+    // Queued, not yet flushed.
+    c.Set("a", 1)
+
+    // CAS must see the queued value...
+    if !c.CAS("a", 1, 2, func(a, b int) bool { return a == b }) {
+        t.Fatal("CAS did not see queued write")
+    }
+
+    // ...and not be overwritten by it later.
+    if err := c.Flush(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if v, ok := c.Get("a"); !ok || v != 2 {
+        t.Fatalf("CAS result overwritten by queued write: %d %v", v, ok)
+    }
+
+    c.Set("b", 1)
+    if old, ok := c.GetSet("b", 3); !ok || old != 1 {
+        t.Fatalf("GetSet did not see queued write: %d %v", old, ok)
+    }
+
+    c.Set("c", 1)
+    if c.Add("c", 4) {
+        t.Fatal("Add succeeded over queued write")
+    }
+}
+
+func TestWriteBehindClosed(t *testing.T) {
+    opts := redis.DefaultOptions()
+    opts.WriteBehindInterval = time.Hour
+
+    c := redis.New[string, int](opts)
+    if err := c.Close(); err != nil {
+        t.Fatal(err)
+    }
+
+    // Not queued once closed.
+    if err := c.SetE("a", 1); !errors.Is(err, errs.ErrStopped) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if err := c.InvalidateE("a"); !errors.Is(err, errs.ErrStopped) {
+        t.Fatalf("unexpected error: %v", err)
+    }
+}
+
+func TestWriteBehindFlush(t *testing.T) {
+    mr, opts := miniOptions(t)
+    opts.WriteBehindInterval = time.Hour
+
+    c := redis.New[string, int](opts)
+    defer c.Close()
+
+    // Queued, served locally.
+    c.Set("a", 1)
+    c.Set("b", 2)
+    if mr.Exists("a") {
+        t.Fatal("queued write written before flush")
+    }
+    if v, ok := c.Get("a"); !ok || v != 1 {
+        t.Fatalf("queued write not served: %d %v", v, ok)
+    }
+
+    if err := c.Flush(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if v, err := mr.Get("a"); err != nil || v != "1" {
+        t.Fatalf("unexpected flushed value: %q %v", v, err)
+    }
+
+    // Queued writes are not applied
+    // after a later InvalidateAll.
+    c.Set("a", 3)
+    if !c.InvalidateAll("a", "b") {
+        t.Fatal("InvalidateAll failed")
+    }
+    if err := c.Flush(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if mr.Exists("a") || mr.Exists("b") {
+        t.Fatalf("queued write applied after invalidate: %v", mr.Keys())
+    }
+
+    // Nor after a later Clear.
+    c.Set("c", 4)
+    c.Clear()
+    if err := c.Flush(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if len(mr.Keys()) != 0 {
+        t.Fatalf("queued write applied after clear: %v", mr.Keys())
+    }
+
+    // Flushed on close.
+    c.Set("d", 5)
+    if err := c.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if v, err := mr.Get("d"); err != nil || v != "5" {
+        t.Fatalf("queued write not flushed on close: %q %v", v, err)
+    }
+}
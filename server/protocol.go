@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Protocol: each request and response is a frame consisting of a 4-byte big-endian payload length followed by
+// the payload. A request payload is an op byte followed by its arguments, a response payload is a status byte
+// followed by its results, where each argument or result is a 4-byte big-endian length followed by its bytes.
+
+// op is a request operation.
+type op byte
+
+const (
+	opPing op = iota + 1
+	opGet
+	opSet
+	opAdd
+	opHas
+	opInvalidate
+	opLen
+	opClear
+)
+
+// status is a response status.
+type status byte
+
+const (
+	// statusOK indicates success (or a true result).
+	statusOK status = iota
+
+	// statusMiss indicates a cache miss (or a false result).
+	statusMiss
+
+	// statusError indicates a failed request, with the error message as result.
+	statusError
+)
+
+// maxFrame is the maximum accepted frame payload size.
+const maxFrame = 64 << 20
+
+// ErrFrameTooLarge is returned on reading a frame exceeding the maximum payload size.
+var ErrFrameTooLarge = errors.New("server: frame too large")
+
+// writeFrame writes a frame with given leading byte and fields.
+func writeFrame(w *bufio.Writer, lead byte, fields ...[]byte) error {
+	size := 1
+	for _, f := range fields {
+		size += 4 + len(f)
+	}
+
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(size))
+	_, _ = w.Write(hdr[:])
+	_ = w.WriteByte(lead)
+
+	for _, f := range fields {
+		binary.BigEndian.PutUint32(hdr[:], uint32(len(f)))
+		_, _ = w.Write(hdr[:])
+		_, _ = w.Write(f)
+	}
+
+	return w.Flush()
+}
+
+// readFrame reads a frame, returning its leading byte and fields.
+func readFrame(r *bufio.Reader) (byte, [][]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size > maxFrame {
+		return 0, nil, ErrFrameTooLarge
+	} else if size < 1 {
+		return 0, nil, fmt.Errorf("server: empty frame")
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	lead, rest := payload[0], payload[1:]
+
+	var fields [][]byte
+	for len(rest) > 0 {
+		if len(rest) < 4 {
+			return 0, nil, fmt.Errorf("server: malformed frame")
+		}
+		n := binary.BigEndian.Uint32(rest)
+		rest = rest[4:]
+		if uint32(len(rest)) < n {
+			return 0, nil, fmt.Errorf("server: malformed frame")
+		}
+		fields = append(fields, rest[:n])
+		rest = rest[n:]
+	}
+
+	return lead, fields, nil
+}
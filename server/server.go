@@ -0,0 +1,402 @@
+// Package server exposes a cache over a simple length-prefixed binary protocol on any net.Listener
+// (e.g. TCP or unix socket), with a matching Go Client, such that sidecar processes and scripts
+// can query a shared in-process cache without embedding Go.
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Codec encodes cache values for transfer to clients, and decodes them back, e.g. redis.JSONSerializer.
+type Codec interface {
+	// Marshal encodes value v.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+// Server serves a cache over connections accepted from listeners, with values encoded as bytes.
+type Server struct {
+	store store
+	lns   map[net.Listener]struct{}
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+	done  bool
+	mu    sync.Mutex
+}
+
+// New returns a new Server serving c, with byte values passed through as-is.
+func New(c cache.Cache[string, []byte]) *Server {
+	return newServer(&codecStore[[]byte]{cache: c, codec: rawCodec{}})
+}
+
+// NewWithCodec returns a new Server serving c, with values encoded using codec.
+func NewWithCodec[V any](c cache.Cache[string, V], codec Codec) *Server {
+	return newServer(&codecStore[V]{cache: c, codec: codec})
+}
+
+// newServer returns a new Server serving st.
+func newServer(st store) *Server {
+	return &Server{
+		store: st,
+		lns:   make(map[net.Listener]struct{}),
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts and serves connections from ln until it fails or the server is closed, always returning a non-nil error.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.lns[ln] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			delete(s.lns, ln)
+			if s.done {
+				err = net.ErrClosed
+			}
+			s.mu.Unlock()
+			return err
+		}
+
+		s.mu.Lock()
+		if s.done {
+			s.mu.Unlock()
+			_ = conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// ListenAndServe listens on given network address (e.g. "tcp", ":7070" or "unix", "/run/cache.sock") and serves it.
+func (s *Server) ListenAndServe(network, addr string) error {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Close closes all listeners and connections, waiting on in-progress requests.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.done = true
+	for ln := range s.lns {
+		_ = ln.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn serves requests on conn until it is closed.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		lead, args, err := readFrame(r)
+		if err != nil {
+			return
+		}
+
+		st, results := s.handle(op(lead), args)
+		if err := writeFrame(w, byte(st), results...); err != nil {
+			return
+		}
+	}
+}
+
+// arity is the number of arguments expected by each op.
+var arity = map[op]int{
+	opPing: 0, opGet: 1, opSet: 2, opAdd: 2,
+	opHas: 1, opInvalidate: 1, opLen: 0, opClear: 0,
+}
+
+// handle performs the request op with args, returning the response status and results.
+func (s *Server) handle(o op, args [][]byte) (status, [][]byte) {
+	n, ok := arity[o]
+	if !ok {
+		return errorResult(fmt.Errorf("unknown op %d", o))
+	} else if len(args) != n {
+		return errorResult(fmt.Errorf("op %d expects %d arguments, got %d", o, n, len(args)))
+	}
+
+	switch o {
+	case opGet:
+		v, ok, err := s.store.Get(string(args[0]))
+		if err != nil {
+			return errorResult(err)
+		} else if !ok {
+			return statusMiss, nil
+		}
+		return statusOK, [][]byte{v}
+
+	case opSet:
+		if err := s.store.Set(string(args[0]), args[1]); err != nil {
+			return errorResult(err)
+		}
+		return statusOK, nil
+
+	case opAdd:
+		ok, err := s.store.Add(string(args[0]), args[1])
+		if err != nil {
+			return errorResult(err)
+		}
+		return boolResult(ok)
+
+	case opHas:
+		return boolResult(s.store.Has(string(args[0])))
+
+	case opInvalidate:
+		return boolResult(s.store.Invalidate(string(args[0])))
+
+	case opLen:
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(s.store.Len()))
+		return statusOK, [][]byte{b[:]}
+
+	case opClear:
+		s.store.Clear()
+		return statusOK, nil
+
+	default: // opPing
+		return statusOK, nil
+	}
+}
+
+// boolResult returns the response status of a boolean result.
+func boolResult(ok bool) (status, [][]byte) {
+	if ok {
+		return statusOK, nil
+	}
+	return statusMiss, nil
+}
+
+// errorResult returns the response of a failed request.
+func errorResult(err error) (status, [][]byte) {
+	return statusError, [][]byte{[]byte(err.Error())}
+}
+
+// clone returns a copy of b, as read frames are not retained.
+func clone(b []byte) []byte {
+	return append([]byte{}, b...)
+}
+
+// store is the cache served by a Server, with values in their encoded form.
+type store interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+	Add(key string, value []byte) (bool, error)
+	Has(key string) bool
+	Invalidate(key string) bool
+	Len() int
+	Clear()
+}
+
+// codecStore is a store of cache values encoded using codec.
+type codecStore[V any] struct {
+	cache cache.Cache[string, V]
+	codec Codec
+}
+
+func (st *codecStore[V]) Get(key string) ([]byte, bool, error) {
+	v, ok := st.cache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	b, err := st.codec.Marshal(v)
+	return b, err == nil, err
+}
+
+func (st *codecStore[V]) Set(key string, value []byte) error {
+	var v V
+	if err := st.codec.Unmarshal(value, &v); err != nil {
+		return err
+	}
+	st.cache.Set(key, v)
+	return nil
+}
+
+func (st *codecStore[V]) Add(key string, value []byte) (bool, error) {
+	var v V
+	if err := st.codec.Unmarshal(value, &v); err != nil {
+		return false, err
+	}
+	return st.cache.Add(key, v), nil
+}
+
+func (st *codecStore[V]) Has(key string) bool { return st.cache.Has(key) }
+
+func (st *codecStore[V]) Invalidate(key string) bool { return st.cache.Invalidate(key) }
+
+func (st *codecStore[V]) Len() int { return st.cache.Len() }
+
+func (st *codecStore[V]) Clear() { st.cache.Clear() }
+
+// rawCodec is a Codec passing []byte values through as-is.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	*b = clone(data)
+	return nil
+}
+
+// RemoteError is an error returned by the server.
+type RemoteError string
+
+func (e RemoteError) Error() string {
+	return "server: " + string(e)
+}
+
+// Client is a client of a Server. It is safe for concurrent use, requests being serialized over a single connection.
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+	mu   sync.Mutex
+}
+
+// Dial connects to the server at given network address.
+func Dial(network, addr string) (*Client, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Ping checks the connection to the server.
+func (c *Client) Ping() error {
+	_, _, err := c.do(opPing)
+	return err
+}
+
+// Get fetches the value with key.
+func (c *Client) Get(key string) ([]byte, bool, error) {
+	st, results, err := c.do(opGet, []byte(key))
+	if err != nil || st != statusOK {
+		return nil, false, err
+	} else if len(results) != 1 {
+		return nil, false, errors.New("server: malformed response")
+	}
+	return results[0], true, nil
+}
+
+// Set places the value at key.
+func (c *Client) Set(key string, value []byte) error {
+	_, _, err := c.do(opSet, []byte(key), value)
+	return err
+}
+
+// Add places the value at key, doing nothing if a value with this key already exists.
+func (c *Client) Add(key string, value []byte) (bool, error) {
+	st, _, err := c.do(opAdd, []byte(key), value)
+	return st == statusOK && err == nil, err
+}
+
+// Has checks for a value with key.
+func (c *Client) Has(key string) (bool, error) {
+	st, _, err := c.do(opHas, []byte(key))
+	return st == statusOK && err == nil, err
+}
+
+// Invalidate deletes the value with key.
+func (c *Client) Invalidate(key string) (bool, error) {
+	st, _, err := c.do(opInvalidate, []byte(key))
+	return st == statusOK && err == nil, err
+}
+
+// Len returns the current length of the cache.
+func (c *Client) Len() (int, error) {
+	_, results, err := c.do(opLen)
+	if err != nil {
+		return 0, err
+	} else if len(results) != 1 || len(results[0]) != 8 {
+		return 0, errors.New("server: malformed response")
+	}
+	return int(binary.BigEndian.Uint64(results[0])), nil
+}
+
+// Clear empties the cache.
+func (c *Client) Clear() error {
+	_, _, err := c.do(opClear)
+	return err
+}
+
+// do performs a request, returning the response status and results.
+func (c *Client) do(o op, args ...[]byte) (status, [][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := writeFrame(c.w, byte(o), args...); err != nil {
+		return 0, nil, err
+	}
+
+	lead, results, err := readFrame(c.r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if st := status(lead); st == statusError {
+		msg := "unknown error"
+		if len(results) > 0 {
+			msg = string(results[0])
+		}
+		return st, nil, RemoteError(msg)
+	}
+
+	return status(lead), results, nil
+}
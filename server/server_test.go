@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/redis"
+	"github.com/mkc188/go-cache/v3/server"
+)
+
+func TestServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := server.New(cache.New[string, []byte](0, 10))
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	c, err := server.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := c.Get("a"); ok || err != nil {
+		t.Fatalf("unexpected get result: %v %v", ok, err)
+	}
+
+	if err := c.Set("a", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := c.Get("a"); !ok || err != nil || string(v) != "1" {
+		t.Fatalf("unexpected get result: %q %v %v", v, ok, err)
+	}
+
+	if ok, _ := c.Add("a", []byte("2")); ok {
+		t.Fatal("added existing key")
+	}
+	if ok, _ := c.Has("a"); !ok {
+		t.Fatal("missing key")
+	}
+	if n, _ := c.Len(); n != 1 {
+		t.Fatalf("unexpected len: %d", n)
+	}
+	if ok, _ := c.Invalidate("a"); !ok {
+		t.Fatal("failed to invalidate")
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerCodec(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type point struct{ X, Y int }
+
+	backing := cache.New[string, point](0, 10)
+	srv := server.NewWithCodec[point](backing, redis.JSONSerializer{})
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	c, err := server.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Set("a", []byte(`{"X":1,"Y":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := backing.Get("a"); !ok || v != (point{1, 2}) {
+		t.Fatalf("unexpected stored value: %v %v", v, ok)
+	}
+	if v, ok, err := c.Get("a"); !ok || err != nil || string(v) != `{"X":1,"Y":2}` {
+		t.Fatalf("unexpected get result: %q %v %v", v, ok, err)
+	}
+
+	// Undecodable values are rejected.
+	var rerr server.RemoteError
+	if err := c.Set("b", []byte("not json")); !errors.As(err, &rerr) {
+		t.Fatalf("unexpected set error: %v", err)
+	}
+}
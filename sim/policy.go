@@ -0,0 +1,165 @@
+package sim
+
+import (
+	"container/heap"
+	"container/list"
+
+	"github.com/mkc188/go-cache/v3/simple"
+	"github.com/mkc188/go-cache/v3/tinylfu"
+)
+
+// lru is the least recently used policy, as implemented by simple.Cache.
+type lru[K comparable] struct {
+	cache *simple.Cache[K, struct{}]
+}
+
+// NewLRU returns a least recently used Policy, simulated using simple.Cache.
+func NewLRU[K comparable](cap int) Policy[K] {
+	return &lru[K]{cache: simple.New[K, struct{}](0, cap)}
+}
+
+func (p *lru[K]) Name() string { return "LRU" }
+
+func (p *lru[K]) Cap() int { return p.cache.Cap() }
+
+func (p *lru[K]) Access(key K) bool {
+	if _, ok := p.cache.Get(key); ok {
+		return true
+	}
+	p.cache.Set(key, struct{}{})
+	return false
+}
+
+// lfu is the least frequently used policy, evicting the entry with the lowest
+// access count (least recently accessed first between equal counts).
+type lfu[K comparable] struct {
+	cap   int
+	tick  uint64
+	items map[K]*lfuItem[K]
+	heap  lfuHeap[K]
+}
+
+// lfuItem is an entry in the lfu heap.
+type lfuItem[K comparable] struct {
+	key   K
+	count uint64
+	tick  uint64
+	index int
+}
+
+// NewLFU returns a least frequently used Policy.
+func NewLFU[K comparable](cap int) Policy[K] {
+	return &lfu[K]{cap: cap, items: make(map[K]*lfuItem[K], cap)}
+}
+
+func (p *lfu[K]) Name() string { return "LFU" }
+
+func (p *lfu[K]) Cap() int { return p.cap }
+
+func (p *lfu[K]) Access(key K) bool {
+	p.tick++
+
+	if item, ok := p.items[key]; ok {
+		item.count++
+		item.tick = p.tick
+		heap.Fix(&p.heap, item.index)
+		return true
+	}
+
+	if p.cap <= 0 {
+		return false
+	}
+
+	if len(p.items) >= p.cap {
+		// Evict least frequently used.
+		item := heap.Pop(&p.heap).(*lfuItem[K])
+		delete(p.items, item.key)
+	}
+
+	item := &lfuItem[K]{key: key, count: 1, tick: p.tick}
+	p.items[key] = item
+	heap.Push(&p.heap, item)
+	return false
+}
+
+// lfuHeap implements heap.Interface, ordered by count then tick.
+type lfuHeap[K comparable] []*lfuItem[K]
+
+func (h lfuHeap[K]) Len() int { return len(h) }
+
+func (h lfuHeap[K]) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+	return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap[K]) Push(x any) {
+	item := x.(*lfuItem[K])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *lfuHeap[K]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// tinyLFU is an LRU policy with TinyLFU admission, only admitting a new key
+// in place of the LRU victim if its estimated access frequency is higher.
+type tinyLFU[K comparable] struct {
+	cap    int
+	items  map[K]*list.Element
+	order  list.List
+	filter *tinylfu.Filter[K]
+}
+
+// NewTinyLFU returns an LRU Policy with TinyLFU admission, see tinylfu.Filter.
+func NewTinyLFU[K comparable](cap int) Policy[K] {
+	return &tinyLFU[K]{
+		cap:    cap,
+		items:  make(map[K]*list.Element, cap),
+		filter: tinylfu.New[K](cap),
+	}
+}
+
+func (p *tinyLFU[K]) Name() string { return "TinyLFU" }
+
+func (p *tinyLFU[K]) Cap() int { return p.cap }
+
+func (p *tinyLFU[K]) Access(key K) bool {
+	p.filter.Record(key)
+
+	if elem, ok := p.items[key]; ok {
+		p.order.MoveToFront(elem)
+		return true
+	}
+
+	if p.cap <= 0 {
+		return false
+	}
+
+	if len(p.items) >= p.cap {
+		victim := p.order.Back()
+		vkey := victim.Value.(K)
+
+		if !p.filter.Admit(key, vkey) {
+			// Reject candidate.
+			return false
+		}
+
+		p.order.Remove(victim)
+		delete(p.items, vkey)
+	}
+
+	p.items[key] = p.order.PushFront(key)
+	return false
+}
@@ -0,0 +1,76 @@
+// Package sim replays recorded key access traces against cache eviction policies of various capacities, reporting
+// hit rates, such that policy and capacity may be chosen from data before deploying changes.
+package sim
+
+import (
+	"bufio"
+	"io"
+)
+
+// Policy is a simulated cache eviction policy. Policies are not safe for concurrent use.
+type Policy[Key comparable] interface {
+	// Name returns the policy name, e.g. "LRU".
+	Name() string
+
+	// Cap returns the policy's cache capacity.
+	Cap() int
+
+	// Access records an access of key, returning whether it was a hit. On miss the key is admitted per the policy.
+	Access(key Key) bool
+}
+
+// Factory returns a new Policy with given capacity.
+type Factory[Key comparable] func(cap int) Policy[Key]
+
+// Result is the outcome of replaying a trace against a policy.
+type Result struct {
+	Policy   string
+	Capacity int
+	Hits     int
+	Misses   int
+}
+
+// HitRate returns the ratio of hits to total accesses.
+func (r Result) HitRate() float64 {
+	if total := r.Hits + r.Misses; total > 0 {
+		return float64(r.Hits) / float64(total)
+	}
+	return 0
+}
+
+// Run replays trace against p, returning the result.
+func Run[K comparable](trace []K, p Policy[K]) Result {
+	res := Result{Policy: p.Name(), Capacity: p.Cap()}
+	for _, key := range trace {
+		if p.Access(key) {
+			res.Hits++
+		} else {
+			res.Misses++
+		}
+	}
+	return res
+}
+
+// Simulate replays trace against a new policy from each factory at each capacity, returning results
+// ordered by factory then capacity.
+func Simulate[K comparable](trace []K, caps []int, factories ...Factory[K]) []Result {
+	results := make([]Result, 0, len(caps)*len(factories))
+	for _, factory := range factories {
+		for _, cap := range caps {
+			results = append(results, Run(trace, factory(cap)))
+		}
+	}
+	return results
+}
+
+// ReadTrace reads a trace of newline separated keys from r, skipping empty lines.
+func ReadTrace(r io.Reader) ([]string, error) {
+	var trace []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			trace = append(trace, key)
+		}
+	}
+	return trace, scanner.Err()
+}
@@ -0,0 +1,49 @@
+package sim_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mkc188/go-cache/v3/sim"
+)
+
+func TestSimulate(t *testing.T) {
+	// Skewed trace: a small hot set
+	// interleaved with one-off scans.
+	rng := rand.New(rand.NewSource(1))
+	var trace []int
+	for i := 0; i < 10000; i++ {
+		trace = append(trace, rng.Intn(10))
+		trace = append(trace, 100+i)
+	}
+
+	results := sim.Simulate(trace, []int{5, 20},
+		sim.NewLRU[int],
+		sim.NewLFU[int],
+		sim.NewTinyLFU[int],
+	)
+
+	if len(results) != 6 {
+		t.Fatalf("unexpected result count: %d", len(results))
+	}
+
+	for _, res := range results {
+		t.Logf("%s(%d): %.3f", res.Policy, res.Capacity, res.HitRate())
+		if res.Hits+res.Misses != len(trace) {
+			t.Fatalf("unexpected access count for %s: %d", res.Policy, res.Hits+res.Misses)
+		}
+	}
+
+	// Scan resistant policies should beat LRU.
+	if lru, tiny := results[1], results[5]; tiny.HitRate() <= lru.HitRate() {
+		t.Fatalf("TinyLFU (%.3f) did not outperform LRU (%.3f)", tiny.HitRate(), lru.HitRate())
+	}
+}
+
+func TestReadTrace(t *testing.T) {
+	trace, err := sim.ReadTrace(strings.NewReader("a\nb\n\na\n"))
+	if err != nil || strings.Join(trace, ",") != "a,b,a" {
+		t.Fatalf("unexpected trace: %v %v", trace, err)
+	}
+}
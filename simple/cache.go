@@ -1,3 +1,5 @@
+// Package simple provides a plain capacity-bounded LRU cache, with eviction and invalidate callback hooks but
+// no expiry machinery, for workloads where a sweep routine and expiry bookkeeping would be pure overhead.
 package simple
 
 import (
@@ -325,6 +327,52 @@ func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
 	return
 }
 
+// CompareAndDelete deletes the value with key from the cache only if eq reports it equal to cmp, calling the
+// invalidate callback. Returned bool is whether the value was deleted.
+func (c *Cache[K, V]) CompareAndDelete(key K, cmp V, eq func(V, V) bool) (ok bool) {
+	var (
+		// old value.
+		oldV V
+
+		// hook func ptrs.
+		invalid func(K, V)
+	)
+
+	c.locked(func() {
+		var item *Entry
+
+		// Check for item in cache
+		item, ok = c.Cache.Get(key)
+		if !ok {
+			return
+		}
+
+		// Perform the comparison
+		if ok = eq(cmp, item.Value.(V)); !ok {
+			return
+		}
+
+		// Set old value.
+		oldV = item.Value.(V)
+
+		// Remove from cache map
+		_ = c.Cache.Delete(key)
+
+		// Free entry
+		PutEntry(item)
+
+		// Set hook func ptrs.
+		invalid = c.Invalid
+	})
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	return
+}
+
 // InvalidateAll: implements cache.Cache's InvalidateAll().
 func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
 	var (
@@ -375,7 +423,7 @@ func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
 }
 
 // Clear: implements cache.Cache's Clear().
-func (c *Cache[K, V]) Clear() { c.Trim(100) }
+func (c *Cache[K, V]) Clear() { c.Trim(0) }
 
 // Trim will truncate the cache to ensure it stays within given percentage of total capacity.
 func (c *Cache[K, V]) Trim(perc float64) {
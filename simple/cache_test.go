@@ -7,6 +7,9 @@ import (
 	"time"
 	"unsafe"
 
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/cachetest"
+	"github.com/mkc188/go-cache/v3/simple"
 	"github.com/mkc188/go-cache/v3/ttl"
 	"github.com/google/go-cmp/cmp"
 )
@@ -117,3 +120,29 @@ func TestCache(t *testing.T) {
 		t.Fatalf("unexpected cache size: %d", sz)
 	}
 }
+
+func TestConformance(t *testing.T) {
+	cachetest.TestCache(t, func(cap int) cache.Cache[string, string] {
+		return simple.New[string, string](0, cap)
+	})
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	c := simple.New[string, int](0, 10)
+	c.Set("a", 1)
+
+	var invalidated int
+	c.SetInvalidateCallback(func(string, int) { invalidated++ })
+
+	eq := func(a, b int) bool { return a == b }
+
+	if c.CompareAndDelete("a", 2, eq) || !c.Has("a") {
+		t.Fatal("deleted on mismatched value")
+	}
+	if !c.CompareAndDelete("a", 1, eq) || c.Has("a") || invalidated != 1 {
+		t.Fatal("failed to delete on matched value")
+	}
+	if c.CompareAndDelete("a", 1, eq) {
+		t.Fatal("deleted missing key")
+	}
+}
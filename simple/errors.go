@@ -0,0 +1,28 @@
+package simple
+
+import (
+	"github.com/mkc188/go-cache/v3/errs"
+)
+
+// GetE performs Get(), returning errs.ErrNotFound if no value exists for key.
+func (c *Cache[K, V]) GetE(key K) (V, error) {
+	v, ok := c.Get(key)
+	if !ok {
+		return v, errs.ErrNotFound
+	}
+	return v, nil
+}
+
+// SetE performs Set(), as values are always stored (evicting the least recently used if at capacity) this never fails.
+func (c *Cache[K, V]) SetE(key K, value V) error {
+	c.Set(key, value)
+	return nil
+}
+
+// InvalidateE performs Invalidate(), returning errs.ErrNotFound if no value exists for key.
+func (c *Cache[K, V]) InvalidateE(key K) error {
+	if !c.Invalidate(key) {
+		return errs.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package snapshot provides the shared Dumper and Restorer interfaces, and codec plumbing, used by caches to
+// export and import their warm state uniformly (including each entry's remaining TTL) across cache types.
+package snapshot
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// Dumper is implemented by caches that can export their entries.
+type Dumper interface {
+	// Dump writes all (unexpired) cache entries to w.
+	Dump(w io.Writer) error
+}
+
+// Restorer is implemented by caches that can import entries.
+type Restorer interface {
+	// Restore reads entries from r into the cache, discarding those already expired.
+	Restore(r io.Reader) error
+}
+
+// Entry is a dumped cache entry.
+type Entry[Key comparable, Value any] struct {
+	Key   Key
+	Value Value
+
+	// TTL is the time remaining until the entry expires, zero if it does not expire.
+	TTL time.Duration
+}
+
+// Expired returns whether the entry had already expired when dumped.
+func (e *Entry[K, V]) Expired() bool {
+	return e.TTL < 0
+}
+
+// Encoder encodes a stream of values.
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder decodes a stream of values, returning io.EOF at the end of the stream.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec creates stream encoders and decoders.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Gob is the encoding/gob Codec, the default. Interface values must be registered with gob.Register.
+var Gob Codec = gobCodec{}
+
+// JSON is the encoding/json Codec, writing one entry object per line.
+var JSON Codec = jsonCodec{}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// Write encodes given entries to w using codec, defaulting to Gob if nil.
+func Write[K comparable, V any](w io.Writer, codec Codec, entries []Entry[K, V]) error {
+	if codec == nil {
+		codec = Gob
+	}
+	enc := codec.NewEncoder(w)
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read decodes entries from r using codec (defaulting to Gob if nil), passing each unexpired entry to fn, until end of stream.
+func Read[K comparable, V any](r io.Reader, codec Codec, fn func(Entry[K, V]) error) error {
+	if codec == nil {
+		codec = Gob
+	}
+	dec := codec.NewDecoder(r)
+	for {
+		var e Entry[K, V]
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if e.Expired() {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
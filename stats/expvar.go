@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"expvar"
+	"time"
+)
+
+// Expvar is a Recorder publishing metrics via expvar as a map under a configurable name, e.g. for
+// scraping from /debug/vars. Counts and gauges are published under their name, and timings as
+// "<name>.count" and "<name>.total_ns" counters.
+type Expvar struct {
+	vars *expvar.Map
+}
+
+// NewExpvar returns a new Expvar recorder publishing under name. If an expvar map is already
+// published under name it is shared, otherwise a new one is published.
+func NewExpvar(name string) *Expvar {
+	if m, ok := expvar.Get(name).(*expvar.Map); ok {
+		return &Expvar{vars: m}
+	}
+	return &Expvar{vars: expvar.NewMap(name)}
+}
+
+// Map returns the published expvar map.
+func (e *Expvar) Map() *expvar.Map {
+	return e.vars
+}
+
+// Count implements Recorder.
+func (e *Expvar) Count(name string, delta int64) {
+	e.vars.Add(name, delta)
+}
+
+// Gauge implements Recorder.
+func (e *Expvar) Gauge(name string, value float64) {
+	if f, ok := e.vars.Get(name).(*expvar.Float); ok {
+		f.Set(value)
+		return
+	}
+	f := new(expvar.Float)
+	f.Set(value)
+	e.vars.Set(name, f)
+}
+
+// Timing implements Recorder.
+func (e *Expvar) Timing(name string, d time.Duration) {
+	e.vars.Add(name+".count", 1)
+	e.vars.Add(name+".total_ns", int64(d))
+}
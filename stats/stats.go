@@ -0,0 +1,31 @@
+// Package stats defines the Recorder interface through which caches in this module report metrics, allowing
+// applications to plug in whichever monitoring system they use, without this module depending on any of them.
+// An Expvar recorder is provided for services already exposing /debug/vars.
+package stats
+
+import "time"
+
+// Recorder receives cache metrics. Metric names are dot separated and prefixed by the reporting package,
+// e.g. "ttl.hits" or "redis.get". Implementations must be safe for concurrent use.
+type Recorder interface {
+	// Count adds delta to the counter with name.
+	Count(name string, delta int64)
+
+	// Gauge sets the gauge with name to value.
+	Gauge(name string, value float64)
+
+	// Timing records a duration for the timer with name.
+	Timing(name string, d time.Duration)
+}
+
+// Nop is a Recorder discarding all metrics.
+type Nop struct{}
+
+// Count implements Recorder.
+func (Nop) Count(string, int64) {}
+
+// Gauge implements Recorder.
+func (Nop) Gauge(string, float64) {}
+
+// Timing implements Recorder.
+func (Nop) Timing(string, time.Duration) {}
@@ -0,0 +1,38 @@
+package stats_test
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/stats"
+)
+
+func TestExpvar(t *testing.T) {
+	var rec stats.Recorder = stats.NewExpvar("test_cache")
+
+	rec.Count("ttl.hits", 2)
+	rec.Gauge("ttl.len", 5)
+	rec.Timing("ttl.sweep", time.Millisecond)
+
+	m, ok := expvar.Get("test_cache").(*expvar.Map)
+	if !ok {
+		t.Fatal("expvar map not published")
+	}
+
+	for name, expect := range map[string]string{
+		"ttl.hits":           "2",
+		"ttl.len":            "5",
+		"ttl.sweep.count":    "1",
+		"ttl.sweep.total_ns": "1000000",
+	} {
+		if v := m.Get(name); v == nil || v.String() != expect {
+			t.Fatalf("unexpected value for %s: %v", name, v)
+		}
+	}
+
+	// Publishing again under the same name shares the map.
+	if stats.NewExpvar("test_cache").Map() != m {
+		t.Fatal("expvar map not shared")
+	}
+}
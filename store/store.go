@@ -0,0 +1,88 @@
+// Package store provides Cached, wrapping a backing store with a cache to implement read-through,
+// write-through and delete-through in one place instead of throughout application code.
+package store
+
+import (
+	"context"
+	"errors"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/flight"
+)
+
+// ErrNotFound should be returned by Store implementations' Get when no value exists for key.
+var ErrNotFound = errors.New("store: not found")
+
+// Store is a backing store of values, e.g. a database.
+type Store[Key comparable, Value any] interface {
+	// Get fetches the value with key, returning ErrNotFound if none exists.
+	Get(ctx context.Context, key Key) (Value, error)
+
+	// Put stores the value at key.
+	Put(ctx context.Context, key Key, value Value) error
+
+	// Delete deletes the value with key.
+	Delete(ctx context.Context, key Key) error
+}
+
+// Cached wraps a Store with a cache. Reads are served from the cache, falling through to the store on
+// miss (with concurrent misses for a key coalesced) and populating the cache. Writes and deletes are
+// applied to the store first, then to the cache only on success, such that the cache never holds a
+// value the store does not.
+type Cached[Key comparable, Value any] struct {
+	store Store[Key, Value]
+	cache cache.Cache[Key, Value]
+	loads flight.Group[Key, Value]
+}
+
+// New returns a new Cached wrapping s with c.
+func New[K comparable, V any](s Store[K, V], c cache.Cache[K, V]) *Cached[K, V] {
+	return &Cached[K, V]{store: s, cache: c}
+}
+
+// Cache returns the underlying cache.
+func (c *Cached[K, V]) Cache() cache.Cache[K, V] {
+	return c.cache
+}
+
+// Get fetches the value with key from the cache, or on miss from the store (read-through), caching it.
+func (c *Cached[K, V]) Get(ctx context.Context, key K) (V, error) {
+	if value, ok := c.cache.Get(key); ok {
+		return value, nil
+	}
+
+	value, err, _ := c.loads.DoContext(ctx, key, func(ctx context.Context) (V, error) {
+		value, err := c.store.Get(ctx, key)
+		if err != nil {
+			return value, err
+		}
+		c.cache.Set(key, value)
+		return value, nil
+	})
+
+	return value, err
+}
+
+// Put stores the value at key in the store, then in the cache (write-through).
+func (c *Cached[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.store.Put(ctx, key, value); err != nil {
+		// Drop possibly stale value.
+		c.cache.Invalidate(key)
+		return err
+	}
+	c.cache.Set(key, value)
+	return nil
+}
+
+// Delete deletes the value with key from the store, and the cache (delete-through).
+func (c *Cached[K, V]) Delete(ctx context.Context, key K) error {
+	err := c.store.Delete(ctx, key)
+	c.cache.Invalidate(key)
+	return err
+}
+
+// Refresh drops any cached value for key, and reads it through from the store.
+func (c *Cached[K, V]) Refresh(ctx context.Context, key K) (V, error) {
+	c.cache.Invalidate(key)
+	return c.Get(ctx, key)
+}
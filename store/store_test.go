@@ -0,0 +1,64 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/store"
+)
+
+type mapStore struct {
+	m    map[string]int
+	gets int
+}
+
+func (s *mapStore) Get(_ context.Context, key string) (int, error) {
+	s.gets++
+	v, ok := s.m[key]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *mapStore) Put(_ context.Context, key string, value int) error {
+	s.m[key] = value
+	return nil
+}
+
+func (s *mapStore) Delete(_ context.Context, key string) error {
+	delete(s.m, key)
+	return nil
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	s := &mapStore{m: map[string]int{"a": 1}}
+	c := store.New[string, int](s, cache.New[string, int](0, 10))
+
+	for i := 0; i < 2; i++ {
+		if v, err := c.Get(ctx, "a"); err != nil || v != 1 {
+			t.Fatalf("unexpected read-through result: %d %v", v, err)
+		}
+	}
+	if s.gets != 1 {
+		t.Fatalf("expected single store read, got %d", s.gets)
+	}
+
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if err := c.Put(ctx, "b", 2); err != nil || s.m["b"] != 2 || !c.Cache().Has("b") {
+		t.Fatal("write-through not applied to store and cache")
+	}
+
+	if err := c.Delete(ctx, "a"); err != nil || c.Cache().Has("a") {
+		t.Fatal("delete-through not applied to cache")
+	}
+	if _, ok := s.m["a"]; ok {
+		t.Fatal("delete-through not applied to store")
+	}
+}
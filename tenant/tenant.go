@@ -0,0 +1,382 @@
+// Package tenant provides a Manager of per-tenant logical caches over a single shared store, with per-tenant capacity
+// quotas, isolated Clear and stats, and lazy creation and idle eviction of tenants, for multi-tenant deployments.
+package tenant
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/simple"
+	"github.com/mkc188/go-cache/v3/ttl"
+)
+
+// Options provides configuration for a Manager.
+type Options struct {
+	// Capacity is the capacity of the store shared by all tenants, a Capacity <= 0 is bounded only by tenant quotas.
+	Capacity int
+
+	// Quota is the default per-tenant capacity, a Quota <= 0 is unlimited (bounded only by Capacity).
+	Quota int
+
+	// Quotas overrides Quota for specific tenants.
+	Quotas map[string]int
+
+	// TTL is the cache item TTL.
+	TTL time.Duration
+
+	// IdleTimeout is the time after which tenants not accessed via Manager.Tenant are dropped, an IdleTimeout <= 0 never drops.
+	IdleTimeout time.Duration
+}
+
+// Stats are the access statistics of a tenant.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	Len    int
+	Cap    int
+}
+
+// key is a tenant's key in the shared store.
+type key[K comparable] struct {
+	tenant string
+	key    K
+}
+
+// Tenant is a tenant's logical cache within the store shared by all tenants of a Manager. A Tenant must not be
+// used once dropped, callers should fetch it via Manager.Tenant for each use.
+type Tenant[Key comparable, Value any] struct {
+	store *ttl.Cache[key[Key], Value]
+
+	// keys indexes the tenant's keys in the store in LRU
+	// order, evicting beyond the tenant quota from both.
+	keys *simple.Cache[Key, struct{}]
+
+	id     string
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	last   atomic.Int64
+
+	// mu serializes writes,
+	// keeping keys in sync.
+	mu sync.Mutex
+}
+
+// ID returns the tenant ID.
+func (t *Tenant[K, V]) ID() string {
+	return t.id
+}
+
+// Get: implements cache.Cache's Get(), recording hit / miss stats.
+func (t *Tenant[K, V]) Get(k K) (V, bool) {
+	v, ok := t.store.Get(key[K]{t.id, k})
+	if ok {
+		// Mark recently used.
+		_, _ = t.keys.Get(k)
+		t.hits.Add(1)
+	} else {
+		t.misses.Add(1)
+	}
+	return v, ok
+}
+
+// Add: implements cache.Cache's Add().
+func (t *Tenant[K, V]) Add(k K, v V) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.store.Add(key[K]{t.id, k}, v) {
+		return false
+	}
+	t.keys.Set(k, struct{}{})
+	return true
+}
+
+// Set: implements cache.Cache's Set().
+func (t *Tenant[K, V]) Set(k K, v V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store.Set(key[K]{t.id, k}, v)
+	t.keys.Set(k, struct{}{})
+}
+
+// CAS: implements cache.Cache's CAS().
+func (t *Tenant[K, V]) CAS(k K, old V, new V, cmp func(V, V) bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.store.CAS(key[K]{t.id, k}, old, new, cmp)
+}
+
+// Swap: implements cache.Cache's Swap().
+func (t *Tenant[K, V]) Swap(k K, swp V) V {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.store.Swap(key[K]{t.id, k}, swp)
+	t.keys.Set(k, struct{}{})
+	return old
+}
+
+// Has: implements cache.Cache's Has().
+func (t *Tenant[K, V]) Has(k K) bool {
+	return t.store.Has(key[K]{t.id, k})
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (t *Tenant[K, V]) Invalidate(k K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys.Invalidate(k)
+	return t.store.Invalidate(key[K]{t.id, k})
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll().
+func (t *Tenant[K, V]) InvalidateAll(ks ...K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.invalidateAll(ks)
+}
+
+// Clear: implements cache.Cache's Clear(), invalidating only this tenant's entries.
+func (t *Tenant[K, V]) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Gather all indexed keys.
+	t.keys.Lock()
+	ks := make([]K, 0, t.keys.Cache.Len())
+	t.keys.Cache.Range(0, t.keys.Cache.Len(), func(_ int, k K, _ *simple.Entry) {
+		ks = append(ks, k)
+	})
+	t.keys.Unlock()
+
+	t.invalidateAll(ks)
+}
+
+// invalidateAll invalidates keys from the index and store (NOTE: requires t.mu).
+func (t *Tenant[K, V]) invalidateAll(ks []K) bool {
+	sks := make([]key[K], len(ks))
+	for i, k := range ks {
+		sks[i] = key[K]{t.id, k}
+	}
+	t.keys.InvalidateAll(ks...)
+	return t.store.InvalidateAll(sks...)
+}
+
+// Len: implements cache.Cache's Len().
+func (t *Tenant[K, V]) Len() int {
+	return t.keys.Len()
+}
+
+// Cap: implements cache.Cache's Cap(), returning the tenant quota, or -1 if unlimited.
+func (t *Tenant[K, V]) Cap() int {
+	if cap := t.keys.Cap(); cap != math.MaxInt {
+		return cap
+	}
+	return -1
+}
+
+// Stats returns the tenant's current stats.
+func (t *Tenant[K, V]) Stats() Stats {
+	return Stats{
+		Hits:   t.hits.Load(),
+		Misses: t.misses.Load(),
+		Len:    t.Len(),
+		Cap:    t.Cap(),
+	}
+}
+
+// Manager manages lazily created tenant caches, over a single store shared by all tenants.
+type Manager[Key comparable, Value any] struct {
+	opts    Options
+	store   *ttl.Cache[key[Key], Value]
+	tenants map[string]*Tenant[Key, Value]
+	stop    chan struct{}
+	mu      sync.Mutex
+}
+
+// NewManager returns a new Manager with given options.
+func NewManager[K comparable, V any](opts Options) *Manager[K, V] {
+	cap := opts.Capacity
+	if cap <= 0 {
+		// Bounded by quotas only.
+		cap = math.MaxInt
+	}
+
+	m := &Manager[K, V]{
+		opts:    opts,
+		store:   ttl.New[key[K], V](0, cap, opts.TTL),
+		tenants: make(map[string]*Tenant[K, V]),
+	}
+
+	// Drop keys evicted or expired
+	// from the store from their index.
+	m.store.SetEvictionCallback(m.evicted)
+
+	return m
+}
+
+// Tenant returns the cache of tenant with ID, creating it if necessary, and marking it as accessed.
+func (m *Manager[K, V]) Tenant(id string) *Tenant[K, V] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tenants[id]
+	if !ok {
+		quota, ok := m.opts.Quotas[id]
+		if !ok {
+			quota = m.opts.Quota
+		}
+		if quota <= 0 {
+			// Unlimited.
+			quota = math.MaxInt
+		}
+		t = &Tenant[K, V]{
+			store: m.store,
+			keys:  simple.New[K, struct{}](0, quota),
+			id:    id,
+		}
+
+		// Evict beyond quota from the store.
+		t.keys.SetEvictionCallback(func(k K, _ struct{}) {
+			t.store.Invalidate(key[K]{t.id, k})
+		})
+
+		m.tenants[id] = t
+	}
+
+	// Mark accessed under lock, so
+	// Sweep cannot drop it as idle.
+	t.last.Store(time.Now().UnixNano())
+
+	return t
+}
+
+// Drop drops the cache of tenant with ID, invalidating its contents, returning whether it existed.
+func (m *Manager[K, V]) Drop(id string) bool {
+	m.mu.Lock()
+	t, ok := m.tenants[id]
+	delete(m.tenants, id)
+	m.mu.Unlock()
+
+	if ok {
+		t.Clear()
+	}
+
+	return ok
+}
+
+// Tenants returns the sorted IDs of all current tenants.
+func (m *Manager[K, V]) Tenants() []string {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+	sort.Strings(ids)
+	return ids
+}
+
+// Stats returns the stats of all current tenants.
+func (m *Manager[K, V]) Stats() map[string]Stats {
+	stats := make(map[string]Stats)
+	for _, t := range m.list() {
+		stats[t.id] = t.Stats()
+	}
+	return stats
+}
+
+// Start will start the background routine sweeping expired items from all tenants and dropping idle tenants,
+// with given frequency. If already running or a freq <= 0 provided, this is a no-op.
+func (m *Manager[K, V]) Start(freq time.Duration) (ok bool) {
+	if freq <= 0 {
+		return false
+	}
+
+	m.mu.Lock()
+	if ok = (m.stop == nil); ok {
+		m.stop = make(chan struct{})
+		go m.run(m.stop, freq)
+	}
+	m.mu.Unlock()
+
+	return
+}
+
+// Stop will stop the background routine. If not running this is a no-op.
+func (m *Manager[K, V]) Stop() (ok bool) {
+	m.mu.Lock()
+	if ok = (m.stop != nil); ok {
+		close(m.stop)
+		m.stop = nil
+	}
+	m.mu.Unlock()
+	return
+}
+
+// Sweep sweeps expired items from all tenants, and drops tenants idle beyond IdleTimeout.
+func (m *Manager[K, V]) Sweep(now time.Time) {
+	m.store.Sweep(now)
+
+	if m.opts.IdleTimeout <= 0 {
+		return
+	}
+
+	cutoff := now.Add(-m.opts.IdleTimeout).UnixNano()
+
+	var idle []*Tenant[K, V]
+
+	m.mu.Lock()
+	for id, t := range m.tenants {
+		if t.last.Load() < cutoff {
+			idle = append(idle, t)
+			delete(m.tenants, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, t := range idle {
+		// Drop contents.
+		t.Clear()
+	}
+}
+
+// evicted drops key evicted from the store from its tenant's index.
+func (m *Manager[K, V]) evicted(k key[K], _ V) {
+	m.mu.Lock()
+	t, ok := m.tenants[k.tenant]
+	m.mu.Unlock()
+
+	// Check it was not stored
+	// again since eviction.
+	if ok && !m.store.Has(k) {
+		t.keys.Invalidate(k.key)
+	}
+}
+
+// run calls Sweep at freq until stopped.
+func (m *Manager[K, V]) run(stop chan struct{}, freq time.Duration) {
+	ticker := time.NewTicker(freq)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			m.Sweep(now)
+		}
+	}
+}
+
+// list returns all current tenants.
+func (m *Manager[K, V]) list() []*Tenant[K, V] {
+	m.mu.Lock()
+	ts := make([]*Tenant[K, V], 0, len(m.tenants))
+	for _, t := range m.tenants {
+		ts = append(ts, t)
+	}
+	m.mu.Unlock()
+	return ts
+}
@@ -0,0 +1,99 @@
+package tenant_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/tenant"
+)
+
+func TestManager(t *testing.T) {
+	m := tenant.NewManager[string, int](tenant.Options{
+		Quota:       2,
+		Quotas:      map[string]int{"big": 10},
+		TTL:         time.Minute,
+		IdleTimeout: time.Minute,
+	})
+
+	a := m.Tenant("a")
+	for i, key := range []string{"x", "y", "z"} {
+		a.Set(key, i)
+	}
+	if a.Len() != 2 {
+		t.Fatalf("quota not applied: len=%d", a.Len())
+	}
+	if m.Tenant("big").Cap() != 10 {
+		t.Fatal("quota override not applied")
+	}
+
+	b := m.Tenant("b")
+	b.Set("x", 1)
+	a.Clear()
+	if !b.Has("x") {
+		t.Fatal("clear not isolated to tenant")
+	}
+
+	b.Get("x")
+	b.Get("missing")
+	if s := m.Stats()["b"]; s.Hits != 1 || s.Misses != 1 || s.Len != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	// Drop idle tenants.
+	m.Sweep(time.Now().Add(time.Hour))
+	if len(m.Tenants()) != 0 {
+		t.Fatalf("idle tenants not dropped: %v", m.Tenants())
+	}
+}
+
+func TestManagerShared(t *testing.T) {
+	m := tenant.NewManager[string, int](tenant.Options{
+		Capacity: 3,
+		Quota:    2,
+		TTL:      time.Minute,
+	})
+
+	a, b := m.Tenant("a"), m.Tenant("b")
+	a.Set("x", 1)
+	a.Set("y", 2)
+	b.Set("x", 3)
+
+	// Keys are isolated per tenant.
+	if v, _ := a.Get("x"); v != 1 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+	if v, _ := b.Get("x"); v != 3 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+
+	// Quota evicts the tenant's least recently used.
+	a.Set("z", 4)
+	if a.Has("y") || !a.Has("x") || !b.Has("x") {
+		t.Fatal("quota evicted wrong key")
+	}
+
+	// Shared capacity evicts across tenants.
+	b.Set("y", 5)
+	if a.Len()+b.Len() != 3 {
+		t.Fatalf("shared capacity not applied: len=%d", a.Len()+b.Len())
+	}
+
+	// Dropped tenants' contents are invalidated.
+	m.Drop("b")
+	if m.Tenant("b").Has("y") {
+		t.Fatal("dropped tenant contents remain")
+	}
+}
+
+func TestManagerUnlimited(t *testing.T) {
+	m := tenant.NewManager[string, int](tenant.Options{})
+
+	a := m.Tenant("a")
+	for i := 0; i < 100; i++ {
+		a.Set(strconv.Itoa(i), i)
+	}
+	if a.Len() != 100 || a.Cap() != -1 {
+		t.Fatalf("unexpected unlimited tenant: len=%d cap=%d", a.Len(), a.Cap())
+	}
+}
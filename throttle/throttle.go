@@ -0,0 +1,151 @@
+// Package throttle provides a loader wrapper bounding the rate and concurrency of loader invocations on cache misses,
+// queuing or failing fast beyond the budget, such that a cold cache cannot overwhelm the origin it loads from.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// ErrLimited is returned by a FailFast Loader when a load would exceed its rate or concurrency budget.
+var ErrLimited = errors.New("throttle: load budget exceeded")
+
+// Options provides configuration for a Loader.
+type Options struct {
+	// Rate is the maximum sustained loader invocations per second, a Rate <= 0 is unlimited.
+	Rate float64
+
+	// Burst is the maximum number of loader invocations allowed at once above Rate, defaulting to 1.
+	Burst int
+
+	// MaxInFlight is the maximum number of concurrent loader invocations, a MaxInFlight <= 0 is unlimited.
+	MaxInFlight int
+
+	// FailFast returns ErrLimited instead of queuing loads beyond the budget.
+	FailFast bool
+}
+
+// Loader wraps a load function, bounding its invocation rate (via token bucket) and concurrency.
+type Loader[Key comparable, Value any] struct {
+	load   func(context.Context, Key) (Value, error)
+	opts   Options
+	sem    chan struct{}
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// New returns a new Loader wrapping load.
+func New[K comparable, V any](load func(context.Context, K) (V, error), opts Options) *Loader[K, V] {
+	if opts.Burst <= 0 {
+		// Default size
+		opts.Burst = 1
+	}
+
+	l := &Loader[K, V]{
+		load:   load,
+		opts:   opts,
+		tokens: float64(opts.Burst),
+		last:   time.Now(),
+	}
+
+	if opts.MaxInFlight > 0 {
+		l.sem = make(chan struct{}, opts.MaxInFlight)
+	}
+
+	return l
+}
+
+// Load invokes the wrapped load function for key within the rate and concurrency budget.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	var zero V
+
+	if err := l.wait(ctx); err != nil {
+		return zero, err
+	}
+
+	if l.sem != nil {
+		if l.opts.FailFast {
+			select {
+			case l.sem <- struct{}{}:
+			default:
+				return zero, ErrLimited
+			}
+		} else {
+			select {
+			case l.sem <- struct{}{}:
+			case <-ctx.Done():
+				return zero, ctx.Err()
+			}
+		}
+		defer func() { <-l.sem }()
+	}
+
+	return l.load(ctx, key)
+}
+
+// Get fetches the value with key from c, or on miss loads it within the budget and stores it in c.
+func (l *Loader[K, V]) Get(ctx context.Context, c cache.Cache[K, V], key K) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	value, err := l.Load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	c.Set(key, value)
+	return value, nil
+}
+
+// wait takes a token from the bucket, waiting for one to become available unless FailFast.
+func (l *Loader[K, V]) wait(ctx context.Context) error {
+	if l.opts.Rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	// Refill tokens since last.
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.opts.Rate
+	if max := float64(l.opts.Burst); l.tokens > max {
+		l.tokens = max
+	}
+	l.last = now
+
+	if l.tokens < 1 && l.opts.FailFast {
+		l.mu.Unlock()
+		return ErrLimited
+	}
+
+	// Reserve token, possibly
+	// going into token debt.
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.opts.Rate * float64(time.Second))
+
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Return reserved token.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
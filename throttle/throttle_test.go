@@ -0,0 +1,45 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/throttle"
+)
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+	load := func(_ context.Context, key string) (string, error) { return key, nil }
+
+	// Fail fast beyond burst.
+	l := throttle.New(load, throttle.Options{Rate: 1, Burst: 2, FailFast: true})
+	for i := 0; i < 2; i++ {
+		if _, err := l.Load(ctx, "a"); err != nil {
+			t.Fatalf("unexpected error within burst: %v", err)
+		}
+	}
+	if _, err := l.Load(ctx, "a"); !errors.Is(err, throttle.ErrLimited) {
+		t.Fatalf("expected limited error, got %v", err)
+	}
+
+	// Queue beyond burst.
+	l = throttle.New(load, throttle.Options{Rate: 100, Burst: 1})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := l.Load(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) < time.Millisecond*15 {
+		t.Fatal("loads not rate limited")
+	}
+
+	// Cache read-through.
+	c := cache.New[string, string](0, 10)
+	if v, err := l.Get(ctx, c, "b"); err != nil || v != "b" || !c.Has("b") {
+		t.Fatalf("unexpected read-through result: %q %v", v, err)
+	}
+}
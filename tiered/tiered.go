@@ -0,0 +1,252 @@
+// Package tiered provides a cache combinator chaining multiple cache.Cache implementations as tiers, e.g. L1 memory, L2 disk and L3 redis.
+package tiered
+
+import (
+	"sync"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Policy determines how writes are applied to a tier.
+type Policy int
+
+const (
+	// WriteThrough applies writes to the tier synchronously.
+	WriteThrough Policy = iota
+
+	// WriteAround skips writes to the tier, instead invalidating its value, such that it is only populated by read backfill.
+	WriteAround
+
+	// WriteBehind applies writes and invalidations to the tier asynchronously, in order, from a background routine.
+	WriteBehind
+)
+
+// queueSize is the size of the write-behind queue, beyond which writers block.
+const queueSize = 1024
+
+// Tier is a cache tier with its write policy.
+type Tier[Key comparable, Value any] struct {
+	Cache  cache.Cache[Key, Value]
+	Policy Policy
+}
+
+// Cache chains an ordered list of tiers, the first being checked first and the last being the most authoritative.
+// Reads fall through the tiers, backfilling all earlier tiers on a hit. Writes are applied per each tier's Policy,
+// and invalidations are applied synchronously to all tiers except WriteBehind tiers, to which they are queued in
+// order with writes. Operations spanning multiple tiers are not atomic.
+type Cache[Key comparable, Value any] struct {
+	tiers []Tier[Key, Value]
+	queue chan func()
+	done  chan struct{}
+
+	// closed indicates the write-behind
+	// queue is closed, guarded by mu.
+	closed bool
+	mu     sync.RWMutex
+}
+
+// New returns a new Cache chaining given tiers, which must be non-empty.
+func New[K comparable, V any](tiers ...Tier[K, V]) *Cache[K, V] {
+	if len(tiers) == 0 {
+		panic("tiered: no tiers")
+	}
+
+	c := &Cache[K, V]{tiers: tiers}
+
+	for i := range tiers {
+		if tiers[i].Policy != WriteBehind {
+			continue
+		}
+
+		// Start write-behind routine.
+		c.queue = make(chan func(), queueSize)
+		c.done = make(chan struct{})
+		go func() {
+			defer close(c.done)
+			for fn := range c.queue {
+				fn()
+			}
+		}()
+		break
+	}
+
+	return c
+}
+
+// Close stops the write-behind routine (if any tier is WriteBehind), blocking until all queued writes are applied.
+// Later writes are applied synchronously.
+func (c *Cache[K, V]) Close() {
+	if c.queue == nil {
+		// No routine.
+		return
+	}
+
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+	<-c.done
+}
+
+// SetEvictionCallback: implements cache.Cache's SetEvictionCallback(), set on the last tier.
+func (c *Cache[K, V]) SetEvictionCallback(hook func(K, V)) {
+	c.last().SetEvictionCallback(hook)
+}
+
+// SetInvalidateCallback: implements cache.Cache's SetInvalidateCallback(), set on the last tier.
+func (c *Cache[K, V]) SetInvalidateCallback(hook func(K, V)) {
+	c.last().SetInvalidateCallback(hook)
+}
+
+// Get: implements cache.Cache's Get(), backfilling earlier tiers on a hit.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	for i := range c.tiers {
+		v, ok := c.tiers[i].Cache.Get(key)
+		if !ok {
+			continue
+		}
+
+		// Backfill earlier tiers.
+		for j := 0; j < i; j++ {
+			c.tiers[j].Cache.Set(key, v)
+		}
+
+		return v, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Add: implements cache.Cache's Add(), succeeding if no tier has a value with key.
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	if c.Has(key) {
+		return false
+	}
+	c.write(key, func(cc cache.Cache[K, V]) { cc.Set(key, value) })
+	return true
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.write(key, func(cc cache.Cache[K, V]) { cc.Set(key, value) })
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	cur, ok := c.Get(key)
+	if !ok || !cmp(old, cur) {
+		return false
+	}
+	c.Set(key, new)
+	return true
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) V {
+	old, _ := c.Get(key)
+	c.Set(key, swp)
+	return old
+}
+
+// Has: implements cache.Cache's Has().
+func (c *Cache[K, V]) Has(key K) bool {
+	for i := range c.tiers {
+		if c.tiers[i].Cache.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate: implements cache.Cache's Invalidate(). For WriteBehind tiers, the
+// result is whether a value existed (or was queued) at the time of queueing.
+func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
+	for i := range c.tiers {
+		tier := c.tiers[i]
+		if tier.Policy == WriteBehind {
+			ok = tier.Cache.Has(key) || ok
+			c.behind(func() { tier.Cache.Invalidate(key) })
+		} else if tier.Cache.Invalidate(key) {
+			ok = true
+		}
+	}
+	return
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll(), see Invalidate().
+func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
+	for i := range c.tiers {
+		tier := c.tiers[i]
+		if tier.Policy == WriteBehind {
+			for _, key := range keys {
+				ok = tier.Cache.Has(key) || ok
+			}
+			c.behind(func() { tier.Cache.InvalidateAll(keys...) })
+		} else if tier.Cache.InvalidateAll(keys...) {
+			ok = true
+		}
+	}
+	return
+}
+
+// Clear: implements cache.Cache's Clear().
+func (c *Cache[K, V]) Clear() {
+	for i := range c.tiers {
+		tier := c.tiers[i]
+		if tier.Policy == WriteBehind {
+			c.behind(tier.Cache.Clear)
+		} else {
+			tier.Cache.Clear()
+		}
+	}
+}
+
+// Len: implements cache.Cache's Len(), of the last tier.
+func (c *Cache[K, V]) Len() int {
+	return c.last().Len()
+}
+
+// Cap: implements cache.Cache's Cap(), of the last tier.
+func (c *Cache[K, V]) Cap() int {
+	return c.last().Cap()
+}
+
+// write applies given write to each tier according to its policy.
+func (c *Cache[K, V]) write(key K, fn func(cache.Cache[K, V])) {
+	for i := range c.tiers {
+		tier := c.tiers[i]
+
+		switch tier.Policy {
+		case WriteAround:
+			// Drop any stale value.
+			tier.Cache.Invalidate(key)
+
+		case WriteBehind:
+			c.behind(func() { fn(tier.Cache) })
+
+		default:
+			fn(tier.Cache)
+		}
+	}
+}
+
+// behind queues fn to the write-behind routine, blocking while the queue is full so queued operations are
+// applied in order. Once closed, fn is applied synchronously.
+func (c *Cache[K, V]) behind(fn func()) {
+	c.mu.RLock()
+	if c.closed {
+		// Queue closed, apply now.
+		fn()
+	} else {
+		c.queue <- fn
+	}
+	c.mu.RUnlock()
+}
+
+// last returns the last, most authoritative, tier's cache.
+func (c *Cache[K, V]) last() cache.Cache[K, V] {
+	return c.tiers[len(c.tiers)-1].Cache
+}
@@ -0,0 +1,72 @@
+package tiered_test
+
+import (
+	"testing"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/tiered"
+)
+
+func TestCache(t *testing.T) {
+	l1 := cache.New[string, int](0, 10)
+	l2 := cache.New[string, int](0, 10)
+	l3 := cache.New[string, int](0, 10)
+
+	c := tiered.New(
+		tiered.Tier[string, int]{Cache: l1, Policy: tiered.WriteAround},
+		tiered.Tier[string, int]{Cache: l2, Policy: tiered.WriteBehind},
+		tiered.Tier[string, int]{Cache: l3, Policy: tiered.WriteThrough},
+	)
+
+	c.Set("a", 1)
+	if l1.Has("a") || !l3.Has("a") {
+		t.Fatal("unexpected write policy result")
+	}
+
+	// Flush write-behind queue.
+	c.Close()
+	if v, ok := l2.Get("a"); !ok || v != 1 {
+		t.Fatal("write-behind tier not written")
+	}
+
+	// Ensure read backfills earlier tiers.
+	l2.Invalidate("a")
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatal("failed to read from last tier")
+	}
+	if !l1.Has("a") || !l2.Has("a") {
+		t.Fatal("earlier tiers not backfilled")
+	}
+
+	if !c.Invalidate("a") || c.Has("a") {
+		t.Fatal("failed to invalidate all tiers")
+	}
+}
+
+func TestWriteBehindOrder(t *testing.T) {
+	l1 := cache.New[string, int](0, 10)
+	l2 := cache.New[string, int](0, 10)
+
+	c := tiered.New(
+		tiered.Tier[string, int]{Cache: l1, Policy: tiered.WriteBehind},
+		tiered.Tier[string, int]{Cache: l2, Policy: tiered.WriteThrough},
+	)
+
+	for i := 0; i < 5000; i++ {
+		c.Set("a", i)
+	}
+	c.Invalidate("a")
+	c.Set("b", 1)
+	c.Clear()
+	c.Set("c", 1)
+
+	// Flush write-behind queue.
+	c.Close()
+
+	if l1.Has("a") || l1.Has("b") || !l1.Has("c") {
+		t.Fatal("write-behind operations applied out of order")
+	}
+
+	// No write-behind routine to close.
+	tiered.New(tiered.Tier[string, int]{Cache: l2}).Close()
+}
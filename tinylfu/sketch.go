@@ -0,0 +1,78 @@
+package tinylfu
+
+// sketchDepth is the number of count-min sketch rows.
+const sketchDepth = 4
+
+// Sketch is a count-min sketch of 8-bit counters, halving all
+// counters after a sample period such that frequencies age.
+// It is not safe for concurrent use.
+type Sketch struct {
+	rows   [sketchDepth][]uint8
+	mask   uint64
+	adds   int
+	sample int
+}
+
+// NewSketch returns a new Sketch sized for a cache of given capacity.
+func NewSketch(cap int) *Sketch {
+	width := 64
+	for width < cap {
+		width <<= 1
+	}
+
+	s := &Sketch{
+		mask:   uint64(width - 1),
+		sample: width * 10,
+	}
+
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+
+	return s
+}
+
+// Add increments the counters of key hash h.
+func (s *Sketch) Add(h uint64) {
+	for i := range s.rows {
+		idx := s.index(h, i)
+		if s.rows[i][idx] < 255 {
+			s.rows[i][idx]++
+		}
+	}
+
+	if s.adds++; s.adds >= s.sample {
+		s.reset()
+	}
+}
+
+// Estimate returns the estimated frequency of key hash h.
+func (s *Sketch) Estimate(h uint64) uint8 {
+	est := uint8(255)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < est {
+			est = c
+		}
+	}
+	return est
+}
+
+// reset halves all counters.
+func (s *Sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.adds /= 2
+}
+
+// index returns the counter index of key hash h in row i, by mixing h with the row number
+// (splitmix64 finalizer), such that keys colliding in one row are unlikely to in others.
+func (s *Sketch) index(h uint64, i int) uint64 {
+	x := h + uint64(i+1)*0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return x & s.mask
+}
@@ -0,0 +1,42 @@
+// Package tinylfu provides a TinyLFU admission filter, estimating key access frequencies using a count-min sketch,
+// such that a cache only admits a new key in place of its eviction victim if the new key is accessed more often.
+// This prevents high-churn one-hit-wonder keys from evicting valuable entries.
+package tinylfu
+
+import (
+	"fmt"
+	"hash/maphash"
+)
+
+// Filter is a TinyLFU admission filter. It is not safe for concurrent use.
+type Filter[Key comparable] struct {
+	// Hash returns the hash of a key, defaulting to hashing its fmt.Sprint representation.
+	Hash func(Key) uint64
+
+	// sketch is the access frequency sketch.
+	sketch *Sketch
+}
+
+// seed is the hash seed of default key hashing.
+var seed = maphash.MakeSeed()
+
+// New returns a new Filter sized for a cache of given capacity.
+func New[K comparable](cap int) *Filter[K] {
+	return &Filter[K]{
+		Hash: func(key K) uint64 {
+			return maphash.String(seed, fmt.Sprint(key))
+		},
+		sketch: NewSketch(cap),
+	}
+}
+
+// Record records an access of key, which should be called on every cache lookup, hit or miss.
+func (f *Filter[K]) Record(key K) {
+	f.sketch.Add(f.Hash(key))
+}
+
+// Admit returns whether candidate should be admitted in place of evicting victim,
+// i.e. whether candidate's estimated access frequency is higher than victim's.
+func (f *Filter[K]) Admit(candidate, victim K) bool {
+	return f.sketch.Estimate(f.Hash(candidate)) > f.sketch.Estimate(f.Hash(victim))
+}
@@ -0,0 +1,23 @@
+package tinylfu_test
+
+import (
+	"testing"
+
+	"github.com/mkc188/go-cache/v3/tinylfu"
+)
+
+func TestFilter(t *testing.T) {
+	f := tinylfu.New[string](100)
+
+	for i := 0; i < 10; i++ {
+		f.Record("hot")
+	}
+	f.Record("cold")
+
+	if !f.Admit("hot", "cold") {
+		t.Fatal("frequent candidate not admitted")
+	}
+	if f.Admit("cold", "hot") {
+		t.Fatal("infrequent candidate admitted")
+	}
+}
@@ -0,0 +1,163 @@
+package ttl
+
+// Warm places all given entries in the cache as Set(), under a single lock acquisition and expiry computation
+// (unless Jitter is set), for priming the cache at startup. The admission filter (if any) is bypassed, and
+// invalidate / eviction hooks are called once all entries are placed.
+func (c *Cache[K, V]) Warm(entries map[K]V) {
+	c.setMany(false, func(yield func(K, V)) {
+		for k, v := range entries {
+			yield(k, v)
+		}
+	})
+}
+
+// WarmFunc performs Warm() with the entries returned by next until it returns false. As next is
+// called with the cache locked, it MUST NOT call any cache methods.
+func (c *Cache[K, V]) WarmFunc(next func() (K, V, bool)) {
+	c.setMany(false, func(yield func(K, V)) {
+		for {
+			k, v, ok := next()
+			if !ok {
+				return
+			}
+			yield(k, v)
+		}
+	})
+}
+
+// SetMany places all given entries in the cache as Set(), under a single lock acquisition and expiry computation
+// (unless Jitter is set). Invalidate / eviction hooks are called once all entries are placed.
+func (c *Cache[K, V]) SetMany(entries map[K]V) {
+	c.setMany(true, func(yield func(K, V)) {
+		for k, v := range entries {
+			yield(k, v)
+		}
+	})
+}
+
+// setMany places each entry yielded by given func in the cache, checking the admission filter (if any) when admit is set.
+func (c *Cache[K, V]) setMany(admit bool, each func(yield func(K, V))) {
+	var (
+		// invalidated, evicted key-values.
+		invalids []kv[K, V]
+		evicts   []kv[K, V]
+
+		// observed changes.
+		changes []change[K, V]
+
+		// overwritten values.
+		updates []update[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		updated func(K, V, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
+	)
+
+	c.locked(func() {
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
+
+		// Compute shared expiry.
+		expiry := c.expiry()
+
+		each(func(key K, value V) {
+			if c.Jitter > 0 {
+				// Spread each expiry.
+				expiry = c.expiry()
+			}
+
+			// Check for item in cache
+			item, ok := c.Cache.Get(key)
+
+			if !ok && admit && !c.admit(key) {
+				// Rejected.
+				return
+			}
+
+			if observe != nil {
+				// Store change for later access.
+				op := OpAdd
+				if ok {
+					op = OpUpdate
+				}
+				changes = append(changes, change[K, V]{op, kv[K, V]{K: key, V: value}})
+			}
+
+			if ok {
+				if invalid != nil {
+					// Store old key-value pair for later access.
+					invalids = append(invalids, kv[K, V]{K: key, V: item.Value})
+				}
+
+				if updated != nil {
+					// Store old and new values for later access.
+					updates = append(updates, update[K, V]{key, item.Value, value})
+				}
+
+				// Reset the existing item.
+				item.Cost = 0
+				item.Policy = DefaultExpiry
+				c.prioritize(item, 0)
+			} else {
+				// Alloc new entry.
+				item = c.alloc()
+				item.Key = key
+
+				// Make room by priority.
+				if evc, evicted := c.evictVictim(); evicted && evict != nil {
+					evicts = append(evicts, evc)
+				}
+
+				// Add new entry to cache and catch any evicted item.
+				c.Cache.SetWithHook(key, item, func(_ K, item *Entry[K, V]) {
+					if evict != nil {
+						evicts = append(evicts, kv[K, V]{K: item.Key, V: item.Value})
+					}
+					c.free(item)
+				})
+			}
+
+			// Set value + expiry.
+			item.Value = value
+			item.Expiry = expiry
+			c.weigh(item)
+			c.index(item)
+		})
+
+		// Evict beyond max cost.
+		evicts = append(evicts, c.shed(evict)...)
+	})
+
+	if invalid != nil {
+		for x := range invalids {
+			// Pass to invalidate hook.
+			invalid(invalids[x].K, invalids[x].V)
+		}
+	}
+
+	if updated != nil {
+		for x := range updates {
+			// Pass to update hook.
+			updated(updates[x].K, updates[x].Old, updates[x].New)
+		}
+	}
+
+	if observe != nil {
+		for x := range changes {
+			// Pass to observer.
+			observe(changes[x].op, changes[x].K, changes[x].V)
+		}
+	}
+
+	if evict != nil {
+		for x := range evicts {
+			// Pass to eviction hook.
+			evict(evicts[x].K, evicts[x].V)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package ttl
+
+import (
+	"time"
+)
+
+// Clone returns an independent copy of the cache, with the same entries (in the same order) and expiries, and the
+// same configuration excluding callbacks, observer, admission filter and persist path. Values are copied using copy,
+// nil copies them shallowly. The clone's eviction routine is not started, e.g. for test snapshots or config reloads.
+func (c *Cache[K, V]) Clone(copy func(V) V) *Cache[K, V] {
+	dst := new(Cache[K, V])
+
+	c.locked(func() {
+		dst.Init(c.Cache.Len(), c.Cache.Cap(), c.TTL)
+
+		// Copy configuration.
+		dst.TTL = c.TTL
+		dst.Policy = c.Policy
+		dst.SweepBatch = c.SweepBatch
+		dst.Jitter = c.Jitter
+		dst.Weigher = c.Weigher
+		dst.MaxCost = c.MaxCost
+		dst.Stats = c.Stats
+		dst.Log = c.Log
+		dst.Idle = c.Idle
+		dst.Grace = c.Grace
+		dst.Revalidate = c.Revalidate
+		dst.Beta = c.Beta
+		dst.Codec = c.Codec
+
+		if c.wheel != nil {
+			// Index in new timer wheel of same tick.
+			dst.wheel = newWheel[K, V](time.Duration(c.wheel.tick), runtime_nanotime())
+		}
+
+		if c.Cache.Len() == 0 {
+			// Nothing to copy.
+			return
+		}
+
+		// Copy entries, least recently used first.
+		c.Cache.Range(c.Cache.Len()-1, -c.Cache.Len(), func(_ int, key K, item *Entry[K, V]) {
+			e := dst.clone(item)
+			if copy != nil {
+				e.Value = copy(e.Value)
+			}
+			dst.weight += e.Weight
+			if dst.wheel != nil {
+				// Index as-is, keeping idle deadline.
+				dst.wheel.insert(e)
+			}
+			dst.Cache.Set(key, e)
+		})
+
+		// Set cloned size.
+		dst.size.Store(int64(dst.Cache.Len()))
+	})
+
+	return dst
+}
@@ -0,0 +1,72 @@
+package ttl
+
+import (
+	"fmt"
+	"time"
+)
+
+// DebugInfo is a snapshot of cache state for operational inspection, see Debug().
+type DebugInfo struct {
+	// Len, Cap are the current length and maximum capacity.
+	Len int `json:"len"`
+	Cap int `json:"cap"`
+
+	// TTL is the cache item TTL (nanoseconds in JSON).
+	TTL time.Duration `json:"ttl"`
+
+	// Oldest, Newest are the earliest and latest entry expiries, zero if none expire.
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+
+	// Pool is the number of free entries held for reuse.
+	Pool int `json:"pool"`
+}
+
+// Debug returns a snapshot of the cache's size, capacity, TTL, earliest and latest entry expiries and entry pool
+// size, e.g. for serving from admin endpoints. Note this scans all entries under lock.
+func (c *Cache[K, V]) Debug() (info DebugInfo) {
+	c.locked(func() {
+		var oldest, newest uint64
+
+		c.Cache.Range(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+			if item.Expiry == 0 {
+				return
+			}
+			if oldest == 0 || item.Expiry < oldest {
+				oldest = item.Expiry
+			}
+			if item.Expiry > newest {
+				newest = item.Expiry
+			}
+		})
+
+		// get current time.
+		now := runtime_nanotime()
+		wall := time.Now()
+
+		if oldest != 0 {
+			info.Oldest = walltime(wall, now, oldest)
+			info.Newest = walltime(wall, now, newest)
+		}
+
+		info.Len = c.Cache.Len()
+		info.Cap = c.Cache.Cap()
+		info.TTL = c.TTL
+		info.Pool = len(c.pool)
+	})
+	return
+}
+
+// String returns Debug() formatted on a single line, with expiries relative to now.
+func (c *Cache[K, V]) String() string {
+	info := c.Debug()
+
+	oldest, newest := "-", "-"
+	if !info.Oldest.IsZero() {
+		oldest = time.Until(info.Oldest).Round(time.Millisecond).String()
+		newest = time.Until(info.Newest).Round(time.Millisecond).String()
+	}
+
+	return fmt.Sprintf("ttl.Cache{len=%d cap=%d ttl=%s oldest=%s newest=%s pool=%d}",
+		info.Len, info.Cap, info.TTL, oldest, newest, info.Pool)
+}
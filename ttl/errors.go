@@ -0,0 +1,46 @@
+package ttl
+
+import (
+	"github.com/mkc188/go-cache/v3/errs"
+)
+
+// GetE performs Get(), returning errs.ErrNotFound if no unexpired value exists for key.
+func (c *Cache[K, V]) GetE(key K) (V, error) {
+	v, ok := c.Get(key)
+	if !ok {
+		return v, errs.ErrNotFound
+	}
+	return v, nil
+}
+
+// SetE performs Set(), returning errs.ErrCapacity if the value was not stored, i.e. rejected by the admission
+// filter or heavier than MaxCost on its own.
+func (c *Cache[K, V]) SetE(key K, value V) error {
+	if !c.set(key, value, 0, DefaultExpiry, 0) {
+		return errs.ErrCapacity
+	}
+	return nil
+}
+
+// InvalidateE performs Invalidate(), returning errs.ErrNotFound if no value exists for key.
+func (c *Cache[K, V]) InvalidateE(key K) error {
+	if !c.Invalidate(key) {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+// GetE performs Cache.GetE() on the shard for key.
+func (c *ShardedCache[K, V]) GetE(key K) (V, error) {
+	return c.shard(key).GetE(key)
+}
+
+// SetE performs Cache.SetE() on the shard for key.
+func (c *ShardedCache[K, V]) SetE(key K, value V) error {
+	return c.shard(key).SetE(key, value)
+}
+
+// InvalidateE performs Cache.InvalidateE() on the shard for key.
+func (c *ShardedCache[K, V]) InvalidateE(key K) error {
+	return c.shard(key).InvalidateE(key)
+}
@@ -0,0 +1,49 @@
+package ttl
+
+import (
+	"time"
+)
+
+// SetIdleTimeout sets the maximum time an entry is kept without being read or written, even if its TTL is longer,
+// e.g. for session stores with both an absolute and an idle lifetime. Existing entries are treated as used now.
+// This applies while a TTL is set, and only shortens lifetimes, i.e. an idle timeout >= TTL has no effect on entries
+// with sliding expiry. An idle timeout <= 0 disables.
+func (c *Cache[K, V]) SetIdleTimeout(idle time.Duration) {
+	c.locked(func() {
+		c.Cache.Range(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+			// Restore TTL deadline.
+			item.Expiry = c.deadline(item)
+			item.Deadline = 0
+		})
+
+		c.Idle = idle
+
+		c.Cache.Range(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+			// Re-index by new timeout.
+			c.index(item)
+		})
+	})
+}
+
+// deadline returns item's expiry by TTL alone, i.e. ignoring any idle timeout (NOTE: requires lock).
+func (c *Cache[K, V]) deadline(item *Entry[K, V]) uint64 {
+	if c.Idle > 0 && item.Deadline != 0 {
+		return item.Deadline
+	}
+	return item.Expiry
+}
+
+// idle records item's current expiry as its TTL deadline, bringing Expiry forward to the idle timeout from now
+// if earlier, when an idle timeout is set (NOTE: requires lock).
+func (c *Cache[K, V]) idle(item *Entry[K, V]) {
+	if c.Idle <= 0 || item.Expiry == 0 {
+		return
+	}
+
+	item.Deadline = item.Expiry
+
+	if idle := runtime_nanotime() + uint64(c.Idle+c.Grace); idle < item.Expiry {
+		// Idle timeout first.
+		item.Expiry = idle
+	}
+}
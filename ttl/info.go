@@ -0,0 +1,60 @@
+package ttl
+
+import (
+	"time"
+)
+
+// Info is the metadata of a cache entry, see EntryInfo().
+type Info struct {
+	// Created is when the entry was first stored.
+	Created time.Time
+
+	// Accessed is when the entry was last read, zero if never.
+	Accessed time.Time
+
+	// Hits is the number of times the entry has been read.
+	Hits uint64
+
+	// Expires is when the entry expires, zero if never.
+	Expires time.Time
+}
+
+// EntryInfo returns the metadata of the entry with key, without counting as an access, e.g. for freshness heuristics
+// or inspecting hot keys. Returns false if not found or expired.
+func (c *Cache[K, V]) EntryInfo(key K) (info Info, ok bool) {
+	c.locked(func() {
+		var item *Entry[K, V]
+
+		// Check for item in cache,
+		// without marking as used.
+		item, ok = c.peek(key)
+		if !ok {
+			return
+		}
+
+		// get current time.
+		now := runtime_nanotime()
+		wall := time.Now()
+
+		if item.Expiry != 0 && now > item.Expiry {
+			// Expired.
+			ok = false
+			return
+		}
+
+		info.Created = walltime(wall, now, item.Created)
+		info.Hits = item.Hits
+		if item.Accessed != 0 {
+			info.Accessed = walltime(wall, now, item.Accessed)
+		}
+		if item.Expiry != 0 {
+			info.Expires = walltime(wall, now, item.Expiry)
+		}
+	})
+	return
+}
+
+// walltime converts runtime nanoseconds t to wall clock time, given the current wall clock and runtime nanoseconds.
+func walltime(wall time.Time, now uint64, t uint64) time.Time {
+	return wall.Add(time.Duration(int64(t - now)))
+}
@@ -0,0 +1,164 @@
+package ttl
+
+// Merge imports all unexpired entries of other into the cache under a single lock acquisition, least recently used
+// first, keeping their expiries, cost, policy and priority. On conflict with an existing entry the value is given by
+// onConflict(existing, imported) (nil keeps imported), and the later of both expiries is kept. As onConflict is called
+// with the cache locked, it MUST NOT call any cache methods. Invalidate / eviction hooks are called once all are placed.
+func (c *Cache[K, V]) Merge(other *Cache[K, V], onConflict func(a, b V) V) {
+	var entries []Entry[K, V]
+
+	if other == c {
+		// Nothing to merge.
+		return
+	}
+
+	other.locked(func() {
+		if other.Cache.Len() == 0 {
+			// Nothing to import.
+			return
+		}
+
+		// get current nanoseconds.
+		now := runtime_nanotime()
+
+		// Alloc entries slice of expected size.
+		entries = make([]Entry[K, V], 0, other.Cache.Len())
+
+		// Copy entries, least recently used first.
+		other.Cache.Range(other.Cache.Len()-1, -other.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+			if other.TTL > 0 && item.Expiry != 0 && now > item.Expiry {
+				// Already expired.
+				return
+			}
+			entries = append(entries, Entry[K, V]{
+				Key:      item.Key,
+				Value:    item.Value,
+				Expiry:   item.Expiry,
+				Cost:     item.Cost,
+				Policy:   item.Policy,
+				Priority: item.Priority,
+			})
+		})
+	})
+
+	var (
+		// invalidated, evicted key-values.
+		invalids []kv[K, V]
+		evicts   []kv[K, V]
+
+		// observed changes.
+		changes []change[K, V]
+
+		// overwritten values.
+		updates []update[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		updated func(K, V, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
+	)
+
+	c.locked(func() {
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
+
+		for x := range entries {
+			e := &entries[x]
+
+			// Check for item in cache
+			item, ok := c.Cache.Get(e.Key)
+
+			if ok {
+				if invalid != nil {
+					// Store old key-value pair for later access.
+					invalids = append(invalids, kv[K, V]{K: e.Key, V: item.Value})
+				}
+
+				if onConflict != nil {
+					// Resolve conflicting values.
+					e.Value = onConflict(item.Value, e.Value)
+				}
+
+				if updated != nil {
+					// Store old and new values for later access.
+					updates = append(updates, update[K, V]{e.Key, item.Value, e.Value})
+				}
+
+				if item.Expiry == 0 || (e.Expiry != 0 && item.Expiry > e.Expiry) {
+					// Keep the later expiry.
+					e.Expiry = item.Expiry
+				}
+			} else {
+				// Alloc new entry.
+				item = c.alloc()
+				item.Key = e.Key
+
+				// Make room by priority.
+				if evc, evicted := c.evictVictim(); evicted && evict != nil {
+					evicts = append(evicts, evc)
+				}
+
+				// Add new entry to cache and catch any evicted item.
+				c.Cache.SetWithHook(e.Key, item, func(_ K, item *Entry[K, V]) {
+					if evict != nil {
+						evicts = append(evicts, kv[K, V]{K: item.Key, V: item.Value})
+					}
+					c.free(item)
+				})
+			}
+
+			if observe != nil {
+				// Store change for later access.
+				op := OpAdd
+				if ok {
+					op = OpUpdate
+				}
+				changes = append(changes, change[K, V]{op, kv[K, V]{K: e.Key, V: e.Value}})
+			}
+
+			// Set merged entry info.
+			item.Value = e.Value
+			item.Expiry = e.Expiry
+			item.Cost = e.Cost
+			item.Policy = e.Policy
+			c.prioritize(item, e.Priority)
+			c.weigh(item)
+			c.index(item)
+		}
+
+		// Evict beyond max cost.
+		evicts = append(evicts, c.shed(evict)...)
+	})
+
+	if invalid != nil {
+		for x := range invalids {
+			// Pass to invalidate hook.
+			invalid(invalids[x].K, invalids[x].V)
+		}
+	}
+
+	if updated != nil {
+		for x := range updates {
+			// Pass to update hook.
+			updated(updates[x].K, updates[x].Old, updates[x].New)
+		}
+	}
+
+	if observe != nil {
+		for x := range changes {
+			// Pass to observer.
+			observe(changes[x].op, changes[x].K, changes[x].V)
+		}
+	}
+
+	if evict != nil {
+		for x := range evicts {
+			// Pass to eviction hook.
+			evict(evicts[x].K, evicts[x].V)
+		}
+	}
+}
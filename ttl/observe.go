@@ -0,0 +1,77 @@
+package ttl
+
+// Op is a kind of change to a cache entry, as passed to an observer, see SetObserver().
+type Op uint8
+
+const (
+	// OpAdd is a value being stored at a new key.
+	OpAdd Op = iota
+
+	// OpUpdate is the value at an existing key being replaced.
+	OpUpdate
+
+	// OpInvalidate is a value being invalidated, i.e. deleted or cleared.
+	OpInvalidate
+
+	// OpEvict is a value being evicted, i.e. expired or beyond capacity.
+	OpEvict
+)
+
+// String returns the name of the op.
+func (op Op) String() string {
+	switch op {
+	case OpAdd:
+		return "add"
+	case OpUpdate:
+		return "update"
+	case OpInvalidate:
+		return "invalidate"
+	case OpEvict:
+		return "evict"
+	default:
+		return "unknown"
+	}
+}
+
+// change is an observed change to a cache entry.
+type change[K comparable, V any] struct {
+	op Op
+	kv[K, V]
+}
+
+// SetObserver sets the observer called (outside the cache lock) for each change to cache entries, with the new value
+// for OpAdd / OpUpdate and the old value for OpInvalidate / OpEvict. This is independent of the eviction and
+// invalidate callbacks, e.g. for publishing to an events.Bus, see events.Bus.Observe().
+func (c *Cache[K, V]) SetObserver(fn func(op Op, key K, value V)) {
+	c.locked(func() {
+		c.Observer = fn
+	})
+}
+
+// evictHook returns the eviction callback, combined with the observer if set (NOTE: requires lock).
+func (c *Cache[K, V]) evictHook() func(K, V) {
+	evict, observe := c.Evict, c.Observer
+	if observe == nil {
+		return evict
+	}
+	return func(key K, value V) {
+		if evict != nil {
+			evict(key, value)
+		}
+		observe(OpEvict, key, value)
+	}
+}
+
+// invalidHook returns the invalidate callback for deleted values, combined with the observer if set (NOTE: requires lock).
+func (c *Cache[K, V]) invalidHook() func(K, V) {
+	invalid, observe := c.Invalid, c.Observer
+	if observe == nil {
+		return invalid
+	}
+	return func(key K, value V) {
+		if invalid != nil {
+			invalid(key, value)
+		}
+		observe(OpInvalidate, key, value)
+	}
+}
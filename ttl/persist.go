@@ -0,0 +1,65 @@
+package ttl
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// SetPersistPath sets the file the cache is saved to on Stop() and loaded from on Start(), empty disables.
+func (c *Cache[K, V]) SetPersistPath(path string) {
+	c.locked(func() {
+		c.PersistPath = path
+	})
+}
+
+// Save dumps the cache to the file at path (see Dump()), writing to a temporary file first and renaming into place.
+func (c *Cache[K, V]) Save(path string) error {
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(f)
+	if err = c.Dump(bw); err == nil {
+		err = bw.Flush()
+	}
+
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+
+	if err == nil {
+		// Swap the dump into place.
+		err = os.Rename(tmp, path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+
+	return err
+}
+
+// Load restores the cache from the file at path (see Restore()), discarding entries that have expired since the file
+// was saved, as judged by its modification time. A missing file is not an error.
+func (c *Cache[K, V]) Load(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return c.restore(bufio.NewReader(f), time.Since(info.ModTime()))
+}
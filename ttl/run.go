@@ -0,0 +1,32 @@
+package ttl
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotStarted is returned by Run() when the eviction routine could not be started, i.e. when
+// already running or given a sweep frequency <= 0.
+var ErrNotStarted = errors.New("ttl: eviction routine not started")
+
+// Run performs Start(), then blocks until ctx is done before performing Stop(), such that the cache's lifecycle
+// follows that of a service, e.g. run within an errgroup. Returns ctx.Err() once stopped, or ErrNotStarted.
+func (c *Cache[K, V]) Run(ctx context.Context, freq time.Duration) error {
+	if !c.Start(freq) {
+		return ErrNotStarted
+	}
+	<-ctx.Done()
+	c.Stop()
+	return ctx.Err()
+}
+
+// Run performs Cache.Run() for all shards at once.
+func (c *ShardedCache[K, V]) Run(ctx context.Context, freq time.Duration) error {
+	if !c.Start(freq) {
+		return ErrNotStarted
+	}
+	<-ctx.Done()
+	c.Stop()
+	return ctx.Err()
+}
@@ -0,0 +1,103 @@
+package ttl
+
+import (
+	"time"
+)
+
+// ExpiringSet is a set of keys each expiring a TTL after being added, e.g. for deduplicating events or webhooks within a time window.
+type ExpiringSet[Key comparable] struct {
+	cache Cache[Key, struct{}]
+}
+
+// NewExpiringSet returns a new initialized ExpiringSet with given maximum capacity and key TTL.
+func NewExpiringSet[K comparable](cap int, ttl time.Duration) *ExpiringSet[K] {
+	s := new(ExpiringSet[K])
+	s.cache.Init(0, cap, ttl)
+	return s
+}
+
+// Start will start the set background eviction routine with given sweep frequency, see Cache.Start().
+func (s *ExpiringSet[K]) Start(freq time.Duration) bool {
+	return s.cache.Start(freq)
+}
+
+// Stop will stop the set background eviction routine, see Cache.Stop().
+func (s *ExpiringSet[K]) Stop() bool {
+	return s.cache.Stop()
+}
+
+// AddIfAbsent adds key to the set, returning true if it was not already present (or had expired).
+func (s *ExpiringSet[K]) AddIfAbsent(key K) (ok bool) {
+	var (
+		// was entry evicted?
+		ev bool
+
+		// evicted key.
+		evcK K
+
+		// hook func ptrs.
+		evict func(K, struct{})
+	)
+
+	s.cache.locked(func() {
+		item, has := s.cache.Cache.Get(key)
+		if has {
+			if ok = s.expired(item); ok {
+				// Expired but not yet
+				// swept, re-add key.
+				item.Expiry = s.cache.expiry()
+				s.cache.index(item)
+			}
+			return
+		}
+
+		// Alloc new entry.
+		new := s.cache.alloc()
+		new.Expiry = s.cache.expiry()
+		new.Key = key
+		s.cache.index(new)
+
+		// Add new entry to cache and catch any evicted item.
+		s.cache.Cache.SetWithHook(key, new, func(_ K, item *Entry[K, struct{}]) {
+			evcK = item.Key
+			ev = true
+			s.cache.free(item)
+		})
+		ok = true
+
+		// Set hook func ptr.
+		evict = s.cache.evictHook()
+	})
+
+	if ev && evict != nil {
+		// Pass to eviction hook.
+		evict(evcK, struct{}{})
+	}
+
+	return
+}
+
+// Seen returns whether key is present in the set and not expired, without extending its TTL.
+func (s *ExpiringSet[K]) Seen(key K) (ok bool) {
+	s.cache.locked(func() {
+		var item *Entry[K, struct{}]
+		item, ok = s.cache.Cache.Get(key)
+		ok = ok && !s.expired(item)
+	})
+	return
+}
+
+// Remove removes key from the set, returning whether it was present.
+func (s *ExpiringSet[K]) Remove(key K) bool {
+	return s.cache.Invalidate(key)
+}
+
+// Len returns the current number of keys in the set, including any expired but not yet swept.
+func (s *ExpiringSet[K]) Len() int {
+	return s.cache.Len()
+}
+
+// expired returns whether entry has expired (NOTE: requires lock).
+func (s *ExpiringSet[K]) expired(item *Entry[K, struct{}]) bool {
+	return item.Expiry != 0 && runtime_nanotime() > item.Expiry
+}
@@ -0,0 +1,255 @@
+package ttl
+
+import (
+	"fmt"
+	"hash/maphash"
+	"time"
+)
+
+// ShardedCache is a TTLCache split across 2^n Cache shards by key hash, each with its own lock and sweep
+// routine, reducing lock contention under highly concurrent access. Capacity is divided evenly between shards,
+// so eviction is least recently used per shard rather than across the whole cache.
+type ShardedCache[Key comparable, Value any] struct {
+	// Hash returns the hash of a key, defaulting to hashing strings directly, else their fmt.Sprint representation.
+	Hash func(Key) uint64
+
+	// shards are the underlying caches.
+	shards []Cache[Key, Value]
+
+	// mask selects a shard from key hash.
+	mask uint64
+}
+
+// seed is the hash seed of default key hashing.
+var seed = maphash.MakeSeed()
+
+// NewSharded returns a new initialized ShardedCache with given shard count (rounded up to a power of 2),
+// and total initial length, maximum capacity and item TTL divided between shards.
+func NewSharded[K comparable, V any](shards, len, cap int, ttl time.Duration) *ShardedCache[K, V] {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+
+	c := &ShardedCache[K, V]{
+		Hash:   hashKey[K],
+		shards: make([]Cache[K, V], n),
+		mask:   uint64(n - 1),
+	}
+
+	for i := range c.shards {
+		c.shards[i].Init((len+n-1)/n, (cap+n-1)/n, ttl)
+	}
+
+	return c
+}
+
+// Shards returns the number of shards.
+func (c *ShardedCache[K, V]) Shards() int {
+	return len(c.shards)
+}
+
+// Shard returns the shard at index i, for access to Cache methods beyond the TTLCache interface.
+func (c *ShardedCache[K, V]) Shard(i int) *Cache[K, V] {
+	return &c.shards[i]
+}
+
+// Start: implements cache.TTLCache's Start(), starting each shard's sweep routine.
+func (c *ShardedCache[K, V]) Start(freq time.Duration) (ok bool) {
+	for i := range c.shards {
+		ok = c.shards[i].Start(freq) || ok
+	}
+	return
+}
+
+// Stop: implements cache.TTLCache's Stop(), stopping each shard's sweep routine.
+func (c *ShardedCache[K, V]) Stop() (ok bool) {
+	for i := range c.shards {
+		ok = c.shards[i].Stop() || ok
+	}
+	return
+}
+
+// StopAndFlush performs Cache.StopAndFlush() on each shard.
+func (c *ShardedCache[K, V]) StopAndFlush() (ok bool) {
+	for i := range c.shards {
+		ok = c.shards[i].StopAndFlush() || ok
+	}
+	return
+}
+
+// SetTTL: implements cache.TTLCache's SetTTL().
+func (c *ShardedCache[K, V]) SetTTL(ttl time.Duration, update bool) {
+	for i := range c.shards {
+		c.shards[i].SetTTL(ttl, update)
+	}
+}
+
+// SetEvictionCallback: implements cache.Cache's SetEvictionCallback().
+func (c *ShardedCache[K, V]) SetEvictionCallback(hook func(K, V)) {
+	for i := range c.shards {
+		c.shards[i].SetEvictionCallback(hook)
+	}
+}
+
+// SetInvalidateCallback: implements cache.Cache's SetInvalidateCallback().
+func (c *ShardedCache[K, V]) SetInvalidateCallback(hook func(K, V)) {
+	for i := range c.shards {
+		c.shards[i].SetInvalidateCallback(hook)
+	}
+}
+
+// SetUpdateCallback sets the update callback of each shard, see Cache.SetUpdateCallback().
+func (c *ShardedCache[K, V]) SetUpdateCallback(hook func(key K, old V, new V)) {
+	for i := range c.shards {
+		c.shards[i].SetUpdateCallback(hook)
+	}
+}
+
+// Get: implements cache.Cache's Get().
+func (c *ShardedCache[K, V]) Get(key K) (V, bool) {
+	return c.shard(key).Get(key)
+}
+
+// GetMany performs Cache.GetMany() on each shard at once, returning all values found.
+func (c *ShardedCache[K, V]) GetMany(keys ...K) map[K]V {
+	values := make(map[K]V, len(keys))
+
+	for i, keys := range c.group(keys) {
+		for k, v := range c.shards[i].GetMany(keys...) {
+			values[k] = v
+		}
+	}
+
+	return values
+}
+
+// SetMany performs Cache.SetMany() on each shard at once.
+func (c *ShardedCache[K, V]) SetMany(entries map[K]V) {
+	grouped := make(map[uint64]map[K]V)
+	for key, value := range entries {
+		i := c.Hash(key) & c.mask
+		if grouped[i] == nil {
+			grouped[i] = make(map[K]V)
+		}
+		grouped[i][key] = value
+	}
+
+	for i, entries := range grouped {
+		c.shards[i].SetMany(entries)
+	}
+}
+
+// Add: implements cache.Cache's Add().
+func (c *ShardedCache[K, V]) Add(key K, value V) bool {
+	return c.shard(key).Add(key, value)
+}
+
+// Set: implements cache.Cache's Set().
+func (c *ShardedCache[K, V]) Set(key K, value V) {
+	c.shard(key).Set(key, value)
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *ShardedCache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	return c.shard(key).CAS(key, old, new, cmp)
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *ShardedCache[K, V]) Swap(key K, swp V) V {
+	return c.shard(key).Swap(key, swp)
+}
+
+// Update performs Cache.Update() on the shard for key.
+func (c *ShardedCache[K, V]) Update(key K, fn func(old V, ok bool) (V, bool)) (V, bool) {
+	return c.shard(key).Update(key, fn)
+}
+
+// Has: implements cache.Cache's Has().
+func (c *ShardedCache[K, V]) Has(key K) bool {
+	return c.shard(key).Has(key)
+}
+
+// Touch: implements cache.TTLCache's Touch().
+func (c *ShardedCache[K, V]) Touch(key K) bool {
+	return c.shard(key).Touch(key)
+}
+
+// Extend: implements cache.TTLCache's Extend().
+func (c *ShardedCache[K, V]) Extend(key K, d time.Duration) bool {
+	return c.shard(key).Extend(key, d)
+}
+
+// SetExpiry performs Cache.SetExpiry() on the shard for key.
+func (c *ShardedCache[K, V]) SetExpiry(key K, at time.Time) bool {
+	return c.shard(key).SetExpiry(key, at)
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (c *ShardedCache[K, V]) Invalidate(key K) bool {
+	return c.shard(key).Invalidate(key)
+}
+
+// CompareAndDelete performs Cache.CompareAndDelete() on the shard for key.
+func (c *ShardedCache[K, V]) CompareAndDelete(key K, cmp V, eq func(V, V) bool) bool {
+	return c.shard(key).CompareAndDelete(key, cmp, eq)
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll(), invalidating keys in each shard at once.
+func (c *ShardedCache[K, V]) InvalidateAll(keys ...K) bool {
+	return c.InvalidateMany(keys...) > 0
+}
+
+// InvalidateMany performs Cache.InvalidateMany() on each shard at once, returning the total number of values invalidated.
+func (c *ShardedCache[K, V]) InvalidateMany(keys ...K) (n int) {
+	for i, keys := range c.group(keys) {
+		n += c.shards[i].InvalidateMany(keys...)
+	}
+	return
+}
+
+// Clear: implements cache.Cache's Clear().
+func (c *ShardedCache[K, V]) Clear() {
+	for i := range c.shards {
+		c.shards[i].Clear()
+	}
+}
+
+// Len: implements cache.Cache's Len(), summing all shards.
+func (c *ShardedCache[K, V]) Len() (l int) {
+	for i := range c.shards {
+		l += c.shards[i].Len()
+	}
+	return
+}
+
+// Cap: implements cache.Cache's Cap(), summing all shards.
+func (c *ShardedCache[K, V]) Cap() (l int) {
+	for i := range c.shards {
+		l += c.shards[i].Cap()
+	}
+	return
+}
+
+// shard returns the shard for key.
+func (c *ShardedCache[K, V]) shard(key K) *Cache[K, V] {
+	return &c.shards[c.Hash(key)&c.mask]
+}
+
+// group groups keys by shard index.
+func (c *ShardedCache[K, V]) group(keys []K) map[uint64][]K {
+	grouped := make(map[uint64][]K)
+	for _, key := range keys {
+		i := c.Hash(key) & c.mask
+		grouped[i] = append(grouped[i], key)
+	}
+	return grouped
+}
+
+// hashKey is the default key hash func.
+func hashKey[K comparable](key K) uint64 {
+	if s, ok := any(key).(string); ok {
+		return maphash.String(seed, s)
+	}
+	return maphash.String(seed, fmt.Sprint(key))
+}
@@ -0,0 +1,131 @@
+package ttl
+
+import (
+	"io"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/snapshot"
+)
+
+// Dump: implements snapshot.Dumper, writing entries least recently used first, with their remaining TTLs.
+func (c *Cache[K, V]) Dump(w io.Writer) error {
+	var (
+		entries []snapshot.Entry[K, V]
+		codec   snapshot.Codec
+	)
+
+	c.locked(func() {
+		// Set codec ptr.
+		codec = c.Codec
+
+		// get current nanoseconds.
+		now := runtime_nanotime()
+
+		// Alloc entries slice of expected size.
+		entries = make([]snapshot.Entry[K, V], c.Cache.Len())
+
+		// Cache is ordered most recently used first, store in reverse.
+		c.Cache.Range(0, c.Cache.Len(), func(i int, _ K, item *Entry[K, V]) {
+			e := &entries[len(entries)-1-i]
+			e.Key = item.Key
+			e.Value = item.Value
+			if item.Expiry != 0 {
+				e.TTL = time.Duration(int64(item.Expiry - now))
+				if e.TTL == 0 {
+					// Expiring now.
+					e.TTL = -1
+				}
+			}
+		})
+	})
+
+	return snapshot.Write(w, codec, entries)
+}
+
+// Restore: implements snapshot.Restorer, placing restored entries with their remaining TTLs, replacing any existing.
+func (c *Cache[K, V]) Restore(r io.Reader) error {
+	return c.restore(r, 0)
+}
+
+// restore reads entries from r into the cache as Restore(), deducting given elapsed time since dumped from their
+// remaining TTLs and discarding those that have since expired.
+func (c *Cache[K, V]) restore(r io.Reader, elapsed time.Duration) error {
+	var (
+		entries []snapshot.Entry[K, V]
+		codec   snapshot.Codec
+	)
+
+	// Set codec ptr.
+	c.locked(func() { codec = c.Codec })
+
+	if err := snapshot.Read(r, codec, func(e snapshot.Entry[K, V]) error {
+		if e.TTL > 0 {
+			if e.TTL -= elapsed; e.TTL <= 0 {
+				// Expired since dumped.
+				return nil
+			}
+		}
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var (
+		// evicted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		evict func(K, V)
+	)
+
+	c.locked(func() {
+		// get current nanoseconds.
+		now := runtime_nanotime()
+
+		for _, e := range entries {
+			item, ok := c.Cache.Get(e.Key)
+			if !ok {
+				// Alloc new entry.
+				item = c.alloc()
+				item.Key = e.Key
+
+				// Make room by priority.
+				if evc, evicted := c.evictVictim(); evicted {
+					kvs = append(kvs, evc)
+				}
+
+				// Add new entry to cache and catch any evicted item.
+				c.Cache.SetWithHook(e.Key, item, func(_ K, item *Entry[K, V]) {
+					kvs = append(kvs, kv[K, V]{K: item.Key, V: item.Value})
+					c.free(item)
+				})
+			}
+
+			// Set restored value + expiry.
+			item.Value = e.Value
+			if e.TTL > 0 {
+				item.Expiry = now + uint64(e.TTL)
+			} else {
+				item.Expiry = c.expiry()
+			}
+			c.weigh(item)
+			c.index(item)
+		}
+
+		// Set hook func ptr.
+		evict = c.evictHook()
+
+		// Evict beyond max cost.
+		kvs = append(kvs, c.shed(evict)...)
+	})
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+
+	return nil
+}
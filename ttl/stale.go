@@ -0,0 +1,150 @@
+package ttl
+
+import (
+	"time"
+
+	"github.com/mkc188/go-cache/v3/logging"
+)
+
+// SetStaleWhileRevalidate enables serving entries for up to grace after they expire, such that reads of a stale entry
+// return its value while it is refreshed in the background using revalidate (at most once at a time per key), so TTL
+// expiry doesn't cause latency spikes on hot keys. Entries are kept in the cache for TTL + grace, failures to
+// revalidate are logged and the stale value kept until then. A nil revalidate disables, intended to be set after Init().
+func (c *Cache[K, V]) SetStaleWhileRevalidate(grace time.Duration, revalidate func(K) (V, error)) {
+	c.locked(func() {
+		if revalidate == nil {
+			grace = 0
+		}
+		c.Grace = grace
+		c.Revalidate = revalidate
+	})
+}
+
+// stale returns the revalidate func if item is past its TTL but within its grace window and not already being
+// revalidated, marking it as such, in which case the caller MUST call revalidate() after unlock (NOTE: requires lock).
+func (c *Cache[K, V]) stale(item *Entry[K, V]) func(K) (V, error) {
+	if c.Revalidate == nil || item.Expiry == 0 || runtime_nanotime()+uint64(c.Grace) <= item.Expiry {
+		return nil
+	}
+
+	if _, ok := c.revalidating[item.Key]; ok {
+		// Already in progress.
+		return nil
+	}
+
+	if c.revalidating == nil {
+		// Lazily alloc revalidating set.
+		c.revalidating = make(map[K]bool)
+	}
+
+	c.revalidating[item.Key] = false
+	return c.Revalidate
+}
+
+// revalidate refreshes the value at key in the background using fn, see SetStaleWhileRevalidate().
+func (c *Cache[K, V]) revalidate(key K, fn func(K) (V, error)) {
+	go func() {
+		v, err := fn(key)
+		if err == nil {
+			// Store refreshed.
+			c.refresh(key, v)
+			return
+		}
+
+		var log logging.Logger
+
+		c.locked(func() {
+			delete(c.revalidating, key)
+
+			// Set logger ptr.
+			log = c.Log
+		})
+
+		if log != nil {
+			log.Warn("ttl: failed to revalidate stale entry", "err", err)
+		}
+	}()
+}
+
+// refresh stores revalidated value v at key, only if its stale entry is still cached,
+// i.e. not since invalidated, evicted, expired or written, ending its revalidation.
+func (c *Cache[K, V]) refresh(key K, v V) {
+	var (
+		// did store value?
+		ok bool
+
+		// old value.
+		oldV V
+
+		// evicted key-values.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		updated func(K, V, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
+	)
+
+	c.locked(func() {
+		var item *Entry[K, V]
+
+		// Check if written since.
+		written := c.revalidating[key]
+		delete(c.revalidating, key)
+
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
+
+		// Check for item in cache
+		item, ok = c.Cache.Get(key)
+		if !ok || written {
+			ok = false
+			return
+		}
+
+		if evc, ev := c.expire(item); ev {
+			// Expired since.
+			kvs = append(kvs, evc)
+			ok = false
+			return
+		}
+
+		// Set old value.
+		oldV = item.Value
+
+		// Update value + expiry.
+		item.Expiry = c.expiry()
+		item.Value = v
+		c.weigh(item)
+		c.index(item)
+
+		// Evict beyond max cost.
+		kvs = append(kvs, c.shed(evict)...)
+	})
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	if ok && updated != nil {
+		// Pass to update hook.
+		updated(key, oldV, v)
+	}
+
+	if ok && observe != nil {
+		// Pass to observer.
+		observe(OpUpdate, key, v)
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+}
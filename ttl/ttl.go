@@ -1,11 +1,31 @@
 package ttl
 
 import (
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
-	_ "unsafe"
+	"unsafe"
 
 	"codeberg.org/gruf/go-maps"
+	"github.com/mkc188/go-cache/v3/logging"
+	"github.com/mkc188/go-cache/v3/snapshot"
+	"github.com/mkc188/go-cache/v3/stats"
+	"github.com/mkc188/go-cache/v3/tinylfu"
+)
+
+// ExpiryPolicy determines whether accessing an entry extends its expiry.
+type ExpiryPolicy uint8
+
+const (
+	// DefaultExpiry uses the cache's policy for an entry, or SlidingExpiry for a cache.
+	DefaultExpiry ExpiryPolicy = iota
+
+	// SlidingExpiry extends an entry's expiry by the TTL on each access.
+	SlidingExpiry
+
+	// AbsoluteExpiry fixes an entry's expiry at the TTL after it was last written, regardless of access.
+	AbsoluteExpiry
 )
 
 // Entry represents an item in the cache, with it's currently calculated Expiry time.
@@ -13,6 +33,33 @@ type Entry[Key comparable, Value any] struct {
 	Key    Key
 	Value  Value
 	Expiry uint64
+
+	// Cost is the recorded time taken to load Value, see SetWithCost().
+	Cost time.Duration
+
+	// Policy overrides the cache's expiry policy for this entry, see SetWithPolicy().
+	Policy ExpiryPolicy
+
+	// Weight is the entry's weight as calculated by the cache Weigher, if any.
+	Weight int64
+
+	// Priority is the entry's eviction priority, lower priority entries are evicted first, see SetWithPriority().
+	Priority int
+
+	// Created is when the entry was first stored, Accessed when last read, in runtime nanoseconds, see EntryInfo().
+	Created  uint64
+	Accessed uint64
+
+	// Hits is the number of times the entry has been read.
+	Hits uint64
+
+	// Deadline is the entry's expiry by TTL alone while an idle timeout is set, Expiry then
+	// being the earlier of this and its last use plus the idle timeout, see SetIdleTimeout().
+	Deadline uint64
+
+	// timer wheel bucket links, see SetTimerWheel().
+	bucket     *bucket[Key, Value]
+	prev, next *Entry[Key, Value]
 }
 
 // Cache is the underlying TTLCache implementation, providing both the base Cache interface and unsafe access to underlying map to allow flexibility in building your own.
@@ -26,6 +73,68 @@ type Cache[Key comparable, Value any] struct {
 	// Invalid is the hook that is called when an item's data in the cache is invalidated, includes Add/Set.
 	Invalid func(Key, Value)
 
+	// Updated is the hook that is called with the old and new values when an existing item's value is overwritten.
+	Updated func(Key, Value, Value)
+
+	// Observer is the hook that is called with each change to cache entries, see SetObserver().
+	Observer func(Op, Key, Value)
+
+	// Policy is the expiry policy of entries without their own, defaulting to SlidingExpiry.
+	Policy ExpiryPolicy
+
+	// SweepBatch is the maximum number of items evicted per lock hold during a sweep, <= 0 evicts all at once.
+	SweepBatch int
+
+	// wheel is the optional timer wheel expiry index, see SetTimerWheel().
+	wheel *wheel[Key, Value]
+
+	// Jitter is the fraction [0, 1) by which each computed expiry is randomized, e.g. 0.1 for +/- 10% of TTL,
+	// spreading the expiry of entries stored together across sweeps.
+	Jitter float64
+
+	// Weigher calculates the weight (e.g. byte size) of an entry, counted against MaxCost. Nil weighs all entries 0.
+	Weigher func(Key, Value) int64
+
+	// MaxCost is the maximum total weight of entries, beyond which the least recently used are evicted. <= 0 is unbounded.
+	MaxCost int64
+
+	// weight is the current total weight of entries.
+	weight int64
+
+	// prioritized is the current number of entries with non-zero priority.
+	prioritized int
+
+	// Admission is the optional filter deciding whether new keys are admitted when the cache is at capacity, nil admits all.
+	Admission *tinylfu.Filter[Key]
+
+	// Stats receives hit, miss, eviction and sweep metrics, nil disables recording.
+	Stats stats.Recorder
+
+	// Log receives sweep events, nil disables logging.
+	Log logging.Logger
+
+	// Idle is the maximum time an entry is kept without being used, <= 0 disables, see SetIdleTimeout().
+	Idle time.Duration
+
+	// Grace is how long entries are served after expiry while revalidated, see SetStaleWhileRevalidate().
+	Grace time.Duration
+
+	// Revalidate is the func refreshing stale entries in the background, nil disables, see SetStaleWhileRevalidate().
+	Revalidate func(Key) (Value, error)
+
+	// revalidating is the set of keys currently being revalidated, with
+	// whether each has since been written (and so must not be overwritten).
+	revalidating map[Key]bool
+
+	// Beta scales how early GetWithRefresh() advises refresh of costly entries, <= 0 is treated as 1.
+	Beta float64
+
+	// Codec encodes entries for Dump() and Restore(), nil uses snapshot.Gob.
+	Codec snapshot.Codec
+
+	// PersistPath is the file the cache is saved to on Stop() and loaded from on Start(), empty disables.
+	PersistPath string
+
 	// Cache is the underlying hashmap used for this cache.
 	Cache maps.LRUMap[Key, *Entry[Key, Value]]
 
@@ -35,6 +144,11 @@ type Cache[Key comparable, Value any] struct {
 	// pool is a memory pool of entry objects.
 	pool []*Entry[Key, Value]
 
+	// size, capacity mirror the length and capacity of Cache,
+	// updated before each lock release, for lock-free Len(), Cap().
+	size     atomic.Int64
+	capacity atomic.Int64
+
 	// Embedded mutex.
 	sync.Mutex
 }
@@ -56,31 +170,59 @@ func (c *Cache[K, V]) Init(len, cap int, ttl time.Duration) {
 	c.SetEvictionCallback(nil)
 	c.SetInvalidateCallback(nil)
 	c.Cache.Init(len, cap)
+	c.size.Store(0)
+	c.capacity.Store(int64(c.Cache.Cap()))
 }
 
-// Start: implements cache.Cache's Start().
+// Start: implements cache.Cache's Start(). If PersistPath is set, the cache is first loaded from it, see Load().
 func (c *Cache[K, V]) Start(freq time.Duration) (ok bool) {
 	// Nothing to start
 	if freq <= 0 {
 		return false
 	}
 
+	var (
+		// persist path.
+		path string
+
+		// logger ptr.
+		log logging.Logger
+	)
+
 	// Safely start
 	c.Lock()
 
 	if ok = (c.stop == nil); ok {
 		// Not yet running, schedule us
 		c.stop = schedule(c.Sweep, freq)
+
+		// Set persist path, logger ptr.
+		path = c.PersistPath
+		log = c.Log
 	}
 
 	// Done with lock
 	c.Unlock()
 
+	if path != "" {
+		if err := c.Load(path); err != nil && log != nil {
+			log.Error("ttl: failed to load persisted cache", "path", path, "err", err)
+		}
+	}
+
 	return
 }
 
-// Stop: implements cache.Cache's Stop().
+// Stop: implements cache.Cache's Stop(). If PersistPath is set, the cache is then saved to it, see Save().
 func (c *Cache[K, V]) Stop() (ok bool) {
+	var (
+		// persist path.
+		path string
+
+		// logger ptr.
+		log logging.Logger
+	)
+
 	// Safely stop
 	c.Lock()
 
@@ -88,71 +230,191 @@ func (c *Cache[K, V]) Stop() (ok bool) {
 		// We're running, cancel evicts
 		c.stop()
 		c.stop = nil
+
+		// Set persist path, logger ptr.
+		path = c.PersistPath
+		log = c.Log
 	}
 
 	// Done with lock
 	c.Unlock()
 
+	if path != "" {
+		if err := c.Save(path); err != nil && log != nil {
+			log.Error("ttl: failed to save persisted cache", "path", path, "err", err)
+		}
+	}
+
 	return
 }
 
-// Sweep attempts to evict expired items (with callback!) from cache.
-func (c *Cache[K, V]) Sweep(_ time.Time) {
+// StopAndFlush performs Stop(), then evicts all remaining entries least recently used first, calling the eviction
+// callback for each, such that no entries are dropped on shutdown without notice (e.g. pending write-behind data).
+// Entries are flushed whether or not the eviction routine was running, returned bool is as Stop().
+func (c *Cache[K, V]) StopAndFlush() (ok bool) {
 	var (
 		// evicted key-values.
 		kvs []kv[K, V]
 
+		// evicted count.
+		n int
+
+		// stats recorder ptr.
+		rec stats.Recorder
+
 		// hook func ptrs.
 		evict func(K, V)
-
-		// get current nanoseconds.
-		now = runtime_nanotime()
 	)
 
+	// Stop routine.
+	ok = c.Stop()
+
 	c.locked(func() {
-		if c.TTL <= 0 {
-			// sweep is
-			// disabled
-			return
+		// Set hook func ptr, stats recorder ptr.
+		evict = c.evictHook()
+		rec = c.Stats
+
+		// Truncate the entire cache length.
+		n = c.Cache.Len()
+		kvs = c.truncate(n, evict)
+	})
+
+	if rec != nil {
+		rec.Count("ttl.evictions", int64(n))
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
 		}
+	}
 
-		// Sentinel value
-		after := -1
+	return
+}
+
+// Sweep attempts to evict expired items (with callback!) from cache. If SweepBatch is set, the lock is released (and
+// eviction callbacks called) after each batch of evictions, continuing the scan from where the previous batch ended.
+func (c *Cache[K, V]) Sweep(_ time.Time) {
+	var (
+		// stats recorder ptr.
+		rec stats.Recorder
+
+		// logger ptr.
+		log logging.Logger
+
+		// evicted, remaining cache lengths.
+		n, l int
 
-		// The cache will be ordered by expiry date, we iterate until we reach the index of
-		// the youngest item that hsa expired, as all succeeding items will also be expired.
-		c.Cache.RangeIf(0, c.Cache.Len(), func(i int, _ K, item *Entry[K, V]) bool {
-			if now > item.Expiry {
-				after = i
+		// unexpired items scanned
+		// from the tail of the cache.
+		kept int
 
-				// evict all older items
-				// than this (inclusive)
-				return false
+		// get current nanoseconds.
+		now = runtime_nanotime()
+	)
+
+	for more := true; more; {
+		var (
+			// evicted key-values.
+			kvs []kv[K, V]
+
+			// hook func ptrs.
+			evict func(K, V)
+		)
+
+		c.locked(func() {
+			if c.TTL <= 0 {
+				// sweep is
+				// disabled
+				more = false
+				return
 			}
 
-			// cont. loop.
-			return true
+			// Set hook func, stats recorder, logger ptrs.
+			evict = c.evictHook()
+			rec = c.Stats
+			log = c.Log
+
+			// Evict next batch of expired.
+			var batch int
+			kvs, batch, more = c.sweep(now, &kept, evict)
+			n += batch
+			l = c.Cache.Len()
 		})
 
-		if after == -1 {
-			// No Truncation needed
-			return
+		if evict != nil {
+			for x := range kvs {
+				// Pass to eviction hook.
+				evict(kvs[x].K, kvs[x].V)
+			}
 		}
+	}
 
-		// Set hook func ptr.
-		evict = c.Evict
+	if log != nil && n > 0 {
+		log.Debug("ttl: swept expired items", "evicted", n, "remaining", l)
+	}
+
+	if rec != nil {
+		rec.Count("ttl.evictions", int64(n))
+		rec.Gauge("ttl.len", float64(l))
+		rec.Timing("ttl.sweep", time.Duration(runtime_nanotime()-now))
+	}
+}
+
+// sweep evicts up to SweepBatch items expired as of now, scanning from the least recently used item after skipping the
+// kept count of unexpired items already scanned, returning evicted items if hook is set, the evicted count, and whether
+// the batch limit was reached (NOTE: requires lock). Entries with absolute expiry are not extended on access, so the
+// cache is not necessarily ordered by expiry date, we must check every entry for expiry.
+func (c *Cache[K, V]) sweep(now uint64, kept *int, hook func(K, V)) ([]kv[K, V], int, bool) {
+	if c.wheel != nil {
+		// Use expiry index.
+		return c.sweepWheel(now, hook)
+	}
+
+	// Start index, skipping scanned.
+	start := c.Cache.Len() - 1 - *kept
+	if start < 0 {
+		return nil, 0, false
+	}
+
+	var expired []*Entry[K, V]
+
+	c.Cache.RangeIf(start, -(start + 1), func(_ int, _ K, item *Entry[K, V]) bool {
+		if now > item.Expiry {
+			expired = append(expired, item)
+
+			// cont. loop until batch full.
+			return c.SweepBatch <= 0 || len(expired) < c.SweepBatch
+		}
 
-		// Truncate determined size.
-		sz := c.Cache.Len() - after
-		kvs = c.truncate(sz, evict)
+		// cont. loop.
+		*kept++
+		return true
 	})
 
-	if evict != nil {
-		for x := range kvs {
-			// Pass to eviction hook.
-			evict(kvs[x].K, kvs[x].V)
+	var kvs []kv[K, V]
+
+	if hook != nil {
+		// Allocate a slice for evicted k-v pairs.
+		kvs = make([]kv[K, V], 0, len(expired))
+	}
+
+	for _, item := range expired {
+		if hook != nil {
+			// Store key-value pair for later access.
+			kvs = append(kvs, kv[K, V]{
+				K: item.Key,
+				V: item.Value,
+			})
 		}
+
+		// Drop and free entry.
+		c.Cache.Delete(item.Key)
+		c.free(item)
 	}
+
+	return kvs, len(expired), c.SweepBatch > 0 && len(expired) >= c.SweepBatch
 }
 
 // SetEvictionCallback: implements cache.Cache's SetEvictionCallback().
@@ -169,6 +431,103 @@ func (c *Cache[K, V]) SetInvalidateCallback(hook func(K, V)) {
 	})
 }
 
+// SetUpdateCallback sets the update callback to the provided hook, called (after the invalidate callback) with
+// the old and new values whenever an existing entry's value is overwritten, i.e. by Set(), SetMany(), CAS(),
+// Swap(), Update() and Merge().
+func (c *Cache[K, V]) SetUpdateCallback(hook func(key K, old V, new V)) {
+	c.locked(func() {
+		c.Updated = hook
+	})
+}
+
+// SetExpiryPolicy sets the expiry policy of entries without their own, intended to be set after Init().
+func (c *Cache[K, V]) SetExpiryPolicy(policy ExpiryPolicy) {
+	c.locked(func() {
+		c.Policy = policy
+	})
+}
+
+// SetWeigher sets the Weigher and MaxCost, re-weighing all current entries and evicting beyond max cost.
+func (c *Cache[K, V]) SetWeigher(weigher func(K, V) int64, maxCost int64) {
+	var (
+		// evicted beyond max cost.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		evict func(K, V)
+	)
+
+	c.locked(func() {
+		c.Weigher = weigher
+		c.MaxCost = maxCost
+
+		// Re-weigh all entries with new weigher.
+		c.Cache.Range(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+			c.weigh(item)
+		})
+
+		// Set hook func ptr.
+		evict = c.evictHook()
+
+		// Evict beyond max cost.
+		kvs = c.shed(evict)
+	})
+
+	for x := range kvs {
+		// Pass to eviction hook.
+		evict(kvs[x].K, kvs[x].V)
+	}
+}
+
+// Weight returns the current total weight of entries, see Weigher.
+func (c *Cache[K, V]) Weight() (w int64) {
+	c.locked(func() { w = c.weight })
+	return
+}
+
+// SetAdmissionFilter sets the filter deciding whether new keys are admitted when the cache is at capacity,
+// nil admits all. Note that when rejected, Add() returns false and Set() is a no-op.
+func (c *Cache[K, V]) SetAdmissionFilter(filter *tinylfu.Filter[K]) {
+	c.locked(func() {
+		c.Admission = filter
+	})
+}
+
+// SetSweepBatch sets the maximum number of items evicted per lock hold during a sweep, see SweepBatch.
+func (c *Cache[K, V]) SetSweepBatch(n int) {
+	c.locked(func() {
+		c.SweepBatch = n
+	})
+}
+
+// SetJitter sets the fraction [0, 1) by which each computed expiry is randomized, see Jitter.
+func (c *Cache[K, V]) SetJitter(jitter float64) {
+	c.locked(func() {
+		c.Jitter = jitter
+	})
+}
+
+// SetStatsRecorder sets the recorder receiving cache metrics, nil disables recording.
+func (c *Cache[K, V]) SetStatsRecorder(rec stats.Recorder) {
+	c.locked(func() {
+		c.Stats = rec
+	})
+}
+
+// SetLogger sets the logger receiving sweep events, nil disables logging.
+func (c *Cache[K, V]) SetLogger(log logging.Logger) {
+	c.locked(func() {
+		c.Log = log
+	})
+}
+
+// SetCodec sets the codec used to encode entries for Dump() and Restore(), nil uses snapshot.Gob.
+func (c *Cache[K, V]) SetCodec(codec snapshot.Codec) {
+	c.locked(func() {
+		c.Codec = codec
+	})
+}
+
 // SetTTL: implements cache.Cache's SetTTL().
 func (c *Cache[K, V]) SetTTL(ttl time.Duration, update bool) {
 	c.locked(func() {
@@ -179,7 +538,8 @@ func (c *Cache[K, V]) SetTTL(ttl time.Duration, update bool) {
 		if update {
 			// Update existing cache entries with new expiry time
 			c.Cache.Range(0, c.Cache.Len(), func(i int, _ K, item *Entry[K, V]) {
-				item.Expiry += uint64(diff)
+				item.Expiry = c.deadline(item) + uint64(diff)
+				c.index(item)
 			})
 		}
 	})
@@ -193,27 +553,150 @@ func (c *Cache[K, V]) Get(key K) (V, bool) {
 
 		// cached value.
 		v V
+
+		// was entry expired?
+		ev bool
+
+		// expired key value.
+		evc kv[K, V]
+
+		// hook func ptrs.
+		evict      func(K, V)
+		revalidate func(K) (V, error)
+
+		// stats recorder ptr.
+		rec stats.Recorder
 	)
 
 	c.locked(func() {
 		var item *Entry[K, V]
 
+		// Set stats recorder ptr.
+		rec = c.Stats
+
+		if c.Admission != nil {
+			// Record access.
+			c.Admission.Record(key)
+		}
+
 		// Check for item in cache
 		item, ok = c.Cache.Get(key)
 		if !ok {
 			return
 		}
 
+		if evc, ev = c.expire(item); ev {
+			// Set hook func ptr.
+			evict = c.evictHook()
+			ok = false
+			return
+		}
+
+		// Check if stale.
+		revalidate = c.stale(item)
+
 		// Update fetched's expiry
-		item.Expiry = c.expiry()
+		c.access(item)
 
 		// Set value.
 		v = item.Value
 	})
 
+	if revalidate != nil {
+		// Refresh in background.
+		c.revalidate(key, revalidate)
+	}
+
+	if ev && evict != nil {
+		// Pass to eviction hook.
+		evict(evc.K, evc.V)
+	}
+
+	if rec != nil {
+		recordLookup(rec, ok)
+	}
+
 	return v, ok
 }
 
+// GetMany fetches the values with keys from the cache as Get(), under a single lock acquisition, returning those found.
+func (c *Cache[K, V]) GetMany(keys ...K) map[K]V {
+	var (
+		// found values.
+		values = make(map[K]V, len(keys))
+
+		// number of hits.
+		hits int
+
+		// expired key-values.
+		kvs []kv[K, V]
+
+		// stale key-values to revalidate.
+		stale []kv[K, func(K) (V, error)]
+
+		// hook func ptrs.
+		evict func(K, V)
+
+		// stats recorder ptr.
+		rec stats.Recorder
+	)
+
+	c.locked(func() {
+		// Set hook func, stats recorder ptrs.
+		evict = c.evictHook()
+		rec = c.Stats
+
+		for _, key := range keys {
+			if c.Admission != nil {
+				// Record access.
+				c.Admission.Record(key)
+			}
+
+			// Check for item in cache
+			item, ok := c.Cache.Get(key)
+			if !ok {
+				continue
+			}
+
+			if evc, ev := c.expire(item); ev {
+				kvs = append(kvs, evc)
+				continue
+			}
+
+			if fn := c.stale(item); fn != nil {
+				// Store stale key for later access.
+				stale = append(stale, kv[K, func(K) (V, error)]{K: key, V: fn})
+			}
+
+			// Update fetched's expiry
+			c.access(item)
+
+			// Set value.
+			values[key] = item.Value
+			hits++
+		}
+	})
+
+	for x := range stale {
+		// Refresh in background.
+		c.revalidate(stale[x].K, stale[x].V)
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
+	}
+
+	if rec != nil {
+		rec.Count("ttl.hits", int64(hits))
+		rec.Count("ttl.misses", int64(len(keys)-hits))
+	}
+
+	return values
+}
+
 // Add: implements cache.Cache's Add().
 func (c *Cache[K, V]) Add(key K, value V) bool {
 	var (
@@ -227,8 +710,12 @@ func (c *Cache[K, V]) Add(key K, value V) bool {
 		evcK K
 		evcV V
 
+		// evicted beyond max cost.
+		kvs []kv[K, V]
+
 		// hook func ptrs.
-		evict func(K, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
 	)
 
 	c.locked(func() {
@@ -238,85 +725,438 @@ func (c *Cache[K, V]) Add(key K, value V) bool {
 			return
 		}
 
+		if !c.admit(key) {
+			// Rejected, report
+			// as not added.
+			ok = true
+			return
+		}
+
 		// Alloc new entry.
 		new := c.alloc()
 		new.Expiry = c.expiry()
 		new.Key = key
 		new.Value = value
+		c.weigh(new)
+		c.index(new)
+
+		// Make room by priority.
+		if evc, evicted := c.evictVictim(); evicted {
+			evcK, evcV, ev = evc.K, evc.V, true
+		}
+
+		// Add new entry to cache and catched any evicted item.
+		c.Cache.SetWithHook(key, new, func(_ K, item *Entry[K, V]) {
+			evcK = item.Key
+			evcV = item.Value
+			ev = true
+			c.free(item)
+		})
+
+		// Set hook func ptrs.
+		evict = c.evictHook()
+		observe = c.Observer
+
+		// Evict beyond max cost.
+		kvs = c.shed(evict)
+	})
+
+	if observe != nil {
+		// Pass to observer.
+		observe(OpAdd, key, value)
+	}
+
+	if ev && evict != nil {
+		// Pass to eviction hook.
+		evict(evcK, evcV)
+	}
+
+	for x := range kvs {
+		// Pass to eviction hook.
+		evict(kvs[x].K, kvs[x].V)
+	}
+
+	return !ok
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.set(key, value, 0, DefaultExpiry, 0)
+}
+
+// SetWithCost performs Set(), recording the time taken to load value for use in early refresh, see GetWithRefresh().
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost time.Duration) {
+	c.set(key, value, cost, DefaultExpiry, 0)
+}
+
+// SetWithPolicy performs Set(), overriding the cache's expiry policy for this entry until next written.
+func (c *Cache[K, V]) SetWithPolicy(key K, value V, policy ExpiryPolicy) {
+	c.set(key, value, 0, policy, 0)
+}
+
+// SetWithPriority performs Set(), with given eviction priority for this entry until next written. When the cache
+// is at capacity (or MaxCost) the least recently used entry of lowest priority is evicted, default priority is 0.
+func (c *Cache[K, V]) SetWithPriority(key K, value V, priority int) {
+	c.set(key, value, 0, DefaultExpiry, priority)
+}
+
+// set places value at key in the cache with given load cost, expiry policy and eviction priority, returning whether
+// it was stored, i.e. neither rejected by the admission filter nor immediately evicted beyond MaxCost.
+func (c *Cache[K, V]) set(key K, value V, cost time.Duration, policy ExpiryPolicy, priority int) (stored bool) {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// was entry added?
+		added bool
+
+		// was entry evicted?
+		ev bool
+
+		// old value.
+		oldV V
+
+		// evicted key values.
+		evcK K
+		evcV V
+
+		// evicted beyond max cost.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		updated func(K, V, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
+	)
+
+	c.locked(func() {
+		var item *Entry[K, V]
+
+		// Check for item in cache
+		item, ok = c.Cache.Get(key)
+
+		if ok {
+			// Set old value.
+			oldV = item.Value
+
+			// Update the existing item.
+			item.Expiry = c.expiry()
+			item.Value = value
+			item.Cost = cost
+			item.Policy = policy
+			c.weigh(item)
+			c.index(item)
+			c.prioritize(item, priority)
+		} else if c.admit(key) {
+			// Alloc new entry.
+			new := c.alloc()
+			new.Expiry = c.expiry()
+			new.Key = key
+			new.Value = value
+			new.Cost = cost
+			new.Policy = policy
+			c.weigh(new)
+			c.index(new)
+
+			// Make room by priority.
+			if evc, evicted := c.evictVictim(); evicted {
+				evcK, evcV, ev = evc.K, evc.V, true
+			}
+
+			// Set entry priority.
+			c.prioritize(new, priority)
+
+			// Add new entry to cache and catched any evicted item.
+			c.Cache.SetWithHook(key, new, func(_ K, item *Entry[K, V]) {
+				evcK = item.Key
+				evcV = item.Value
+				ev = true
+				c.free(item)
+			})
+			added = true
+		}
+
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
+
+		// Evict beyond max cost.
+		kvs = c.shed(evict)
+
+		// Check still stored.
+		stored = c.Cache.Has(key)
+	})
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	if ok && updated != nil {
+		// Pass to update hook.
+		updated(key, oldV, value)
+	}
+
+	if observe != nil {
+		// Pass to observer.
+		if ok {
+			observe(OpUpdate, key, value)
+		} else if added {
+			observe(OpAdd, key, value)
+		}
+	}
+
+	if ev && evict != nil {
+		// Pass to eviction hook.
+		evict(evcK, evcV)
+	}
+
+	for x := range kvs {
+		// Pass to eviction hook.
+		evict(kvs[x].K, kvs[x].V)
+	}
+
+	return
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// swapped value.
+		oldV V
+
+		// evicted beyond max cost.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		updated func(K, V, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
+	)
+
+	c.locked(func() {
+		var item *Entry[K, V]
+
+		// Check for item in cache
+		item, ok = c.Cache.Get(key)
+		if !ok {
+			return
+		}
+
+		// Perform the comparison
+		if !cmp(old, item.Value) {
+			return
+		}
+
+		// Set old value.
+		oldV = item.Value
+
+		// Update value + expiry.
+		item.Expiry = c.expiry()
+		item.Value = new
+		c.weigh(item)
+		c.index(item)
+
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
+
+		// Evict beyond max cost.
+		kvs = c.shed(evict)
+	})
+
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	if ok && updated != nil {
+		// Pass to update hook.
+		updated(key, oldV, new)
+	}
+
+	if observe != nil {
+		// Pass to observer.
+		observe(OpUpdate, key, new)
+	}
+
+	for x := range kvs {
+		// Pass to eviction hook.
+		evict(kvs[x].K, kvs[x].V)
+	}
+
+	return ok
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) V {
+	var (
+		// did exist in cache?
+		ok bool
+
+		// swapped value.
+		oldV V
+
+		// evicted beyond max cost.
+		kvs []kv[K, V]
+
+		// hook func ptrs.
+		invalid func(K, V)
+		updated func(K, V, V)
+		evict   func(K, V)
+		observe func(Op, K, V)
+	)
+
+	c.locked(func() {
+		var item *Entry[K, V]
+
+		// Check for item in cache
+		item, ok = c.Cache.Get(key)
+		if !ok {
+			return
+		}
+
+		// Set old value.
+		oldV = item.Value
+
+		// Update value + expiry.
+		item.Expiry = c.expiry()
+		item.Value = swp
+		c.weigh(item)
+		c.index(item)
 
-		// Add new entry to cache and catched any evicted item.
-		c.Cache.SetWithHook(key, new, func(_ K, item *Entry[K, V]) {
-			evcK = item.Key
-			evcV = item.Value
-			ev = true
-			c.free(item)
-		})
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
 
-		// Set hook func ptr.
-		evict = c.Evict
+		// Evict beyond max cost.
+		kvs = c.shed(evict)
 	})
 
-	if ev && evict != nil {
+	if ok && invalid != nil {
+		// Pass to invalidate hook.
+		invalid(key, oldV)
+	}
+
+	if ok && updated != nil {
+		// Pass to update hook.
+		updated(key, oldV, swp)
+	}
+
+	if observe != nil {
+		// Pass to observer.
+		observe(OpUpdate, key, swp)
+	}
+
+	for x := range kvs {
 		// Pass to eviction hook.
-		evict(evcK, evcV)
+		evict(kvs[x].K, kvs[x].V)
 	}
 
-	return !ok
+	return oldV
 }
 
-// Set: implements cache.Cache's Set().
-func (c *Cache[K, V]) Set(key K, value V) {
+// Update performs a read-modify-write of the value with key under the cache lock. The fn is passed the current value
+// and whether it exists, the returned value is then stored as Set() if the returned bool is true, otherwise any existing
+// value is deleted, calling the invalidate callback for replaced / deleted values. Returns the stored value and bool.
+// As fn is called with the cache locked, it MUST NOT call any cache methods.
+func (c *Cache[K, V]) Update(key K, fn func(old V, ok bool) (V, bool)) (V, bool) {
 	var (
 		// did exist in cache?
 		ok bool
 
-		// was entry evicted?
-		ev bool
+		// store new value?
+		store bool
 
-		// old value.
+		// old, new values.
 		oldV V
+		newV V
 
-		// evicted key values.
-		evcK K
-		evcV V
+		// evicted key-values.
+		kvs []kv[K, V]
 
 		// hook func ptrs.
 		invalid func(K, V)
+		updated func(K, V, V)
 		evict   func(K, V)
+		observe func(Op, K, V)
 	)
 
 	c.locked(func() {
 		var item *Entry[K, V]
 
+		// Set hook func ptrs.
+		invalid = c.Invalid
+		updated = c.Updated
+		evict = c.evictHook()
+		observe = c.Observer
+
 		// Check for item in cache
 		item, ok = c.Cache.Get(key)
 
 		if ok {
-			// Set old value.
-			oldV = item.Value
+			if evc, ev := c.expire(item); ev {
+				// Expired, treat as missing.
+				kvs = append(kvs, evc)
+				ok = false
+			} else {
+				// Set old value.
+				oldV = item.Value
+			}
+		}
 
+		// Compute new value.
+		newV, store = fn(oldV, ok)
+
+		switch {
+		case store && ok:
 			// Update the existing item.
 			item.Expiry = c.expiry()
-			item.Value = value
-		} else {
+			item.Value = newV
+			c.weigh(item)
+			c.index(item)
+
+		case store:
 			// Alloc new entry.
 			new := c.alloc()
 			new.Expiry = c.expiry()
 			new.Key = key
-			new.Value = value
+			new.Value = newV
+			c.weigh(new)
+			c.index(new)
 
-			// Add new entry to cache and catched any evicted item.
+			// Make room by priority.
+			if evc, evicted := c.evictVictim(); evicted {
+				kvs = append(kvs, evc)
+			}
+
+			// Add new entry to cache and catch any evicted item.
 			c.Cache.SetWithHook(key, new, func(_ K, item *Entry[K, V]) {
-				evcK = item.Key
-				evcV = item.Value
-				ev = true
+				kvs = append(kvs, kv[K, V]{K: item.Key, V: item.Value})
 				c.free(item)
 			})
+
+		case ok:
+			// Remove from cache map
+			_ = c.Cache.Delete(key)
+
+			// Free entry
+			c.free(item)
 		}
 
-		// Set hook func ptrs.
-		invalid = c.Invalid
-		evict = c.Evict
+		// Evict beyond max cost.
+		kvs = append(kvs, c.shed(evict)...)
 	})
 
 	if ok && invalid != nil {
@@ -324,65 +1164,146 @@ func (c *Cache[K, V]) Set(key K, value V) {
 		invalid(key, oldV)
 	}
 
-	if ev && evict != nil {
-		// Pass to eviction hook.
-		evict(evcK, evcV)
+	if store && ok && updated != nil {
+		// Pass to update hook.
+		updated(key, oldV, newV)
+	}
+
+	if observe != nil {
+		// Pass to observer.
+		switch {
+		case store && ok:
+			observe(OpUpdate, key, newV)
+		case store:
+			observe(OpAdd, key, newV)
+		case ok:
+			observe(OpInvalidate, key, oldV)
+		}
+	}
+
+	if evict != nil {
+		for x := range kvs {
+			// Pass to eviction hook.
+			evict(kvs[x].K, kvs[x].V)
+		}
 	}
+
+	if !store {
+		var zero V
+		newV = zero
+	}
+
+	return newV, store
 }
 
-// CAS: implements cache.Cache's CAS().
-func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+// Has: implements cache.Cache's Has(). Expired entries not yet swept are evicted inline.
+func (c *Cache[K, V]) Has(key K) (ok bool) {
 	var (
-		// did exist in cache?
-		ok bool
+		// was entry expired?
+		ev bool
 
-		// swapped value.
-		oldV V
+		// expired key value.
+		evc kv[K, V]
 
 		// hook func ptrs.
-		invalid func(K, V)
+		evict func(K, V)
 	)
 
 	c.locked(func() {
 		var item *Entry[K, V]
 
-		// Check for item in cache
-		item, ok = c.Cache.Get(key)
+		// Check for item in cache,
+		// without marking as used.
+		item, ok = c.peek(key)
 		if !ok {
 			return
 		}
 
-		// Perform the comparison
-		if !cmp(old, item.Value) {
-			return
+		if evc, ev = c.expire(item); ev {
+			// Set hook func ptr.
+			evict = c.evictHook()
+			ok = false
 		}
+	})
 
-		// Set old value.
-		oldV = item.Value
+	if ev && evict != nil {
+		// Pass to eviction hook.
+		evict(evc.K, evc.V)
+	}
 
-		// Update value + expiry.
-		item.Expiry = c.expiry()
-		item.Value = new
+	return
+}
 
-		// Set hook func ptr.
-		invalid = c.Invalid
+// Touch resets the expiry of key to the cache TTL from now, regardless of expiry policy and without calling
+// the invalidate hook, e.g. to keep an entry alive during a long-running operation. Returns false if not found.
+func (c *Cache[K, V]) Touch(key K) (ok bool) {
+	c.locked(func() {
+		var item *Entry[K, V]
+		if item, ok = c.Cache.Get(key); ok {
+			item.Expiry = c.expiry()
+			c.index(item)
+		}
 	})
+	return
+}
 
-	if ok && invalid != nil {
-		// Pass to invalidate hook.
-		invalid(key, oldV)
-	}
+// Extend adds d to the current expiry of key, regardless of expiry policy and without calling
+// the invalidate hook. Entries without expiry are unchanged. Returns false if not found.
+func (c *Cache[K, V]) Extend(key K, d time.Duration) (ok bool) {
+	c.locked(func() {
+		var item *Entry[K, V]
+		if item, ok = c.Cache.Get(key); ok && item.Expiry != 0 {
+			item.Expiry = c.deadline(item) + uint64(d)
+			c.index(item)
+		}
+	})
+	return
+}
 
-	return ok
+// SetExpiry sets key to expire at given time, e.g. to align with an upstream token's expiry, without calling the
+// invalidate hook. The entry's expiry policy becomes absolute so access does not extend it, until next written.
+// Times in the past expire the entry immediately. Returns false if not found.
+func (c *Cache[K, V]) SetExpiry(key K, at time.Time) (ok bool) {
+	c.locked(func() {
+		var item *Entry[K, V]
+		if item, ok = c.Cache.Get(key); !ok {
+			return
+		}
+
+		d := time.Until(at)
+		if d < 0 {
+			// Already passed.
+			d = 0
+		}
+
+		item.Expiry = runtime_nanotime() + uint64(d+c.Grace)
+		item.Policy = AbsoluteExpiry
+		c.index(item)
+	})
+	return
 }
 
-// Swap: implements cache.Cache's Swap().
-func (c *Cache[K, V]) Swap(key K, swp V) V {
-	var (
-		// did exist in cache?
-		ok bool
+// Range calls fn for each unexpired entry in the cache, most recently used first, until fn returns false.
+// This does not extend entry TTLs. The cache is locked during iteration, so fn MUST NOT call any cache methods.
+func (c *Cache[K, V]) Range(fn func(key K, value V) bool) {
+	c.locked(func() {
+		// get current nanoseconds.
+		now := runtime_nanotime()
 
-		// swapped value.
+		c.Cache.RangeIf(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) bool {
+			if item.Expiry != 0 && now > item.Expiry {
+				// skip expired.
+				return true
+			}
+			return fn(item.Key, item.Value)
+		})
+	})
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
+	var (
+		// old value.
 		oldV V
 
 		// hook func ptrs.
@@ -401,12 +1322,14 @@ func (c *Cache[K, V]) Swap(key K, swp V) V {
 		// Set old value.
 		oldV = item.Value
 
-		// Update value + expiry.
-		item.Expiry = c.expiry()
-		item.Value = swp
+		// Remove from cache map
+		_ = c.Cache.Delete(key)
 
-		// Set hook func ptr.
-		invalid = c.Invalid
+		// Free entry
+		c.free(item)
+
+		// Set hook func ptrs.
+		invalid = c.invalidHook()
 	})
 
 	if ok && invalid != nil {
@@ -414,19 +1337,12 @@ func (c *Cache[K, V]) Swap(key K, swp V) V {
 		invalid(key, oldV)
 	}
 
-	return oldV
-}
-
-// Has: implements cache.Cache's Has().
-func (c *Cache[K, V]) Has(key K) (ok bool) {
-	c.locked(func() {
-		ok = c.Cache.Has(key)
-	})
 	return
 }
 
-// Invalidate: implements cache.Cache's Invalidate().
-func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
+// CompareAndDelete deletes the value with key from the cache only if eq reports it equal to cmp, calling the
+// invalidate callback. Returned bool is whether the value was deleted.
+func (c *Cache[K, V]) CompareAndDelete(key K, cmp V, eq func(V, V) bool) (ok bool) {
 	var (
 		// old value.
 		oldV V
@@ -444,6 +1360,11 @@ func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
 			return
 		}
 
+		// Perform the comparison
+		if ok = eq(cmp, item.Value); !ok {
+			return
+		}
+
 		// Set old value.
 		oldV = item.Value
 
@@ -454,7 +1375,7 @@ func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
 		c.free(item)
 
 		// Set hook func ptrs.
-		invalid = c.Invalid
+		invalid = c.invalidHook()
 	})
 
 	if ok && invalid != nil {
@@ -465,8 +1386,14 @@ func (c *Cache[K, V]) Invalidate(key K) (ok bool) {
 	return
 }
 
-// InvalidateAll: implements cache.Cache's InvalidateAll().
-func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
+// InvalidateAll: implements cache.Cache's InvalidateAll(), returning whether any values were invalidated.
+func (c *Cache[K, V]) InvalidateAll(keys ...K) bool {
+	return c.InvalidateMany(keys...) > 0
+}
+
+// InvalidateMany deletes the values with keys from the cache under a single lock acquisition, calling the
+// invalidate callback for each once all are deleted, returning the number of values invalidated.
+func (c *Cache[K, V]) InvalidateMany(keys ...K) int {
 	var (
 		// invalidated kvs.
 		kvs []kv[K, V]
@@ -480,10 +1407,8 @@ func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
 
 	c.locked(func() {
 		for x := range keys {
-			var item *Entry[K, V]
-
 			// Check for item in cache
-			item, ok = c.Cache.Get(keys[x])
+			item, ok := c.Cache.Get(keys[x])
 			if !ok {
 				continue
 			}
@@ -502,7 +1427,7 @@ func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
 		}
 
 		// Set hook func ptrs.
-		invalid = c.Invalid
+		invalid = c.invalidHook()
 	})
 
 	if invalid != nil {
@@ -512,7 +1437,7 @@ func (c *Cache[K, V]) InvalidateAll(keys ...K) (ok bool) {
 		}
 	}
 
-	return
+	return len(kvs)
 }
 
 // Clear: implements cache.Cache's Clear().
@@ -527,7 +1452,7 @@ func (c *Cache[K, V]) Clear() {
 
 	c.locked(func() {
 		// Set hook func ptr.
-		invalid = c.Invalid
+		invalid = c.invalidHook()
 
 		// Truncate the entire cache length.
 		kvs = c.truncate(c.Cache.Len(), invalid)
@@ -541,22 +1466,22 @@ func (c *Cache[K, V]) Clear() {
 	}
 }
 
-// Len: implements cache.Cache's Len().
-func (c *Cache[K, V]) Len() (l int) {
-	c.locked(func() { l = c.Cache.Len() })
-	return
+// Len: implements cache.Cache's Len(). This does not take the lock, reflecting changes made through cache methods
+// (i.e. not those made directly to the underlying Cache) as of their completion.
+func (c *Cache[K, V]) Len() int {
+	return int(c.size.Load())
 }
 
-// Cap: implements cache.Cache's Cap().
-func (c *Cache[K, V]) Cap() (l int) {
-	c.locked(func() { l = c.Cache.Cap() })
-	return
+// Cap: implements cache.Cache's Cap(). This does not take the lock.
+func (c *Cache[K, V]) Cap() int {
+	return int(c.capacity.Load())
 }
 
-// locked performs given function within mutex lock (NOTE: UNLOCK IS NOT DEFERRED).
+// locked performs given function within mutex lock, updating the cache size (NOTE: UNLOCK IS NOT DEFERRED).
 func (c *Cache[K, V]) locked(fn func()) {
 	c.Lock()
 	fn()
+	c.size.Store(int64(c.Cache.Len()))
 	c.Unlock()
 }
 
@@ -585,14 +1510,15 @@ func (c *Cache[K, V]) truncate(sz int, hook func(K, V)) []kv[K, V] {
 	return deleted
 }
 
-// alloc will acquire cache entry from pool, or allocate new.
+// alloc will acquire cache entry from pool, or allocate new, with its creation time set.
 func (c *Cache[K, V]) alloc() *Entry[K, V] {
 	if len(c.pool) == 0 {
-		return &Entry[K, V]{}
+		return &Entry[K, V]{Created: runtime_nanotime()}
 	}
 	idx := len(c.pool) - 1
 	e := c.pool[idx]
 	c.pool = c.pool[:idx]
+	e.Created = runtime_nanotime()
 	return e
 }
 
@@ -602,6 +1528,14 @@ func (c *Cache[K, V]) clone(e *Entry[K, V]) *Entry[K, V] {
 	e2.Key = e.Key
 	e2.Value = e.Value
 	e2.Expiry = e.Expiry
+	e2.Cost = e.Cost
+	e2.Policy = e.Policy
+	e2.Weight = e.Weight
+	e2.Created = e.Created
+	e2.Accessed = e.Accessed
+	e2.Hits = e.Hits
+	e2.Deadline = e.Deadline
+	c.prioritize(e2, e.Priority)
 	return e2
 }
 
@@ -611,26 +1545,240 @@ func (c *Cache[K, V]) free(e *Entry[K, V]) {
 		zk K
 		zv V
 	)
+	if c.wheel != nil {
+		c.wheel.remove(e)
+	}
 	e.Expiry = 0
+	e.Created = 0
+	e.Accessed = 0
+	e.Hits = 0
+	e.Deadline = 0
+	e.Cost = 0
+	e.Policy = DefaultExpiry
+	c.weight -= e.Weight
+	e.Weight = 0
+	c.prioritize(e, 0)
 	e.Key = zk
 	e.Value = zv
 	c.pool = append(c.pool, e)
 }
 
+// weigh updates item's weight, and the cache's total weight, using the Weigher (NOTE: requires lock).
+func (c *Cache[K, V]) weigh(item *Entry[K, V]) {
+	var w int64
+	if c.Weigher != nil {
+		w = c.Weigher(item.Key, item.Value)
+	}
+	c.weight += w - item.Weight
+	item.Weight = w
+
+	if _, ok := c.revalidating[item.Key]; ok {
+		// Written during revalidation.
+		c.revalidating[item.Key] = true
+	}
+}
+
+// shed evicts victim entries (see victim()) until the total weight is within MaxCost, returning evicted items if
+// hook is set. Entries heavier than MaxCost on their own are therefore evicted immediately (NOTE: requires lock).
+func (c *Cache[K, V]) shed(hook func(K, V)) []kv[K, V] {
+	var kvs []kv[K, V]
+
+	for c.MaxCost > 0 && c.weight > c.MaxCost && c.Cache.Len() > 0 {
+		// Get next to evict.
+		item := c.victim()
+
+		if hook != nil {
+			// Store key-value pair for later access.
+			kvs = append(kvs, kv[K, V]{
+				K: item.Key,
+				V: item.Value,
+			})
+		}
+
+		// Drop and free entry.
+		c.Cache.Delete(item.Key)
+		c.free(item)
+	}
+
+	return kvs
+}
+
+// admit records a write of new key in the admission filter (if any), returning whether it may be inserted, i.e.
+// the cache has room or the filter admits it in place of the least recently used entry (NOTE: requires lock).
+func (c *Cache[K, V]) admit(key K) bool {
+	if c.Admission == nil {
+		return true
+	}
+
+	// Record access.
+	c.Admission.Record(key)
+
+	if l := c.Cache.Len(); l == 0 || l < c.Cache.Cap() {
+		// Room available.
+		return true
+	}
+
+	// Get eviction victim.
+	victim := c.victim()
+
+	return c.Admission.Admit(key, victim.Key)
+}
+
+// prioritize sets item's eviction priority, tracking the number of entries with non-zero priority (NOTE: requires lock).
+func (c *Cache[K, V]) prioritize(item *Entry[K, V], priority int) {
+	if item.Priority != 0 {
+		c.prioritized--
+	}
+	if priority != 0 {
+		c.prioritized++
+	}
+	item.Priority = priority
+}
+
+// victim returns the next entry to evict, i.e. the least recently used of those with lowest
+// priority, the cache MUST NOT be empty. Without priorities this is O(1) (NOTE: requires lock).
+func (c *Cache[K, V]) victim() (victim *Entry[K, V]) {
+	if c.prioritized == 0 {
+		// Get least recently used.
+		c.Cache.Range(c.Cache.Len()-1, 1, func(_ int, _ K, item *Entry[K, V]) {
+			victim = item
+		})
+		return
+	}
+
+	// Scan from least recently used for lowest priority.
+	c.Cache.Range(c.Cache.Len()-1, -c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+		if victim == nil || item.Priority < victim.Priority {
+			victim = item
+		}
+	})
+
+	return
+}
+
+// evictVictim evicts the victim entry if the cache is at capacity and any entries have priorities, as the underlying
+// map would otherwise evict the least recently used on insert, returning its key-value and true if so (NOTE: requires lock).
+func (c *Cache[K, V]) evictVictim() (kv[K, V], bool) {
+	if c.prioritized == 0 || c.Cache.Len() < c.Cache.Cap() {
+		return kv[K, V]{}, false
+	}
+
+	item := c.victim()
+	evc := kv[K, V]{K: item.Key, V: item.Value}
+
+	// Drop and free entry.
+	c.Cache.Delete(item.Key)
+	c.free(item)
+
+	return evc, true
+}
+
+// access records a read of item, updating its expiry per its expiry policy (NOTE: requires lock).
+func (c *Cache[K, V]) access(item *Entry[K, V]) {
+	item.Accessed = runtime_nanotime()
+	item.Hits++
+
+	policy := item.Policy
+	if policy == DefaultExpiry {
+		policy = c.Policy
+	}
+
+	if policy != AbsoluteExpiry {
+		item.Expiry = c.expiry()
+		c.index(item)
+	} else if c.Idle > 0 {
+		// Re-apply idle timeout to deadline.
+		item.Expiry = c.deadline(item)
+		c.index(item)
+	}
+}
+
+// expire evicts item inline if it has expired but is yet to be swept,
+// returning its key-value and true if so (NOTE: requires lock).
+func (c *Cache[K, V]) expire(item *Entry[K, V]) (kv[K, V], bool) {
+	if c.TTL <= 0 || item.Expiry == 0 || runtime_nanotime() <= item.Expiry {
+		return kv[K, V]{}, false
+	}
+
+	evc := kv[K, V]{K: item.Key, V: item.Value}
+
+	// Drop and free entry.
+	c.Cache.Delete(item.Key)
+	c.free(item)
+
+	return evc, true
+}
+
+// lruElem mirrors the layout of go-maps' LRUMap list element, see peek().
+type lruElem[K comparable, V any] struct {
+	next *lruElem[K, V]
+	prev *lruElem[K, V]
+	K    K
+	V    V
+}
+
+// peek returns the entry for key without pushing it to the front of the LRU list, as c.Cache.Get would, i.e.
+// without saving it from eviction. LRUMap has no such method, so this reads its hashmap (the first field of
+// the embedded ordered map) directly, which must be checked on upgrading go-maps (NOTE: requires lock).
+func (c *Cache[K, V]) peek(key K) (*Entry[K, V], bool) {
+	hmap := *(*map[K]*lruElem[K, *Entry[K, V]])(unsafe.Pointer(&c.Cache))
+	if elem, ok := hmap[key]; ok {
+		return elem.V, true
+	}
+	return nil, false
+}
+
 //go:linkname runtime_nanotime runtime.nanotime
 func runtime_nanotime() uint64
 
-// expiry returns an the next expiry time to use for an entry,
-// which is equivalent to time.Now().Add(ttl), or zero if disabled.
+// expiry returns an the next expiry time to use for an entry, which is
+// equivalent to time.Now().Add(ttl + grace) +/- jitter, or zero if disabled.
 func (c *Cache[K, V]) expiry() uint64 {
-	if ttl := c.TTL; ttl > 0 {
-		return runtime_nanotime() +
-			uint64(c.TTL)
+	ttl := c.TTL
+	if ttl <= 0 {
+		return 0
+	}
+
+	if c.Jitter > 0 {
+		// Randomize within +/- jitter fraction of TTL.
+		delta := c.Jitter * (2*rand.Float64() - 1)
+		ttl += time.Duration(delta * float64(ttl))
+	}
+
+	return runtime_nanotime() +
+		uint64(ttl+c.Grace)
+}
+
+// recordLookup records a cache hit or miss to rec.
+func recordLookup(rec stats.Recorder, hit bool) {
+	if hit {
+		rec.Count("ttl.hits", 1)
+	} else {
+		rec.Count("ttl.misses", 1)
 	}
-	return 0
 }
 
 type kv[K comparable, V any] struct {
 	K K
 	V V
 }
+
+type update[K comparable, V any] struct {
+	K   K
+	Old V
+	New V
+}
+
+// Remaining returns the time remaining until the entry expires, negative if already expired.
+func (e *Entry[K, V]) Remaining() time.Duration {
+	return time.Duration(int64(e.Expiry - runtime_nanotime()))
+}
+
+// Idle returns the time since the entry was last accessed, or created if never accessed.
+func (e *Entry[K, V]) Idle() time.Duration {
+	t := e.Accessed
+	if t == 0 {
+		t = e.Created
+	}
+	return time.Duration(int64(runtime_nanotime() - t))
+}
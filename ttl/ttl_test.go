@@ -1,12 +1,22 @@
 package ttl_test
 
 import (
+	"bytes"
+	"context"
 	"net/url"
+	"path/filepath"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 	"unsafe"
 
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/cachetest"
+	"github.com/mkc188/go-cache/v3/errs"
+	"github.com/mkc188/go-cache/v3/snapshot"
+	"github.com/mkc188/go-cache/v3/tinylfu"
 	"github.com/mkc188/go-cache/v3/ttl"
 	"github.com/google/go-cmp/cmp"
 )
@@ -159,3 +169,1140 @@ func TestCache(t *testing.T) {
 		t.Fatalf("unexpected cache size: %d", sz)
 	}
 }
+
+func TestConformance(t *testing.T) {
+	cachetest.TestCache(t, func(cap int) cache.Cache[string, string] {
+		return ttl.New[string, string](0, cap, time.Minute)
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	src := ttl.New[string, int](0, 10, time.Minute)
+	src.Set("a", 1)
+	src.Set("b", 2)
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("failed to dump cache: %v", err)
+	}
+
+	dst := ttl.New[string, int](0, 10, time.Hour)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("failed to restore cache: %v", err)
+	}
+
+	for key, value := range map[string]int{"a": 1, "b": 2} {
+		if v, ok := dst.Get(key); !ok || v != value {
+			t.Fatalf("unexpected restored value for %s: %d %v", key, v, ok)
+		}
+	}
+}
+
+func TestSnapshotCodec(t *testing.T) {
+	src := ttl.New[string, int](0, 10, time.Minute)
+	src.SetCodec(snapshot.JSON)
+	src.Set("a", 1)
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("failed to dump cache: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte(`{"Key":"a"`)) {
+		t.Fatalf("unexpected json dump: %s", buf.String())
+	}
+
+	dst := ttl.New[string, int](0, 10, time.Hour)
+	dst.SetCodec(snapshot.JSON)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("failed to restore cache: %v", err)
+	}
+
+	// Restored with remaining TTL, not cache TTL.
+	item, ok := dst.Cache.Get("a")
+	if !ok || item.Value != 1 || item.Remaining() > time.Minute {
+		t.Fatalf("unexpected restored entry: %+v %v", item, ok)
+	}
+}
+
+func TestEntryIdle(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.Set("a", 1)
+	time.Sleep(time.Millisecond * 20)
+
+	// Idle since created.
+	item, _ := c.Cache.Get("a")
+	if idle := item.Idle(); idle < time.Millisecond*20 || idle > time.Second {
+		t.Fatalf("unexpected idle since created: %v", idle)
+	}
+
+	// Idle since accessed.
+	c.Get("a")
+	item, _ = c.Cache.Get("a")
+	if idle := item.Idle(); idle >= time.Millisecond*20 {
+		t.Fatalf("unexpected idle since accessed: %v", idle)
+	}
+}
+
+func TestExpiringSet(t *testing.T) {
+	s := ttl.NewExpiringSet[string](10, time.Millisecond*50)
+
+	if !s.AddIfAbsent("event") {
+		t.Fatal("failed to add new key")
+	}
+	if s.AddIfAbsent("event") || !s.Seen("event") {
+		t.Fatal("duplicate key not detected")
+	}
+
+	time.Sleep(time.Millisecond * 60)
+
+	if s.Seen("event") {
+		t.Fatal("expired key still seen")
+	}
+	if !s.AddIfAbsent("event") {
+		t.Fatal("failed to re-add expired key")
+	}
+}
+
+func TestGetWithRefresh(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Second)
+
+	c.Set("cheap", 1)
+	if _, refresh, ok := c.GetWithRefresh("cheap"); !ok || refresh {
+		t.Fatal("refresh advised for entry without recorded cost")
+	}
+
+	// A load cost far exceeding the TTL
+	// should near-certainly advise refresh.
+	c.SetWithCost("costly", 2, time.Hour*1000)
+	if v, refresh, ok := c.GetWithRefresh("costly"); !ok || v != 2 || !refresh {
+		t.Fatal("refresh not advised for costly entry")
+	}
+}
+
+type countRecorder map[string]int64
+
+func (r countRecorder) Count(name string, delta int64)      { r[name] += delta }
+func (r countRecorder) Gauge(name string, value float64)    {}
+func (r countRecorder) Timing(name string, d time.Duration) {}
+
+func TestStatsRecorder(t *testing.T) {
+	rec := countRecorder{}
+
+	c := ttl.New[string, int](0, 10, time.Millisecond*10)
+	c.SetStatsRecorder(rec)
+
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("b")
+
+	time.Sleep(time.Millisecond * 20)
+	c.Sweep(time.Now())
+
+	if rec["ttl.hits"] != 1 || rec["ttl.misses"] != 1 || rec["ttl.evictions"] != 1 {
+		t.Fatalf("unexpected recorded stats: %v", rec)
+	}
+}
+
+func TestExpiryPolicy(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*200)
+
+	c.Set("sliding", 1)
+	c.SetWithPolicy("absolute", 2, ttl.AbsoluteExpiry)
+
+	// Keep accessing both entries
+	// beyond their initial expiry.
+	for i := 0; i < 6; i++ {
+		time.Sleep(time.Millisecond * 50)
+		c.Get("sliding")
+		c.Get("absolute")
+	}
+
+	if _, ok := c.Get("absolute"); ok {
+		t.Fatal("absolute entry extended by access")
+	}
+
+	c.Sweep(time.Now())
+
+	if !c.Has("sliding") || c.Has("absolute") {
+		t.Fatal("unexpected entries remaining after sweep")
+	}
+}
+
+func TestTouchExtend(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*100)
+
+	var invalidated bool
+	c.SetInvalidateCallback(func(string, int) { invalidated = true })
+
+	c.SetWithPolicy("a", 1, ttl.AbsoluteExpiry)
+	c.Set("b", 2)
+
+	if !c.Extend("a", time.Hour) || !c.Touch("b") {
+		t.Fatal("failed to extend existing entries")
+	}
+	if c.Touch("missing") || c.Extend("missing", time.Hour) {
+		t.Fatal("extended missing entry")
+	}
+
+	time.Sleep(time.Millisecond * 150)
+	c.Sweep(time.Now())
+
+	if !c.Has("a") || c.Has("b") {
+		t.Fatal("unexpected entries remaining after sweep")
+	}
+	if invalidated {
+		t.Fatal("invalidate hook called on extend")
+	}
+}
+
+func TestRange(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	var keys []string
+	c.Range(func(key string, value int) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+
+	if len(keys) != 2 || keys[0] != "c" || keys[1] != "b" {
+		t.Fatalf("unexpected ranged keys: %v", keys)
+	}
+}
+
+func TestAdmissionFilter(t *testing.T) {
+	c := ttl.New[int, int](0, 2, time.Minute)
+	c.SetAdmissionFilter(tinylfu.New[int](2))
+
+	// Frequently accessed keys.
+	c.Set(1, 1)
+	c.Set(2, 2)
+	for i := 0; i < 20; i++ {
+		c.Get(1)
+		c.Get(2)
+	}
+
+	// One-hit wonders should be rejected.
+	for i := 100; i < 200; i++ {
+		c.Set(i, i)
+	}
+
+	if !c.Has(1) || !c.Has(2) {
+		t.Fatal("frequent keys evicted by one-hit wonders")
+	}
+}
+
+func TestWeigher(t *testing.T) {
+	c := ttl.New[string, string](0, 100, time.Minute)
+	c.SetWeigher(func(key, value string) int64 {
+		return int64(len(value))
+	}, 10)
+
+	var evicted []string
+	c.SetEvictionCallback(func(key, _ string) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", "1234")
+	c.Set("b", "1234")
+	c.Set("c", "1234")
+
+	if c.Has("a") || !c.Has("b") || !c.Has("c") {
+		t.Fatal("least recently used entry not evicted beyond max cost")
+	}
+	if w := c.Weight(); w != 8 {
+		t.Fatalf("unexpected total weight: %d", w)
+	}
+
+	c.Invalidate("b")
+	if w := c.Weight(); w != 4 {
+		t.Fatalf("unexpected total weight after invalidate: %d", w)
+	}
+
+	c.Set("d", "12345678901")
+	if c.Len() != 0 || len(evicted) != 3 {
+		t.Fatalf("unexpected entries after overweight set: %d %v", c.Len(), evicted)
+	}
+}
+
+func TestShardedCache(t *testing.T) {
+	var c cache.TTLCache[int, int] = ttl.NewSharded[int, int](6, 0, 1000, time.Minute)
+
+	if n := c.(*ttl.ShardedCache[int, int]).Shards(); n != 8 {
+		t.Fatalf("unexpected shard count: %d", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := i * 100; j < (i+1)*100; j++ {
+				c.Set(j, j)
+				if v, ok := c.Get(j); !ok || v != j {
+					t.Errorf("unexpected value for %d: %d %v", j, v, ok)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if l := c.Len(); l != 800 {
+		t.Fatalf("unexpected cache length: %d", l)
+	}
+
+	if !c.InvalidateAll(1, 2, 3) || c.Has(2) || c.Len() != 797 {
+		t.Fatal("failed to invalidate keys across shards")
+	}
+
+	if m := c.(*ttl.ShardedCache[int, int]).GetMany(1, 4, 5, 900); !reflect.DeepEqual(m, map[int]int{4: 4, 5: 5}) {
+		t.Fatalf("unexpected values across shards: %v", m)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	c := ttl.New[int, int](0, 100, time.Hour)
+	c.SetJitter(0.5)
+
+	expiries := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		c.Set(i, i)
+	}
+
+	c.Lock()
+	c.Cache.Range(0, c.Cache.Len(), func(_ int, _ int, e *ttl.Entry[int, int]) {
+		r := e.Remaining()
+		if r < time.Minute*29 || r > time.Minute*91 {
+			t.Errorf("expiry outside jitter bounds: %v", r)
+		}
+		expiries[r.Round(time.Minute)] = true
+	})
+	c.Unlock()
+
+	if len(expiries) < 2 {
+		t.Fatal("expiries not spread by jitter")
+	}
+}
+
+func TestLazyExpiry(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*20)
+
+	var evicted []string
+	c.SetEvictionCallback(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	time.Sleep(time.Millisecond * 30)
+
+	// No sweep has run, expired
+	// entries are evicted inline.
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expired entry returned by get")
+	}
+	if c.Has("b") {
+		t.Fatal("expired entry reported by has")
+	}
+	if c.Len() != 0 || len(evicted) != 2 {
+		t.Fatalf("expired entries not evicted inline: %d %v", c.Len(), evicted)
+	}
+}
+
+func TestHasNoPromote(t *testing.T) {
+	c := ttl.New[string, int](0, 2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	if !c.Has("a") || c.Has("c") {
+		t.Fatal("unexpected has result")
+	}
+
+	// Has is not a use, so "a"
+	// remains least recently used.
+	c.Set("c", 3)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("has saved entry from eviction")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("unexpected eviction")
+	}
+}
+
+func TestSweepBatch(t *testing.T) {
+	c := ttl.New[int, int](0, 100, time.Millisecond*10)
+	c.SetSweepBatch(7)
+
+	var evicted int
+	c.SetEvictionCallback(func(int, int) {
+		evicted++
+	})
+
+	for i := 0; i < 50; i++ {
+		c.SetWithPolicy(i, i, ttl.AbsoluteExpiry)
+	}
+	time.Sleep(time.Millisecond * 20)
+
+	// Keep some entries alive, mixing
+	// expired and unexpired positions.
+	for i := 0; i < 50; i += 5 {
+		c.Touch(i)
+	}
+
+	c.Sweep(time.Now())
+
+	if c.Len() != 10 || evicted != 40 {
+		t.Fatalf("unexpected entries after batched sweep: %d %d", c.Len(), evicted)
+	}
+}
+
+func TestReentrantCallbacks(t *testing.T) {
+	c := ttl.New[int, int](0, 2, time.Millisecond*10)
+	c.SetWeigher(func(int, int) int64 { return 1 }, 2)
+
+	// Callbacks re-entering the cache would deadlock
+	// if called while the cache lock is still held.
+	var calls int
+	c.SetEvictionCallback(func(key, _ int) {
+		c.Len()
+		calls++
+	})
+	c.SetInvalidateCallback(func(key, _ int) {
+		c.Has(key)
+		calls++
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		// 1 invalidate.
+		c.Set(1, 1)
+		c.Set(1, 1)
+
+		// 1 evict, 1 invalidate.
+		c.Set(2, 2)
+		c.Set(3, 3)
+		c.Swap(3, 3)
+
+		// 2 invalidate.
+		c.Clear()
+
+		// 1 evict.
+		c.Set(4, 4)
+		time.Sleep(time.Millisecond * 20)
+		c.Sweep(time.Now())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("callback re-entering cache deadlocked")
+	}
+
+	if calls != 6 {
+		t.Fatalf("unexpected callback count: %d", calls)
+	}
+}
+
+func TestTimerWheel(t *testing.T) {
+	cachetest.TestCache(t, func(cap int) cache.Cache[string, string] {
+		c := ttl.New[string, string](0, cap, time.Minute)
+		c.SetTimerWheel(time.Millisecond)
+		return c
+	})
+
+	// TTL beyond the first wheel level, so
+	// entries must cascade before expiring.
+	c := ttl.New[int, int](0, 100, time.Millisecond*100)
+	c.SetTimerWheel(time.Millisecond)
+	c.SetSweepBatch(7)
+
+	var evicted int
+	c.SetEvictionCallback(func(int, int) {
+		evicted++
+	})
+
+	for i := 0; i < 50; i++ {
+		c.SetWithPolicy(i, i, ttl.AbsoluteExpiry)
+	}
+	time.Sleep(time.Millisecond * 50)
+
+	c.Sweep(time.Now())
+	if c.Len() != 50 || evicted != 0 {
+		t.Fatalf("unexpected entries before expiry: %d %d", c.Len(), evicted)
+	}
+
+	// Keep some entries alive.
+	for i := 0; i < 50; i += 5 {
+		c.Touch(i)
+	}
+	time.Sleep(time.Millisecond * 75)
+
+	c.Sweep(time.Now())
+	if c.Len() != 10 || evicted != 40 {
+		t.Fatalf("unexpected entries after wheel sweep: %d %d", c.Len(), evicted)
+	}
+
+	c.Invalidate(0)
+	time.Sleep(time.Millisecond * 100)
+
+	c.Sweep(time.Now())
+	if c.Len() != 0 || evicted != 49 {
+		t.Fatalf("unexpected entries after final wheel sweep: %d %d", c.Len(), evicted)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	c := ttl.New[int, int](0, 3, time.Minute)
+
+	var evicted []int
+	c.SetEvictionCallback(func(key int, _ int) {
+		evicted = append(evicted, key)
+	})
+
+	c.SetWithPriority(1, 1, 10)
+	c.SetWithPriority(2, 2, 5)
+	c.Set(3, 3)
+
+	// Lowest priority is evicted first,
+	// regardless of recent use, then the
+	// least recently used of the lowest.
+	c.Set(4, 4)
+	c.Set(5, 5)
+	c.Get(2)
+	c.SetWithPriority(6, 6, 10)
+
+	if !reflect.DeepEqual(evicted, []int{3, 4, 5}) {
+		t.Fatalf("unexpected evictions by priority: %v", evicted)
+	}
+
+	// Resetting to default priority
+	// makes the entry evictable again.
+	c.Set(1, 1)
+	c.Add(7, 7)
+
+	if !reflect.DeepEqual(evicted, []int{3, 4, 5, 1}) {
+		t.Fatalf("unexpected evictions after reset: %v", evicted)
+	}
+}
+
+func TestPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	src := ttl.New[string, int](0, 10, time.Minute)
+	src.SetPersistPath(path)
+	src.Start(time.Minute)
+	src.Set("a", 1)
+	src.SetWithPolicy("b", 2, ttl.AbsoluteExpiry)
+	src.Extend("b", -time.Minute+time.Millisecond*20)
+	src.Stop()
+
+	// Let "b" expire while stopped.
+	time.Sleep(time.Millisecond * 40)
+
+	dst := ttl.New[string, int](0, 10, time.Minute)
+	dst.SetPersistPath(path)
+	dst.Start(time.Minute)
+	defer dst.Stop()
+
+	if v, ok := dst.Get("a"); !ok || v != 1 {
+		t.Fatalf("unexpected loaded value: %d %v", v, ok)
+	}
+	if dst.Has("b") {
+		t.Fatal("loaded entry expired since saved")
+	}
+}
+
+func TestWarm(t *testing.T) {
+	c := ttl.New[int, int](0, 100, time.Minute)
+	c.Set(0, -1)
+
+	var invalidated, evicted int
+	c.SetInvalidateCallback(func(int, int) { invalidated++ })
+	c.SetEvictionCallback(func(int, int) { evicted++ })
+
+	entries := make(map[int]int)
+	for i := 0; i < 50; i++ {
+		entries[i] = i
+	}
+	c.Warm(entries)
+
+	i := 50
+	c.WarmFunc(func() (int, int, bool) {
+		i++
+		return i - 1, i - 1, i <= 110
+	})
+
+	if c.Len() != 100 || invalidated != 1 || evicted != 10 {
+		t.Fatalf("unexpected cache after warm: %d %d %d", c.Len(), invalidated, evicted)
+	}
+
+	// Map entries are placed in
+	// random order, so any may
+	// have since been evicted.
+	for i := 50; i < 110; i++ {
+		if v, ok := c.Get(i); !ok || v != i {
+			t.Fatalf("unexpected warmed value for %d: %d %v", i, v, ok)
+		}
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*20)
+	c.SetWithPolicy("a", 1, ttl.AbsoluteExpiry)
+	time.Sleep(time.Millisecond * 30)
+
+	var evicted []string
+	c.SetEvictionCallback(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	if m := c.GetMany("a", "b", "c", "d"); !reflect.DeepEqual(m, map[string]int{"b": 2, "c": 3}) {
+		t.Fatalf("unexpected values: %v", m)
+	}
+
+	if !reflect.DeepEqual(evicted, []string{"a"}) {
+		t.Fatalf("unexpected evictions: %v", evicted)
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.Set("a", 0)
+
+	var invalidated []string
+	c.SetInvalidateCallback(func(key string, _ int) {
+		invalidated = append(invalidated, key)
+	})
+
+	c.SetMany(map[string]int{"a": 1, "b": 2, "c": 3})
+	if m := c.GetMany("a", "b", "c"); !reflect.DeepEqual(m, map[string]int{"a": 1, "b": 2, "c": 3}) {
+		t.Fatalf("unexpected values: %v", m)
+	}
+
+	if n := c.InvalidateMany("a", "c", "d"); n != 2 || c.Len() != 1 {
+		t.Fatalf("unexpected invalidate count: %d %d", n, c.Len())
+	}
+
+	if !reflect.DeepEqual(invalidated, []string{"a", "a", "c"}) {
+		t.Fatalf("unexpected invalidations: %v", invalidated)
+	}
+
+	s := ttl.NewSharded[int, int](4, 0, 100, time.Minute)
+	s.SetMany(map[int]int{1: 1, 2: 2, 3: 3, 4: 4})
+	if n := s.InvalidateMany(1, 2, 5); n != 2 || s.Len() != 2 {
+		t.Fatalf("unexpected sharded invalidate count: %d %d", n, s.Len())
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.Set("a", 1)
+
+	var invalidated int
+	c.SetInvalidateCallback(func(string, int) { invalidated++ })
+
+	eq := func(a, b int) bool { return a == b }
+
+	if c.CompareAndDelete("a", 2, eq) || !c.Has("a") {
+		t.Fatal("deleted on mismatched value")
+	}
+	if !c.CompareAndDelete("a", 1, eq) || c.Has("a") || invalidated != 1 {
+		t.Fatal("failed to delete on matched value")
+	}
+	if c.CompareAndDelete("a", 1, eq) {
+		t.Fatal("deleted missing key")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+
+	var invalidated []int
+	c.SetInvalidateCallback(func(_ string, v int) {
+		invalidated = append(invalidated, v)
+	})
+
+	incr := func(old int, ok bool) (int, bool) {
+		return old + 1, true
+	}
+
+	for i := 0; i < 3; i++ {
+		c.Update("a", incr)
+	}
+
+	if v, ok := c.Get("a"); !ok || v != 3 {
+		t.Fatalf("unexpected updated value: %d %v", v, ok)
+	}
+
+	// Delete once beyond threshold.
+	if v, ok := c.Update("a", func(old int, ok bool) (int, bool) {
+		return old, old < 3
+	}); ok || v != 0 || c.Has("a") {
+		t.Fatalf("failed to delete by update: %d %v", v, ok)
+	}
+
+	if !reflect.DeepEqual(invalidated, []int{1, 2, 3}) {
+		t.Fatalf("unexpected invalidations: %v", invalidated)
+	}
+}
+
+func TestSetExpiry(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	if c.SetExpiry("c", time.Now()) {
+		t.Fatal("set expiry of missing key")
+	}
+
+	if !c.SetExpiry("a", time.Now().Add(time.Millisecond*20)) || !c.SetExpiry("b", time.Now().Add(-time.Hour)) {
+		t.Fatal("failed to set expiry")
+	}
+
+	if c.Has("b") {
+		t.Fatal("entry with past expiry still present")
+	}
+
+	// Access must not extend explicit expiry.
+	time.Sleep(time.Millisecond * 10)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("entry expired early")
+	}
+	time.Sleep(time.Millisecond * 20)
+
+	c.Sweep(time.Now())
+	if c.Len() != 0 {
+		t.Fatalf("unexpected entries after expiry: %d", c.Len())
+	}
+}
+
+func TestEntryInfo(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+
+	before := time.Now()
+	c.Set("a", 1)
+
+	info, ok := c.EntryInfo("a")
+	if !ok || info.Hits != 0 || !info.Accessed.IsZero() {
+		t.Fatalf("unexpected info before access: %+v %v", info, ok)
+	}
+
+	// Allow for clock rounding.
+	if info.Created.Before(before.Add(-time.Millisecond)) || info.Created.After(time.Now().Add(time.Millisecond)) {
+		t.Fatalf("unexpected created time: %v", info.Created)
+	}
+
+	c.Get("a")
+	c.GetMany("a", "b")
+	c.Set("a", 2)
+
+	info, ok = c.EntryInfo("a")
+	if !ok || info.Hits != 2 || info.Accessed.Before(info.Created) {
+		t.Fatalf("unexpected info after access: %+v %v", info, ok)
+	}
+
+	if d := time.Until(info.Expires); d <= 0 || d > time.Minute {
+		t.Fatalf("unexpected expiry: %v", info.Expires)
+	}
+
+	if _, ok := c.EntryInfo("b"); ok {
+		t.Fatal("info of missing key")
+	}
+}
+
+func TestEntryInfoNoPromote(t *testing.T) {
+	c := ttl.New[string, int](0, 2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// Inspecting is not a use, so "a"
+	// remains least recently used.
+	if _, ok := c.EntryInfo("a"); !ok {
+		t.Fatal("missing info")
+	}
+	c.Set("c", 3)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("entry info saved entry from eviction")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("unexpected eviction")
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*20)
+
+	var (
+		calls   atomic.Int32
+		release = make(chan struct{})
+	)
+
+	c.SetStaleWhileRevalidate(time.Millisecond*200, func(key string) (int, error) {
+		calls.Add(1)
+		<-release
+		return 2, nil
+	})
+
+	c.SetWithPolicy("a", 1, ttl.AbsoluteExpiry)
+	time.Sleep(time.Millisecond * 30)
+
+	// Stale value served while a
+	// single refresh is in progress.
+	for i := 0; i < 5; i++ {
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Fatalf("unexpected stale value: %d %v", v, ok)
+		}
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if v, _ := c.Get("a"); v == 2 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("stale value not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("unexpected revalidate calls: %d", calls.Load())
+	}
+}
+
+func TestStaleRevalidateWritten(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*20)
+
+	var (
+		done    = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	c.SetStaleWhileRevalidate(time.Millisecond*200, func(key string) (int, error) {
+		defer close(done)
+		<-release
+		return 2, nil
+	})
+
+	c.SetWithPolicy("a", 1, ttl.AbsoluteExpiry)
+	c.SetWithPolicy("b", 1, ttl.AbsoluteExpiry)
+	time.Sleep(time.Millisecond * 30)
+
+	// Written during revalidation.
+	c.Get("a")
+	c.Set("a", 3)
+	close(release)
+	<-done
+
+	// Refreshed value must not overwrite newer.
+	time.Sleep(time.Millisecond * 5)
+	if v, _ := c.Get("a"); v != 3 {
+		t.Fatalf("revalidation overwrote newer value: %d", v)
+	}
+
+	// Invalidated during revalidation.
+	done, release = make(chan struct{}), make(chan struct{})
+	c.Get("b")
+	c.Invalidate("b")
+	close(release)
+	<-done
+
+	time.Sleep(time.Millisecond * 5)
+	if c.Has("b") {
+		t.Fatal("revalidation restored invalidated key")
+	}
+}
+
+func TestClone(t *testing.T) {
+	c := ttl.New[string, []int](0, 3, time.Minute)
+
+	c.SetWithPriority("a", []int{1}, 1)
+	c.Set("b", []int{2})
+	c.SetExpiry("b", time.Now().Add(time.Hour))
+
+	clone := c.Clone(func(v []int) []int {
+		return append([]int(nil), v...)
+	})
+
+	// Changes to original not seen by clone.
+	v, _ := c.Get("a")
+	v[0] = 10
+	c.Invalidate("b")
+
+	if v, ok := clone.Get("a"); !ok || v[0] != 1 {
+		t.Fatalf("unexpected cloned value: %v %v", v, ok)
+	}
+
+	if info, ok := clone.EntryInfo("b"); !ok || time.Until(info.Expires) < time.Minute {
+		t.Fatalf("unexpected cloned expiry: %+v %v", info, ok)
+	}
+
+	// Priority is cloned.
+	clone.Set("c", []int{3})
+	clone.Set("d", []int{4})
+	if !clone.Has("a") || clone.Has("b") {
+		t.Fatal("unexpected cloned priority eviction")
+	}
+}
+
+func TestCloneEmpty(t *testing.T) {
+	c := ttl.New[string, int](0, 3, time.Minute)
+
+	clone := c.Clone(nil)
+	if clone.Len() != 0 || clone.Cap() != 3 {
+		t.Fatalf("unexpected empty clone: len=%d cap=%d", clone.Len(), clone.Cap())
+	}
+
+	clone.Set("a", 1)
+	if c.Has("a") {
+		t.Fatal("clone not independent")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := ttl.New[string, int](0, 10, time.Minute)
+	b := ttl.New[string, int](0, 10, time.Minute)
+
+	a.Set("x", 1)
+	a.Set("y", 2)
+	b.Set("y", 3)
+	b.Set("z", 4)
+	b.SetExpiry("y", time.Now().Add(time.Hour))
+
+	a.Merge(b, func(a, b int) int { return a + b })
+
+	for k, v := range map[string]int{"x": 1, "y": 5, "z": 4} {
+		if got, ok := a.Get(k); !ok || got != v {
+			t.Fatalf("unexpected merged value for %s: %d %v", k, got, ok)
+		}
+	}
+
+	// Later expiry kept.
+	if info, ok := a.EntryInfo("y"); !ok || time.Until(info.Expires) < time.Minute*30 {
+		t.Fatalf("unexpected merged expiry: %+v %v", info, ok)
+	}
+
+	// Other left untouched.
+	if b.Len() != 2 {
+		t.Fatalf("unexpected other size: %d", b.Len())
+	}
+}
+
+func TestMergeEmpty(t *testing.T) {
+	a := ttl.New[string, int](0, 10, time.Minute)
+	b := ttl.New[string, int](0, 10, time.Minute)
+
+	a.Set("x", 1)
+	a.Merge(b, nil)
+
+	if v, ok := a.Get("x"); !ok || v != 1 || a.Len() != 1 {
+		t.Fatalf("unexpected merged cache: %d %v len=%d", v, ok, a.Len())
+	}
+
+	// Other not left locked.
+	b.Set("y", 2)
+	if !b.Has("y") {
+		t.Fatal("other unusable after merge")
+	}
+}
+
+func TestUpdateCallback(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+
+	type diff struct{ old, new int }
+	var diffs []diff
+	c.SetUpdateCallback(func(_ string, old, new int) {
+		diffs = append(diffs, diff{old, new})
+	})
+
+	c.Set("a", 1) // not an overwrite
+	c.Set("a", 2)
+	c.CAS("a", 2, 3, func(a, b int) bool { return a == b })
+	c.CAS("a", 0, 4, func(a, b int) bool { return a == b }) // no swap
+	c.Swap("a", 5)
+	c.Swap("b", 6) // not present
+
+	expect := []diff{{1, 2}, {2, 3}, {3, 5}}
+	if !reflect.DeepEqual(diffs, expect) {
+		t.Fatalf("unexpected update callbacks: %v", diffs)
+	}
+}
+
+func TestErrorVariants(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.SetWeigher(func(_ string, v int) int64 { return int64(v) }, 10)
+
+	if _, err := c.GetE("a"); err != errs.ErrNotFound {
+		t.Fatalf("unexpected get error: %v", err)
+	}
+
+	// Heavier than max cost.
+	if err := c.SetE("b", 11); err != errs.ErrCapacity {
+		t.Fatalf("unexpected set error: %v", err)
+	}
+
+	if err := c.SetE("a", 1); err != nil {
+		t.Fatalf("unexpected set error: %v", err)
+	}
+
+	if v, err := c.GetE("a"); err != nil || v != 1 {
+		t.Fatalf("unexpected get result: %d %v", v, err)
+	}
+
+	if err := c.InvalidateE("a"); err != nil {
+		t.Fatalf("unexpected invalidate error: %v", err)
+	}
+
+	if err := c.InvalidateE("a"); err != errs.ErrNotFound {
+		t.Fatalf("unexpected invalidate error: %v", err)
+	}
+}
+
+func TestStopAndFlush(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+
+	var evicted []string
+	c.SetEvictionCallback(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Start(time.Second)
+
+	if !c.StopAndFlush() {
+		t.Fatal("failed to stop cache")
+	}
+
+	if c.Len() != 0 {
+		t.Fatalf("unexpected cache size: %d", c.Len())
+	}
+
+	if !reflect.DeepEqual(evicted, []string{"a", "b"}) {
+		t.Fatalf("unexpected flushed keys: %v", evicted)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.SetIdleTimeout(time.Millisecond * 50)
+
+	c.SetWithPolicy("a", 1, ttl.AbsoluteExpiry)
+	c.Set("b", 2)
+
+	// Keep a in use.
+	time.Sleep(time.Millisecond * 30)
+	if !c.Has("a") {
+		t.Fatal("entry expired early")
+	}
+	c.Get("a")
+	time.Sleep(time.Millisecond * 30)
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("accessed entry expired before idle timeout")
+	}
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("unused entry not expired after idle timeout")
+	}
+
+	// Expiry brought forward to idle timeout.
+	info, _ := c.EntryInfo("a")
+	if time.Until(info.Expires) > time.Millisecond*50 {
+		t.Fatalf("unexpected idle expiry: %v", time.Until(info.Expires))
+	}
+
+	time.Sleep(time.Millisecond * 80)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("entry not expired after idle timeout")
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Millisecond*10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c.Start(time.Second)
+	if err := c.Run(ctx, time.Millisecond*5); err != ttl.ErrNotStarted {
+		t.Fatalf("unexpected error running twice: %v", err)
+	}
+	c.Stop()
+
+	c.Set("a", 1)
+
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx, time.Millisecond*5) }()
+
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expired entry not swept while running")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+
+	// Stopped on cancel.
+	if !c.Start(time.Second) {
+		t.Fatal("routine still running after cancel")
+	}
+	c.Stop()
+}
+
+func TestLenLockFree(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Invalidate("a")
+
+	// Len, Cap must not take the lock.
+	c.Lock()
+	l, cap := c.Len(), c.Cap()
+	c.Unlock()
+
+	if l != 1 || cap != 10 {
+		t.Fatalf("unexpected len / cap: %d %d", l, cap)
+	}
+}
+
+func TestDebug(t *testing.T) {
+	c := ttl.New[string, int](0, 10, time.Minute)
+
+	if s := c.String(); s != "ttl.Cache{len=0 cap=10 ttl=1m0s oldest=- newest=- pool=0}" {
+		t.Fatalf("unexpected empty debug string: %s", s)
+	}
+
+	c.Set("a", 1)
+	c.SetExpiry("a", time.Now().Add(time.Second))
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Invalidate("c")
+
+	info := c.Debug()
+	if info.Len != 2 || info.Cap != 10 || info.TTL != time.Minute || info.Pool != 1 {
+		t.Fatalf("unexpected debug info: %+v", info)
+	}
+
+	if d := time.Until(info.Oldest); d > time.Second || d < 0 {
+		t.Fatalf("unexpected oldest expiry: %v", d)
+	}
+
+	if d := time.Until(info.Newest); d > time.Minute || d < time.Second*59 {
+		t.Fatalf("unexpected newest expiry: %v", d)
+	}
+}
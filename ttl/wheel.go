@@ -0,0 +1,250 @@
+package ttl
+
+import (
+	"time"
+)
+
+const (
+	// wheelBits is the number of tick index bits per timer wheel level.
+	wheelBits = 6
+
+	// wheelSize is the number of buckets per timer wheel level.
+	wheelSize = 1 << wheelBits
+
+	// wheelMask masks a tick index to a bucket within a timer wheel level.
+	wheelMask = wheelSize - 1
+
+	// wheelLevels is the number of timer wheel levels, each spanning wheelSize times the ticks of the previous.
+	wheelLevels = 4
+)
+
+// bucket is an intrusive doubly linked list of entries in the timer wheel.
+type bucket[K comparable, V any] struct {
+	head *Entry[K, V]
+}
+
+// push links entry at the head of bucket.
+func (b *bucket[K, V]) push(e *Entry[K, V]) {
+	e.bucket = b
+	e.next = b.head
+	if b.head != nil {
+		b.head.prev = e
+	}
+	b.head = e
+}
+
+// unlink unlinks entry from its bucket.
+func (b *bucket[K, V]) unlink(e *Entry[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		b.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	}
+	e.bucket = nil
+	e.prev = nil
+	e.next = nil
+}
+
+// wheel is a hierarchical timer wheel indexing entries by expiry tick, such that entries due for expiry can be
+// found in time proportional to their number rather than by scanning the whole cache. Entries beyond the range
+// of the top level are placed in its furthest bucket, and re-placed as it cascades (NOTE: requires cache lock).
+type wheel[K comparable, V any] struct {
+	// tick is the duration in nanoseconds of the lowest level buckets.
+	tick uint64
+
+	// next is the next tick to be processed.
+	next uint64
+
+	// n is the number of entries in levels.
+	n int
+
+	// levels are the wheel buckets, level 0 has buckets of 1
+	// tick, level 1 has buckets of wheelSize ticks, and so on.
+	levels [wheelLevels][wheelSize]bucket[K, V]
+
+	// due are entries whose expiry tick has been processed.
+	due bucket[K, V]
+}
+
+// newWheel returns a new timer wheel with given tick duration, starting at now.
+func newWheel[K comparable, V any](tick time.Duration, now uint64) *wheel[K, V] {
+	w := &wheel[K, V]{tick: uint64(tick)}
+	w.next = now / w.tick
+	return w
+}
+
+// insert places entry in the wheel by its expiry, entries without expiry are not indexed.
+func (w *wheel[K, V]) insert(e *Entry[K, V]) {
+	if e.Expiry == 0 {
+		return
+	}
+
+	// First tick at which the entry
+	// is certain to have expired.
+	t := e.Expiry/w.tick + 1
+	if t < w.next {
+		t = w.next
+	}
+
+	// Find level spanning delta.
+	delta := t - w.next
+	level := 0
+	for level < wheelLevels-1 && delta >= 1<<(wheelBits*(level+1)) {
+		level++
+	}
+
+	if delta >= 1<<(wheelBits*wheelLevels) {
+		// Beyond range, place in furthest bucket.
+		t = w.next + 1<<(wheelBits*wheelLevels) - 1
+	}
+
+	idx := (t >> (wheelBits * level)) & wheelMask
+	w.levels[level][idx].push(e)
+	w.n++
+}
+
+// remove unlinks entry from the wheel, if indexed.
+func (w *wheel[K, V]) remove(e *Entry[K, V]) {
+	if e.bucket == nil {
+		return
+	}
+	if e.bucket != &w.due {
+		w.n--
+	}
+	e.bucket.unlink(e)
+}
+
+// update re-places entry in the wheel after a change in its expiry.
+func (w *wheel[K, V]) update(e *Entry[K, V]) {
+	w.remove(e)
+	w.insert(e)
+}
+
+// advance processes all ticks up to now, moving entries whose expiry tick has passed to the due list.
+func (w *wheel[K, V]) advance(now uint64) {
+	until := now / w.tick
+
+	for w.next <= until {
+		if w.n == 0 {
+			// Nothing indexed,
+			// skip to the end.
+			w.next = until + 1
+			return
+		}
+
+		t := w.next
+
+		// Find highest level whose bucket boundary this tick crosses.
+		level := 0
+		for level < wheelLevels-1 && t&(1<<(wheelBits*(level+1))-1) == 0 {
+			level++
+		}
+
+		// Cascade entries down from highest level first.
+		for ; level > 0; level-- {
+			w.cascade(&w.levels[level][(t>>(wheelBits*level))&wheelMask])
+		}
+
+		// Move this tick's entries to due.
+		b := &w.levels[0][t&wheelMask]
+		for b.head != nil {
+			e := b.head
+			b.unlink(e)
+			w.n--
+			w.due.push(e)
+		}
+
+		w.next++
+	}
+}
+
+// cascade re-places all entries of bucket into lower levels.
+func (w *wheel[K, V]) cascade(b *bucket[K, V]) {
+	for b.head != nil {
+		e := b.head
+		b.unlink(e)
+		w.n--
+		w.insert(e)
+	}
+}
+
+// SetTimerWheel enables indexing entry expiries in a hierarchical timer wheel with given tick resolution, so
+// sweeps evict expired entries in time proportional to their number rather than scanning the cache. This suits
+// caches with very many entries, at the cost of re-indexing entries on every expiry change. Entries are swept up to
+// a tick after expiring (though never returned by Get once expired). A tick <= 0 disables.
+func (c *Cache[K, V]) SetTimerWheel(tick time.Duration) {
+	c.locked(func() {
+		if c.wheel != nil {
+			// Unlink all from existing wheel.
+			c.Cache.Range(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+				c.wheel.remove(item)
+			})
+			c.wheel = nil
+		}
+
+		if tick <= 0 {
+			return
+		}
+
+		// Index all entries in new wheel.
+		c.wheel = newWheel[K, V](tick, runtime_nanotime())
+		c.Cache.Range(0, c.Cache.Len(), func(_ int, _ K, item *Entry[K, V]) {
+			c.wheel.insert(item)
+		})
+	})
+}
+
+// index re-places item in the timer wheel (if any) after a change in its expiry, first applying
+// the idle timeout (if any) to its new expiry, see SetIdleTimeout() (NOTE: requires lock).
+func (c *Cache[K, V]) index(item *Entry[K, V]) {
+	c.idle(item)
+	if c.wheel != nil {
+		c.wheel.update(item)
+	}
+}
+
+// sweepWheel evicts up to SweepBatch items expired as of now using the timer wheel, returning evicted items if
+// hook is set, the evicted count, and whether the batch limit was reached (NOTE: requires lock).
+func (c *Cache[K, V]) sweepWheel(now uint64, hook func(K, V)) ([]kv[K, V], int, bool) {
+	var (
+		kvs []kv[K, V]
+		n   int
+	)
+
+	// Process elapsed ticks.
+	c.wheel.advance(now)
+
+	for c.wheel.due.head != nil {
+		if c.SweepBatch > 0 && n >= c.SweepBatch {
+			// Batch full.
+			return kvs, n, true
+		}
+
+		item := c.wheel.due.head
+
+		if now <= item.Expiry {
+			// Not yet expired, re-place
+			// (i.e. beyond wheel range).
+			c.wheel.update(item)
+			continue
+		}
+
+		if hook != nil {
+			// Store key-value pair for later access.
+			kvs = append(kvs, kv[K, V]{
+				K: item.Key,
+				V: item.Value,
+			})
+		}
+
+		// Drop and free entry.
+		c.Cache.Delete(item.Key)
+		c.free(item)
+		n++
+	}
+
+	return kvs, n, false
+}
@@ -0,0 +1,78 @@
+package ttl
+
+import (
+	"math"
+	"math/rand"
+)
+
+// GetWithRefresh performs Get(), additionally advising whether the caller should recompute the value early. Using
+// probabilistic early expiration (XFetch), refresh is advised with increasing likelihood as the entry approaches its
+// expiry, scaled by its recorded load cost (see SetWithCost()), such that costly keys are typically recomputed by a
+// single caller before expiry, rather than by all callers at once after. Entries without a recorded cost are never
+// advised for refresh. Note the expiry check is against the entry's expiry prior to this Get() extending it.
+func (c *Cache[K, V]) GetWithRefresh(key K) (v V, refresh bool, ok bool) {
+	var (
+		// was entry expired?
+		ev bool
+
+		// expired key value.
+		evc kv[K, V]
+
+		// hook func ptrs.
+		evict      func(K, V)
+		revalidate func(K) (V, error)
+
+		// get current nanoseconds.
+		now = runtime_nanotime()
+	)
+
+	c.locked(func() {
+		var item *Entry[K, V]
+
+		// Check for item in cache
+		item, ok = c.Cache.Get(key)
+		if !ok {
+			return
+		}
+
+		if evc, ev = c.expire(item); ev {
+			// Set hook func ptr.
+			evict = c.evictHook()
+			ok = false
+			return
+		}
+
+		if item.Cost > 0 && c.TTL > 0 {
+			beta := c.Beta
+			if beta <= 0 {
+				// Default beta
+				beta = 1
+			}
+
+			// XFetch: now - cost * beta * ln(rand()) >= expiry, where ln(rand()) <= 0.
+			early := float64(item.Cost) * beta * -math.Log(1-rand.Float64())
+			refresh = float64(now+uint64(c.Grace))+early >= float64(item.Expiry)
+		}
+
+		// Check if stale.
+		revalidate = c.stale(item)
+
+		// Update fetched's expiry
+		c.access(item)
+
+		// Set value.
+		v = item.Value
+	})
+
+	if revalidate != nil {
+		// Refresh in background.
+		c.revalidate(key, revalidate)
+	}
+
+	if ev && evict != nil {
+		// Pass to eviction hook.
+		evict(evc.K, evc.V)
+	}
+
+	return
+}
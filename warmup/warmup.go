@@ -0,0 +1,127 @@
+// Package warmup provides helpers streaming key-value pairs from external sources into any cache.Cache, for prefilling caches at deploy time.
+package warmup
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Pair is a key-value pair, as read from ndjson (one `{"key":...,"value":...}` object per line) or gob streams.
+type Pair[Key comparable, Value any] struct {
+	Key   Key   `json:"key"`
+	Value Value `json:"value"`
+}
+
+// Options provides configuration for warming up a cache.
+type Options struct {
+	// Rate is the maximum number of pairs stored per second, a Rate <= 0 is unlimited.
+	Rate float64
+
+	// Progress is called with the total number of pairs stored, every ProgressEvery pairs and once on completion.
+	Progress func(n int)
+
+	// ProgressEvery is the number of pairs between Progress calls, defaulting to 1000.
+	ProgressEvery int
+}
+
+// FromIter stores all pairs returned by next in c, until next returns false or an error, returning the number of pairs stored.
+func FromIter[K comparable, V any](ctx context.Context, c cache.Cache[K, V], next func() (K, V, bool, error), opts Options) (n int, err error) {
+	every := opts.ProgressEvery
+	if every <= 0 {
+		// Default interval
+		every = 1000
+	}
+
+	var (
+		// interval between stores.
+		interval time.Duration
+
+		// time of next store slot.
+		slot = time.Now()
+	)
+
+	if opts.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / opts.Rate)
+	}
+
+	defer func() {
+		if opts.Progress != nil {
+			// Report final total.
+			opts.Progress(n)
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		key, value, ok, err := next()
+		if err != nil {
+			return n, err
+		} else if !ok {
+			return n, nil
+		}
+
+		if interval > 0 {
+			// Wait for next rate limit slot.
+			if wait := time.Until(slot); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return n, ctx.Err()
+				case <-timer.C:
+				}
+			}
+			slot = slot.Add(interval)
+			if now := time.Now(); slot.Before(now) {
+				// Don't accumulate burst.
+				slot = now
+			}
+		}
+
+		c.Set(key, value)
+		n++
+
+		if opts.Progress != nil && n%every == 0 {
+			opts.Progress(n)
+		}
+	}
+}
+
+// FromJSON stores all pairs read from ndjson stream r in c, returning the number of pairs stored.
+func FromJSON[K comparable, V any](ctx context.Context, c cache.Cache[K, V], r io.Reader, opts Options) (int, error) {
+	dec := json.NewDecoder(r)
+	return FromIter(ctx, c, func() (K, V, bool, error) {
+		var p Pair[K, V]
+		if err := dec.Decode(&p); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return p.Key, p.Value, false, err
+		}
+		return p.Key, p.Value, true, nil
+	}, opts)
+}
+
+// FromGob stores all gob encoded pairs read from stream r in c, returning the number of pairs stored.
+func FromGob[K comparable, V any](ctx context.Context, c cache.Cache[K, V], r io.Reader, opts Options) (int, error) {
+	dec := gob.NewDecoder(r)
+	return FromIter(ctx, c, func() (K, V, bool, error) {
+		var p Pair[K, V]
+		if err := dec.Decode(&p); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return p.Key, p.Value, false, err
+		}
+		return p.Key, p.Value, true, nil
+	}, opts)
+}
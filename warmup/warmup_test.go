@@ -0,0 +1,62 @@
+package warmup_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/warmup"
+)
+
+func TestFromJSON(t *testing.T) {
+	c := cache.New[string, int](0, 10)
+
+	var reported []int
+	input := `{"key":"a","value":1}
+{"key":"b","value":2}
+{"key":"c","value":3}
+`
+
+	start := time.Now()
+	n, err := warmup.FromJSON[string, int](context.Background(), c, strings.NewReader(input), warmup.Options{
+		Rate:          100,
+		Progress:      func(n int) { reported = append(reported, n) },
+		ProgressEvery: 2,
+	})
+	if err != nil || n != 3 {
+		t.Fatalf("unexpected result: %d %v", n, err)
+	}
+
+	if time.Since(start) < time.Millisecond*15 {
+		t.Fatal("rate limit not applied")
+	}
+
+	if len(reported) != 2 || reported[0] != 2 || reported[1] != 3 {
+		t.Fatalf("unexpected progress: %v", reported)
+	}
+
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Fatal("pair not stored")
+	}
+}
+
+func TestFromGob(t *testing.T) {
+	var buf bytes.Buffer
+
+	enc := gob.NewEncoder(&buf)
+	for i := 0; i < 5; i++ {
+		if err := enc.Encode(warmup.Pair[int, string]{Key: i, Value: "v"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := cache.New[int, string](0, 10)
+	n, err := warmup.FromGob[int, string](context.Background(), c, &buf, warmup.Options{})
+	if err != nil || n != 5 || c.Len() != 5 {
+		t.Fatalf("unexpected result: %d %v (len=%d)", n, err, c.Len())
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !go1.24
+
+package weakcache
+
+// ref is a reference to a value, held strongly as weak pointers are unavailable before Go 1.24.
+type ref[V any] struct{ p *V }
+
+// makeRef returns a reference to value.
+func makeRef[V any](value *V) ref[V] {
+	return ref[V]{p: value}
+}
+
+// value returns the referenced value.
+func (r ref[V]) value() *V {
+	return r.p
+}
+
+// onCollect is a no-op, as strongly held values are never collected.
+func onCollect[V any](value *V, fn func()) {}
@@ -0,0 +1,26 @@
+//go:build go1.24
+
+package weakcache
+
+import (
+	"runtime"
+	"weak"
+)
+
+// ref is a weak reference to a value.
+type ref[V any] struct{ p weak.Pointer[V] }
+
+// makeRef returns a weak reference to value.
+func makeRef[V any](value *V) ref[V] {
+	return ref[V]{p: weak.Make(value)}
+}
+
+// value returns the referenced value, or nil if collected.
+func (r ref[V]) value() *V {
+	return r.p.Value()
+}
+
+// onCollect registers fn to be called after value is collected.
+func onCollect[V any](value *V, fn func()) {
+	runtime.AddCleanup(value, func(struct{}) { fn() }, struct{}{})
+}
@@ -0,0 +1,90 @@
+// Package weakcache provides a cache holding values by weak reference, such that entries are dropped automatically
+// once no other references to their value remain, useful for deduplicating large immutable objects.
+package weakcache
+
+import (
+	"sync"
+)
+
+// Cache maps keys to weakly referenced values. When built with Go 1.24 or later values are held via weak pointers,
+// with entries removed by runtime cleanups once their value is collected. Older toolchains lack weak references,
+// so there values are held strongly and entries must be removed with Invalidate.
+type Cache[Key comparable, Value any] struct {
+	entries map[Key]ref[Value]
+	mu      sync.Mutex
+}
+
+// New returns a new initialized Cache.
+func New[K comparable, V any]() *Cache[K, V] {
+	return &Cache[K, V]{entries: make(map[K]ref[V])}
+}
+
+// Get fetches the value with key, if it is still referenced elsewhere.
+func (c *Cache[K, V]) Get(key K) (*V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key)
+}
+
+// Set places the value at key, replacing any existing.
+func (c *Cache[K, V]) Set(key K, value *V) {
+	c.mu.Lock()
+	c.set(key, value)
+	c.mu.Unlock()
+}
+
+// GetOrSet fetches the value with key if still referenced, else places and returns value. Use this to
+// deduplicate objects, replacing a newly constructed value with a canonical copy where one exists.
+func (c *Cache[K, V]) GetOrSet(key K, value *V) *V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.get(key); ok {
+		return v
+	}
+	c.set(key, value)
+	return value
+}
+
+// Invalidate removes the entry with key, returning whether it existed.
+func (c *Cache[K, V]) Invalidate(key K) bool {
+	c.mu.Lock()
+	_, ok := c.entries[key]
+	delete(c.entries, key)
+	c.mu.Unlock()
+	return ok
+}
+
+// Len returns the current number of entries, including any whose value was collected but not yet cleaned up.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	n := len(c.entries)
+	c.mu.Unlock()
+	return n
+}
+
+// get fetches the live value with key, dropping the entry if collected (NOTE: requires lock).
+func (c *Cache[K, V]) get(key K) (*V, bool) {
+	r, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	v := r.value()
+	if v == nil {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return v, true
+}
+
+// set places the value at key, registering cleanup of the entry once the value is collected (NOTE: requires lock).
+func (c *Cache[K, V]) set(key K, value *V) {
+	r := makeRef(value)
+	c.entries[key] = r
+	onCollect(value, func() {
+		c.mu.Lock()
+		if cur, ok := c.entries[key]; ok && cur == r {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	})
+}
@@ -0,0 +1,38 @@
+//go:build go1.24
+
+package weakcache_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mkc188/go-cache/v3/weakcache"
+)
+
+type blob struct{ data [1 << 10]byte }
+
+func TestCache(t *testing.T) {
+	c := weakcache.New[string, blob]()
+
+	kept := &blob{}
+	c.Set("kept", kept)
+	c.Set("dropped", &blob{})
+
+	if v := c.GetOrSet("kept", &blob{}); v != kept {
+		t.Fatal("expected canonical value")
+	}
+
+	for i := 0; i < 10 && c.Len() > 1; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if _, ok := c.Get("dropped"); ok || c.Len() != 1 {
+		t.Fatalf("unreferenced value not dropped (len=%d)", c.Len())
+	}
+	if v, ok := c.Get("kept"); !ok || v != kept {
+		t.Fatal("referenced value dropped")
+	}
+	runtime.KeepAlive(kept)
+}
@@ -0,0 +1,249 @@
+// Package writebehind provides a generic write-behind queue, where mutations applied to a cache are queued and
+// flushed in batches to a user-provided persist function, with retries and a failure callback.
+package writebehind
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+)
+
+// Op is the kind of queued mutation.
+type Op int
+
+const (
+	// Set persists the mutation's value at key.
+	Set Op = iota
+
+	// Delete deletes the mutation's key.
+	Delete
+)
+
+// Mutation is a queued mutation of key.
+type Mutation[Key comparable, Value any] struct {
+	Op    Op
+	Key   Key
+	Value Value
+}
+
+// Options provides configuration for a Queue.
+type Options[Key comparable, Value any] struct {
+	// Interval is the frequency at which queued mutations are flushed, defaulting to 1s.
+	Interval time.Duration
+
+	// BatchSize is the maximum number of mutations per persist call, and the queue length triggering
+	// an early flush. A BatchSize <= 0 persists all queued mutations in a single call.
+	BatchSize int
+
+	// MaxRetries is the number of times a failed batch is retried before being passed to OnFailure.
+	MaxRetries int
+
+	// RetryBackoff is the delay between retries of a failed batch, defaulting to 100ms.
+	RetryBackoff time.Duration
+
+	// OnFailure is called with each batch that failed to persist after all retries, and the last error.
+	OnFailure func(batch []Mutation[Key, Value], err error)
+}
+
+// Queue queues mutations, coalesced per key, flushing them to persist from a background routine.
+type Queue[Key comparable, Value any] struct {
+	persist func(context.Context, []Mutation[Key, Value]) error
+	opts    Options[Key, Value]
+	order   []Key
+	pending map[Key]Mutation[Key, Value]
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	flushMu sync.Mutex
+	mu      sync.Mutex
+}
+
+// New returns a new Queue flushing to persist, starting its flush routine.
+func New[K comparable, V any](persist func(context.Context, []Mutation[K, V]) error, opts Options[K, V]) *Queue[K, V] {
+	if opts.Interval <= 0 {
+		// Default duration
+		opts.Interval = time.Second
+	}
+
+	if opts.RetryBackoff <= 0 {
+		// Default duration
+		opts.RetryBackoff = time.Millisecond * 100
+	}
+
+	q := &Queue[K, V]{
+		persist: persist,
+		opts:    opts,
+		pending: make(map[K]Mutation[K, V]),
+		flushCh: make(chan struct{}, 1),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		defer close(q.doneCh)
+
+		for {
+			select {
+			case <-q.stopCh:
+				return
+			case <-ticker.C:
+			case <-q.flushCh:
+			}
+			_ = q.Flush(context.Background())
+		}
+	}()
+
+	return q
+}
+
+// Enqueue queues given mutation, replacing any queued for its key.
+func (q *Queue[K, V]) Enqueue(m Mutation[K, V]) {
+	q.mu.Lock()
+	if _, ok := q.pending[m.Key]; !ok {
+		q.order = append(q.order, m.Key)
+	}
+	q.pending[m.Key] = m
+	full := q.opts.BatchSize > 0 && len(q.order) >= q.opts.BatchSize
+	q.mu.Unlock()
+
+	if full {
+		select {
+		case q.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Len returns the number of queued mutations.
+func (q *Queue[K, V]) Len() int {
+	q.mu.Lock()
+	n := len(q.order)
+	q.mu.Unlock()
+	return n
+}
+
+// Flush persists all queued mutations in batches, retrying failed batches. It returns the last error
+// of any batch that failed after all retries, such batches being passed to OnFailure and dropped.
+func (q *Queue[K, V]) Flush(ctx context.Context) (err error) {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+
+	q.mu.Lock()
+	order, pending := q.order, q.pending
+	q.order, q.pending = nil, make(map[K]Mutation[K, V], len(pending))
+	q.mu.Unlock()
+
+	size := q.opts.BatchSize
+	if size <= 0 {
+		size = len(order)
+	}
+
+	for len(order) > 0 {
+		n := size
+		if n > len(order) {
+			n = len(order)
+		}
+
+		batch := make([]Mutation[K, V], n)
+		for i, key := range order[:n] {
+			batch[i] = pending[key]
+		}
+		order = order[n:]
+
+		if berr := q.persistBatch(ctx, batch); berr != nil {
+			err = berr
+			if q.opts.OnFailure != nil {
+				q.opts.OnFailure(batch, berr)
+			}
+		}
+	}
+
+	return err
+}
+
+// Close stops the flush routine, after a final flush bounded by ctx.
+func (q *Queue[K, V]) Close(ctx context.Context) error {
+	close(q.stopCh)
+	<-q.doneCh
+	return q.Flush(ctx)
+}
+
+// persistBatch persists batch, retrying on failure.
+func (q *Queue[K, V]) persistBatch(ctx context.Context, batch []Mutation[K, V]) error {
+	for attempt := 0; ; attempt++ {
+		err := q.persist(ctx, batch)
+		if err == nil || attempt >= q.opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(q.opts.RetryBackoff):
+		}
+	}
+}
+
+// Cache wraps a cache.Cache, enqueueing each mutation applied to it on a Queue.
+type Cache[Key comparable, Value any] struct {
+	cache.Cache[Key, Value]
+
+	// Queue is the queue mutations are enqueued on.
+	Queue *Queue[Key, Value]
+}
+
+// Wrap returns given cache wrapped to enqueue mutations on q.
+func Wrap[K comparable, V any](c cache.Cache[K, V], q *Queue[K, V]) *Cache[K, V] {
+	return &Cache[K, V]{Cache: c, Queue: q}
+}
+
+// Add: implements cache.Cache's Add().
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	ok := c.Cache.Add(key, value)
+	if ok {
+		c.Queue.Enqueue(Mutation[K, V]{Op: Set, Key: key, Value: value})
+	}
+	return ok
+}
+
+// Set: implements cache.Cache's Set().
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.Cache.Set(key, value)
+	c.Queue.Enqueue(Mutation[K, V]{Op: Set, Key: key, Value: value})
+}
+
+// CAS: implements cache.Cache's CAS().
+func (c *Cache[K, V]) CAS(key K, old V, new V, cmp func(V, V) bool) bool {
+	ok := c.Cache.CAS(key, old, new, cmp)
+	if ok {
+		c.Queue.Enqueue(Mutation[K, V]{Op: Set, Key: key, Value: new})
+	}
+	return ok
+}
+
+// Swap: implements cache.Cache's Swap().
+func (c *Cache[K, V]) Swap(key K, swp V) V {
+	old := c.Cache.Swap(key, swp)
+	c.Queue.Enqueue(Mutation[K, V]{Op: Set, Key: key, Value: swp})
+	return old
+}
+
+// Invalidate: implements cache.Cache's Invalidate().
+func (c *Cache[K, V]) Invalidate(key K) bool {
+	ok := c.Cache.Invalidate(key)
+	c.Queue.Enqueue(Mutation[K, V]{Op: Delete, Key: key})
+	return ok
+}
+
+// InvalidateAll: implements cache.Cache's InvalidateAll().
+func (c *Cache[K, V]) InvalidateAll(keys ...K) bool {
+	ok := c.Cache.InvalidateAll(keys...)
+	for _, key := range keys {
+		c.Queue.Enqueue(Mutation[K, V]{Op: Delete, Key: key})
+	}
+	return ok
+}
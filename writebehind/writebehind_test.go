@@ -0,0 +1,80 @@
+package writebehind_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/mkc188/go-cache/v3"
+	"github.com/mkc188/go-cache/v3/writebehind"
+)
+
+func TestQueue(t *testing.T) {
+	var (
+		store    = map[string]int{}
+		attempts int
+		failed   []writebehind.Mutation[string, int]
+		mu       sync.Mutex
+	)
+
+	q := writebehind.New(func(_ context.Context, batch []writebehind.Mutation[string, int]) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range batch {
+			switch m.Op {
+			case writebehind.Set:
+				store[m.Key] = m.Value
+			case writebehind.Delete:
+				delete(store, m.Key)
+			}
+		}
+		return nil
+	}, writebehind.Options[string, int]{
+		Interval:     time.Hour,
+		BatchSize:    2,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		OnFailure: func(batch []writebehind.Mutation[string, int], _ error) {
+			failed = append(failed, batch...)
+		},
+	})
+
+	c := writebehind.Wrap(cache.New[string, int](0, 10), q)
+	c.Set("a", 1)
+	c.Set("a", 2) // coalesced
+	c.Set("b", 3)
+	c.Invalidate("b")
+
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	if store["a"] != 2 || len(store) != 1 {
+		t.Fatalf("unexpected persisted state: %v", store)
+	}
+	mu.Unlock()
+
+	// Failed batches are retried then passed to OnFailure.
+	q = writebehind.New(func(_ context.Context, batch []writebehind.Mutation[string, int]) error {
+		attempts++
+		return errors.New("persist failed")
+	}, writebehind.Options[string, int]{
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		OnFailure: func(batch []writebehind.Mutation[string, int], _ error) {
+			failed = append(failed, batch...)
+		},
+	})
+	attempts = 0
+	q.Enqueue(writebehind.Mutation[string, int]{Key: "bad"})
+	if err := q.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush error")
+	}
+	q.Close(context.Background())
+	if attempts != 3 || len(failed) != 1 {
+		t.Fatalf("unexpected retries: attempts=%d failed=%v", attempts, failed)
+	}
+}